@@ -30,6 +30,9 @@ type API struct {
 	evaluationSvc domain.EvaluationService
 	conditionSvc  domain.ConditionService
 	ruleSvc       domain.RuleService
+	treatySvc     domain.TreatyService
+	calendarSvc   domain.CalendarService
+	pingSvc       domain.PingService
 }
 
 func NewAPI(logger *slog.Logger, conn *pgxpool.Pool, ch *amqp091.Channel) *API {
@@ -48,6 +51,10 @@ func NewAPI(logger *slog.Logger, conn *pgxpool.Pool, ch *amqp091.Channel) *API {
 		evaluationSvc: service.NewEvaluationService(logger, conn, ch),
 	}
 
+	api.treatySvc = service.NewTreatyService(logger, conn, api.evaluationSvc)
+	api.calendarSvc = service.NewCalendarService(logger, conn, api.presenceSvc, api.evaluationSvc)
+	api.pingSvc = service.NewPingService(logger, conn, ch, api.presenceSvc)
+
 	api.use(api.Logging, api.Cors)
 	api.registerRoutes()
 
@@ -70,6 +77,13 @@ func (a *API) registerRoutes() {
 
 	a.handle("GET /evaluate/{regionId}", a.EvaluateRegion, a.Auth)
 	a.handle("GET /evaluate", a.EvaluateRegions, a.Auth)
+	a.handle("POST /forecast/{regionId}", a.Forecast, a.Auth)
+	a.handle("POST /whatif", a.WhatIf, a.Auth)
+	a.handle("GET /timeline/{regionId}", a.Timeline, a.Auth)
+
+	a.handle("GET /treaty/{treatyId}", a.GetTreaty)
+	a.handle("GET /treaty", a.ListTreaties)
+	a.handle("GET /treaty/{treatyId}/evaluate", a.EvaluateTreaty, a.Auth)
 
 	a.handle("GET /condition/{conditionId}", a.GetCondition)
 	a.handle("GET /condition", a.ListConditions)
@@ -79,12 +93,21 @@ func (a *API) registerRoutes() {
 	a.handle("POST /device", a.CreateDevice, a.Auth)
 	a.handle("PATCH /device", a.UpdateDevice, a.Auth)
 	a.handle("DELETE /device/{deviceId}", a.DeleteDevice, a.Auth)
+	a.handle("POST /device/{deviceId}/ping", a.PingDevice, a.Auth)
 
 	a.handle("GET /presence/{regionId}/{date}", a.GetPresence, a.Auth)
 	a.handle("GET /presence", a.ListPresences, a.Auth)
 	a.handle("POST /presence", a.CreatePresence, a.Auth)
+	a.handle("POST /presence/import", a.ImportPresences, a.Auth)
+	a.handle("POST /presence/import/track", a.ImportTrack, a.Auth)
+	a.handle("POST /presence/import/calendar", a.ImportCalendar, a.Auth)
+	a.handle("POST /presence/confirm", a.ConfirmPresences, a.Auth)
 	a.handle("DELETE /presence", a.DeletePresence, a.Auth)
 
+	a.handle("POST /calendar/token", a.CreateCalendarToken, a.Auth)
+	// Calendar apps cannot send headers, so the feed is authenticated by the token in its URL
+	a.handle("GET /calendar/{feed}", a.GetCalendarFeed)
+
 	a.handle("GET /user", a.GetUser, a.Auth)
 	a.handle("POST /user", a.CreateUser)
 	a.handle("PATCH /user", a.UpdateUser, a.Auth)
@@ -19,6 +19,7 @@ var (
 	testRegionID    = domain.RegionID("JE")
 	testRuleID      = domain.Code("test_rule_id")
 	testConditionID = domain.Code("test_condition_id")
+	testTreatyID    = domain.Code("JE_GG")
 	testDate        = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
 )
 
@@ -31,6 +32,9 @@ type testAPIOptions struct {
 	conditionSvc  domain.ConditionService
 	regionSvc     domain.RegionService
 	ruleSvc       domain.RuleService
+	treatySvc     domain.TreatyService
+	calendarSvc   domain.CalendarService
+	pingSvc       domain.PingService
 }
 
 func newTestAPI(t *testing.T, opts testAPIOptions) *API {
@@ -48,6 +52,9 @@ func newTestAPI(t *testing.T, opts testAPIOptions) *API {
 		conditionSvc:  opts.conditionSvc,
 		ruleSvc:       opts.ruleSvc,
 		evaluationSvc: opts.evaluationSvc,
+		treatySvc:     opts.treatySvc,
+		calendarSvc:   opts.calendarSvc,
+		pingSvc:       opts.pingSvc,
 	}
 
 	a.registerRoutes()
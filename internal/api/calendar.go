@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/ical"
+)
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	// Path is the path of the feed, to subscribe to from a calendar app.
+	Path string `json:"path"`
+}
+
+// CreateCalendarToken issues a new calendar feed token, which revokes the URL of any earlier feed.
+func (a *API) CreateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	token, err := a.calendarSvc.CreateFeedToken(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "user not found")
+		default:
+			a.logger.Error("failed to create calendar token", "userId", userID, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to create calendar token")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, CalendarTokenResponse{
+		Token: token,
+		Path:  "/calendar/" + token + ".ics",
+	})
+}
+
+func (a *API) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(r.PathValue("feed"), ".ics")
+
+	feed, err := a.calendarSvc.Feed(r.Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "calendar not found")
+		default:
+			// The token is a secret, so it is never logged
+			a.logger.Error("failed to get calendar feed", "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to get calendar feed")
+		}
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, feed, time.Now()); err != nil {
+		a.logger.Error("failed to write calendar feed", "error", err)
+		RespondError(w, http.StatusInternalServerError, "failed to get calendar feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/test/mocks"
+)
+
+func TestCreateCalendarToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		authenticated       bool
+		mockCreateFeedToken func(ctx context.Context, userID int64) (string, error)
+		expectedCode        int
+		expectedPath        string
+	}{
+		{
+			name:          "created token",
+			authenticated: true,
+			mockCreateFeedToken: func(ctx context.Context, userID int64) (string, error) {
+				return "secret", nil
+			},
+			expectedCode: http.StatusCreated,
+			expectedPath: "/calendar/secret.ics",
+		},
+		{
+			name:         "missing user ID",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "user not found",
+			authenticated: true,
+			mockCreateFeedToken: func(ctx context.Context, userID int64) (string, error) {
+				return "", domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:          "service error",
+			authenticated: true,
+			mockCreateFeedToken: func(ctx context.Context, userID int64) (string, error) {
+				return "", errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				calendarSvc: &mocks.CalendarService{CreateFeedTokenFunc: tc.mockCreateFeedToken},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, "/calendar/token", "", tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+
+			if tc.expectedPath != "" {
+				var resp CalendarTokenResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.expectedPath, resp.Path)
+			}
+		})
+	}
+}
+
+func TestGetCalendarFeed(t *testing.T) {
+	t.Parallel()
+
+	feed := &domain.CalendarFeed{
+		Name: "Pumpkinlog presences",
+		Events: []*domain.CalendarFeedEvent{
+			{UID: "presence-JE-20250101@pumpkinlog", Summary: "In Jersey", RegionID: testRegionID, Start: testDate, End: testDate.Add(24 * time.Hour)},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		mockFeed     func(ctx context.Context, token string) (*domain.CalendarFeed, error)
+		expectedCode int
+	}{
+		{
+			name: "feed",
+			path: "/calendar/secret.ics",
+			mockFeed: func(ctx context.Context, token string) (*domain.CalendarFeed, error) {
+				if token != "secret" {
+					return nil, domain.ErrNotFound
+				}
+				return feed, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "unknown token",
+			path: "/calendar/guess.ics",
+			mockFeed: func(ctx context.Context, token string) (*domain.CalendarFeed, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "service error",
+			path: "/calendar/secret.ics",
+			mockFeed: func(ctx context.Context, token string) (*domain.CalendarFeed, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				calendarSvc: &mocks.CalendarService{FeedFunc: tc.mockFeed},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodGet, tc.path, "", false)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+
+			if tc.expectedCode == http.StatusOK {
+				require.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/calendar"))
+				require.Contains(t, rr.Body.String(), "SUMMARY:In Jersey\r\n")
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/pumpkinlog/backend/internal/domain"
 )
@@ -139,3 +140,64 @@ func (a *API) DeleteDevice(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// maxPingBatchSize caps the size of a batch of pings.
+const maxPingBatchSize = 1 << 20
+
+type DevicePingRequest struct {
+	At       time.Time        `json:"at"`
+	LatLng   *[2]float64      `json:"latLng"`
+	RegionID *domain.RegionID `json:"regionId"`
+}
+
+type PingDeviceRequest struct {
+	Pings []DevicePingRequest `json:"pings"`
+}
+
+// PingDevice records a batch of location check-ins from a device, each with either a position or
+// a region code. Pings are folded into presences asynchronously, so the batch is only accepted.
+func (a *API) PingDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	var err error
+	var deviceID int64
+	if deviceID, err = strconv.ParseInt(r.PathValue("deviceId"), 10, 64); err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid device ID")
+		return
+	}
+
+	var params PingDeviceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPingBatchSize)).Decode(&params); err != nil {
+		RespondError(w, http.StatusBadRequest, "malformed request body")
+		return
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	pings := make([]*domain.DevicePing, 0, len(params.Pings))
+	for _, p := range params.Pings {
+		pings = append(pings, &domain.DevicePing{
+			At:       p.At,
+			LatLng:   p.LatLng,
+			RegionID: p.RegionID,
+		})
+	}
+
+	receipt, err := a.pingSvc.Record(ctx, userID, deviceID, pings)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "device not found")
+		default:
+			a.logger.Error("failed to record pings", "userId", userID, "deviceId", deviceID, "pings", len(pings), "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to record pings")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusAccepted, receipt)
+}
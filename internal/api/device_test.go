@@ -332,3 +332,99 @@ func TestDeleteDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestPingDevice(t *testing.T) {
+	t.Parallel()
+
+	body := `{"pings": [{"at": "2025-06-01T09:00:00Z", "latLng": [49.21, -2.13]}, {"at": "2025-06-02T09:00:00Z", "regionId": "JE"}]}`
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		path          string
+		body          string
+		mockRecord    func(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error)
+		expectedCode  int
+	}{
+		{
+			name:          "accepted pings",
+			authenticated: true,
+			path:          "/device/1/ping",
+			body:          body,
+			mockRecord: func(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error) {
+				if deviceID != 1 || len(pings) != 2 || pings[0].LatLng == nil || pings[1].RegionID == nil || *pings[1].RegionID != testRegionID {
+					return nil, errors.New("unexpected pings")
+				}
+				return &domain.PingReceipt{Received: 2, Stored: 2}, nil
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "missing user ID",
+			path:         "/device/1/ping",
+			body:         body,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "invalid device ID",
+			authenticated: true,
+			path:          "/device/phone/ping",
+			body:          body,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "malformed body",
+			authenticated: true,
+			path:          "/device/1/ping",
+			body:          `{"pings": [`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid ping",
+			authenticated: true,
+			path:          "/device/1/ping",
+			body:          body,
+			mockRecord: func(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error) {
+				return nil, domain.ValidationError("ping time is required")
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "device not found",
+			authenticated: true,
+			path:          "/device/1/ping",
+			body:          body,
+			mockRecord: func(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:          "service error",
+			authenticated: true,
+			path:          "/device/1/ping",
+			body:          body,
+			mockRecord: func(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				pingSvc: &mocks.PingService{RecordFunc: tc.mockRecord},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, tc.path, tc.body, tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pumpkinlog/backend/internal/domain"
@@ -23,8 +25,29 @@ func (a *API) EvaluateRegion(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var explain bool
+	if explainStr := r.URL.Query().Get("explain"); explainStr != "" {
+		explain, err = strconv.ParseBool(explainStr)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid explain value")
+			return
+		}
+	}
+
+	// Unconfirmed days are counted unless excluded, as they are in cached evaluations
+	var excludeUnconfirmed bool
+	if v := r.URL.Query().Get("excludeUnconfirmed"); v != "" {
+		excludeUnconfirmed, err = strconv.ParseBool(v)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid excludeUnconfirmed value")
+			return
+		}
+	}
+
 	opts := &domain.EvaluateOpts{
-		PointInTime: pit,
+		PointInTime:        pit,
+		Explain:            explain,
+		ExcludeUnconfirmed: excludeUnconfirmed,
 	}
 
 	evaluation, err := a.evaluationSvc.EvaluateRegion(ctx, userID, regionID, opts)
@@ -43,14 +66,14 @@ func (a *API) EvaluateRegion(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) EvaluateRegions(w http.ResponseWriter, r *http.Request) {
-	/*ctx := r.Context()
+	ctx := r.Context()
 	userID := UserID(ctx)
 
 	evaluations, err := a.evaluationSvc.EvaluateRegions(ctx, userID)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
-			RespondJSON(w, http.StatusNotFound, "region not found")
+			RespondError(w, http.StatusNotFound, "user not found")
 		default:
 			a.logger.Error("failed to evaluate regions", "userId", userID, "error", err)
 			RespondError(w, http.StatusInternalServerError, "failed to evaluate regions")
@@ -58,5 +81,190 @@ func (a *API) EvaluateRegions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	RespondJSON(w, http.StatusOK, evaluations)*/
+	RespondJSON(w, http.StatusOK, evaluations)
+}
+
+func (a *API) Timeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+	regionID := domain.RegionID(r.PathValue("regionId"))
+
+	from, err := time.Parse(time.DateOnly, r.URL.Query().Get("from"))
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid from date")
+		return
+	}
+
+	until, err := time.Parse(time.DateOnly, r.URL.Query().Get("until"))
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid until date")
+		return
+	}
+
+	opts := &domain.TimelineOpts{
+		From:  from,
+		Until: until,
+		Step:  domain.TimelineStep(r.URL.Query().Get("step")),
+	}
+
+	timeline, err := a.evaluationSvc.Timeline(ctx, userID, regionID, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "region not found")
+		default:
+			a.logger.Error("failed to build region timeline", "userId", userID, "regionId", regionID, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to build region timeline")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, timeline)
+}
+
+type PlannedPresenceRequest struct {
+	RegionID domain.RegionID `json:"regionId"`
+	Start    string          `json:"start"`
+	End      string          `json:"end"`
+}
+
+type ForecastRequest struct {
+	From    string                   `json:"from"`
+	Until   string                   `json:"until"`
+	Stay    bool                     `json:"stay"`
+	Planned []PlannedPresenceRequest `json:"planned"`
+}
+
+func (a *API) Forecast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+	regionID := domain.RegionID(r.PathValue("regionId"))
+
+	var params ForecastRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, http.StatusBadRequest, "malformed request body")
+		return
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	opts := &domain.ForecastOpts{
+		Stay: params.Stay,
+	}
+
+	var err error
+	if params.From != "" {
+		opts.From, err = time.Parse(time.DateOnly, params.From)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid from date")
+			return
+		}
+	}
+
+	opts.Until, err = time.Parse(time.DateOnly, params.Until)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid until date")
+		return
+	}
+
+	opts.Planned, err = parsePlannedPresences(params.Planned)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid planned date")
+		return
+	}
+
+	forecast, err := a.evaluationSvc.Forecast(ctx, userID, regionID, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "region not found")
+		default:
+			a.logger.Error("failed to forecast region", "userId", userID, "regionId", regionID, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to forecast region")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, forecast)
+}
+
+type WhatIfRequest struct {
+	PointInTime string                   `json:"pointInTime"`
+	Trips       []PlannedPresenceRequest `json:"trips"`
+}
+
+func (a *API) WhatIf(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	var params WhatIfRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, http.StatusBadRequest, "malformed request body")
+		return
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	opts := &domain.WhatIfOpts{}
+
+	var err error
+	if params.PointInTime != "" {
+		opts.PointInTime, err = time.Parse(time.DateOnly, params.PointInTime)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid point in time format")
+			return
+		}
+	}
+
+	opts.Trips, err = parsePlannedPresences(params.Trips)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid trip date")
+		return
+	}
+
+	whatIf, err := a.evaluationSvc.WhatIf(ctx, userID, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "region not found")
+		default:
+			a.logger.Error("failed to evaluate trips", "userId", userID, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to evaluate trips")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, whatIf)
+}
+
+func parsePlannedPresences(params []PlannedPresenceRequest) ([]domain.PlannedPresence, error) {
+	planned := make([]domain.PlannedPresence, 0, len(params))
+
+	for _, p := range params {
+		start, err := time.Parse(time.DateOnly, p.Start)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := time.Parse(time.DateOnly, p.End)
+		if err != nil {
+			return nil, err
+		}
+
+		planned = append(planned, domain.PlannedPresence{
+			RegionID: p.RegionID,
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	return planned, nil
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -22,6 +23,7 @@ func TestEvaluateRegion(t *testing.T) {
 		name               string
 		authenticated      bool
 		regionID           domain.RegionID
+		query              string
 		mockEvaluate       func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error)
 		expectedCode       int
 		expectedEvaluation domain.RegionEvaluation
@@ -35,6 +37,42 @@ func TestEvaluateRegion(t *testing.T) {
 			},
 			expectedCode: http.StatusOK,
 		},
+		{
+			name:          "evaluation explained",
+			authenticated: true,
+			regionID:      testRegionID,
+			query:         "?explain=true",
+			mockEvaluate: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error) {
+				require.True(t, opts.Explain)
+				return &domain.RegionEvaluation{}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "invalid explain value",
+			authenticated: true,
+			regionID:      testRegionID,
+			query:         "?explain=maybe",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "unconfirmed days excluded",
+			authenticated: true,
+			regionID:      testRegionID,
+			query:         "?excludeUnconfirmed=true",
+			mockEvaluate: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error) {
+				require.True(t, opts.ExcludeUnconfirmed)
+				return &domain.RegionEvaluation{}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "invalid excludeUnconfirmed value",
+			authenticated: true,
+			regionID:      testRegionID,
+			query:         "?excludeUnconfirmed=maybe",
+			expectedCode:  http.StatusBadRequest,
+		},
 		{
 			name:          "evaluation not found",
 			authenticated: true,
@@ -73,7 +111,7 @@ func TestEvaluateRegion(t *testing.T) {
 			}
 
 			api := newTestAPI(t, opts)
-			uri := fmt.Sprintf("/evaluate/%s", tc.regionID)
+			uri := fmt.Sprintf("/evaluate/%s%s", tc.regionID, tc.query)
 			req := newTestRequest(t, http.MethodGet, uri, "", tc.authenticated)
 			rr := httptest.NewRecorder()
 			api.Handler().ServeHTTP(rr, req)
@@ -92,7 +130,6 @@ func TestEvaluateRegion(t *testing.T) {
 
 func TestEvaluateRegions(t *testing.T) {
 	t.Parallel()
-	t.Skip("handler not fully implemented yet")
 
 	tests := []struct {
 		name                string
@@ -110,6 +147,19 @@ func TestEvaluateRegions(t *testing.T) {
 			expectedCode:        http.StatusOK,
 			expectedEvaluations: make([]domain.RegionEvaluation, 0),
 		},
+		{
+			name:          "region failed to evaluate",
+			authenticated: true,
+			mockEvaluate: func(ctx context.Context, userID int64) ([]*domain.RegionEvaluation, error) {
+				return []*domain.RegionEvaluation{
+					{RegionID: "JE", Status: domain.EvaluationStatusError, Reason: "failed to evaluate region"},
+				}, nil
+			},
+			expectedCode: http.StatusOK,
+			expectedEvaluations: []domain.RegionEvaluation{
+				{RegionID: "JE", Status: domain.EvaluationStatusError, Reason: "failed to evaluate region"},
+			},
+		},
 		{
 			name:          "evaluation not found",
 			authenticated: true,
@@ -138,7 +188,7 @@ func TestEvaluateRegions(t *testing.T) {
 			t.Parallel()
 
 			opts := testAPIOptions{
-				evaluationSvc: &mocks.EvaluationService{},
+				evaluationSvc: &mocks.EvaluationService{EvaluateRegionsFunc: tc.mockEvaluate},
 			}
 
 			api := newTestAPI(t, opts)
@@ -157,3 +207,315 @@ func TestEvaluateRegions(t *testing.T) {
 		})
 	}
 }
+
+func TestForecast(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		body          string
+		mockForecast  func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error)
+		expectedCode  int
+	}{
+		{
+			name:          "forecast with planned presences",
+			authenticated: true,
+			body:          `{"from":"2025-06-01","until":"2025-12-31","planned":[{"regionId":"JE","start":"2025-07-01","end":"2025-07-31"}]}`,
+			mockForecast: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+				require.Equal(t, time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), opts.From)
+				require.Len(t, opts.Planned, 1)
+				return &domain.Forecast{RegionID: regionID}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "forecast staying continuously",
+			authenticated: true,
+			body:          `{"until":"2025-12-31","stay":true}`,
+			mockForecast: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+				require.True(t, opts.Stay)
+				require.True(t, opts.From.IsZero())
+				return &domain.Forecast{RegionID: regionID}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "malformed body",
+			authenticated: true,
+			body:          `{`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid until date",
+			authenticated: true,
+			body:          `{"until":"31-12-2025"}`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid planned date",
+			authenticated: true,
+			body:          `{"until":"2025-12-31","planned":[{"regionId":"JE","start":"July","end":"2025-07-31"}]}`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "validation error",
+			authenticated: true,
+			body:          `{"until":"2030-12-31"}`,
+			mockForecast: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+				return nil, domain.ValidationError("forecast cannot cover more than 730 days")
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "region not found",
+			authenticated: true,
+			body:          `{"until":"2025-12-31"}`,
+			mockForecast: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing userID",
+			body:         `{"until":"2025-12-31"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "service returns error",
+			authenticated: true,
+			body:          `{"until":"2025-12-31"}`,
+			mockForecast: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				evaluationSvc: &mocks.EvaluationService{ForecastFunc: tc.mockForecast},
+			}
+
+			api := newTestAPI(t, opts)
+			uri := fmt.Sprintf("/forecast/%s", testRegionID)
+			req := newTestRequest(t, http.MethodPost, uri, tc.body, tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code")
+
+			if rr.Code == http.StatusOK {
+				var got domain.Forecast
+				err := json.NewDecoder(rr.Body).Decode(&got)
+				require.NoError(t, err, "cannot decode json response")
+				require.Equal(t, testRegionID, got.RegionID)
+			}
+		})
+	}
+}
+
+func TestWhatIf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		body          string
+		mockWhatIf    func(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error)
+		expectedCode  int
+	}{
+		{
+			name:          "trips evaluated",
+			authenticated: true,
+			body:          `{"trips":[{"regionId":"JE","start":"2025-07-01","end":"2025-07-31"},{"regionId":"GB","start":"2025-08-01","end":"2025-08-10"}]}`,
+			mockWhatIf: func(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error) {
+				require.Len(t, opts.Trips, 2)
+				require.True(t, opts.PointInTime.IsZero())
+				return &domain.WhatIf{Regions: []*domain.RegionDiff{{RegionID: "JE", Changed: true}}}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "malformed body",
+			authenticated: true,
+			body:          `[`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid point in time",
+			authenticated: true,
+			body:          `{"pointInTime":"tomorrow","trips":[]}`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid trip date",
+			authenticated: true,
+			body:          `{"trips":[{"regionId":"JE","start":"2025-07-01","end":"soon"}]}`,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "validation error",
+			authenticated: true,
+			body:          `{"trips":[]}`,
+			mockWhatIf: func(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error) {
+				return nil, domain.ValidationError("at least one trip is required")
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "region not found",
+			authenticated: true,
+			body:          `{"trips":[{"regionId":"XX","start":"2025-07-01","end":"2025-07-31"}]}`,
+			mockWhatIf: func(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing userID",
+			body:         `{"trips":[]}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "service returns error",
+			authenticated: true,
+			body:          `{"trips":[{"regionId":"JE","start":"2025-07-01","end":"2025-07-31"}]}`,
+			mockWhatIf: func(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				evaluationSvc: &mocks.EvaluationService{WhatIfFunc: tc.mockWhatIf},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, "/whatif", tc.body, tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code")
+
+			if rr.Code == http.StatusOK {
+				var got domain.WhatIf
+				err := json.NewDecoder(rr.Body).Decode(&got)
+				require.NoError(t, err, "cannot decode json response")
+				require.Len(t, got.Regions, 1)
+			}
+		})
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		query         string
+		mockTimeline  func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error)
+		expectedCode  int
+	}{
+		{
+			name:          "timeline by day",
+			authenticated: true,
+			query:         "?from=2025-01-01&until=2025-12-31",
+			mockTimeline: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+				require.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), opts.From)
+				require.Equal(t, time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC), opts.Until)
+				require.Empty(t, opts.Step)
+				return &domain.Timeline{RegionID: regionID}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "timeline by period",
+			authenticated: true,
+			query:         "?from=2020-01-01&until=2025-12-31&step=period",
+			mockTimeline: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+				require.Equal(t, domain.TimelineStepPeriod, opts.Step)
+				return &domain.Timeline{RegionID: regionID}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "missing from date",
+			authenticated: true,
+			query:         "?until=2025-12-31",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid until date",
+			authenticated: true,
+			query:         "?from=2025-01-01&until=31-12-2025",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "validation error",
+			authenticated: true,
+			query:         "?from=2025-01-01&until=2025-12-31&step=week",
+			mockTimeline: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+				return nil, domain.ValidationError("unknown timeline step: week")
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "region not found",
+			authenticated: true,
+			query:         "?from=2025-01-01&until=2025-12-31",
+			mockTimeline: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing userID",
+			query:        "?from=2025-01-01&until=2025-12-31",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "service returns error",
+			authenticated: true,
+			query:         "?from=2025-01-01&until=2025-12-31",
+			mockTimeline: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				evaluationSvc: &mocks.EvaluationService{TimelineFunc: tc.mockTimeline},
+			}
+
+			api := newTestAPI(t, opts)
+			uri := fmt.Sprintf("/timeline/%s%s", testRegionID, tc.query)
+			req := newTestRequest(t, http.MethodGet, uri, "", tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code")
+
+			if rr.Code == http.StatusOK {
+				var got domain.Timeline
+				err := json.NewDecoder(rr.Body).Decode(&got)
+				require.NoError(t, err, "cannot decode json response")
+				require.Equal(t, testRegionID, got.RegionID)
+			}
+		})
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/ical"
+	"github.com/pumpkinlog/backend/internal/importer"
 )
 
 func (a *API) GetPresence(w http.ResponseWriter, r *http.Request) {
@@ -65,10 +68,22 @@ func (a *API) ListPresences(w http.ResponseWriter, r *http.Request) {
 		end = &t
 	}
 
+	var confirmed *bool
+
+	if v := r.URL.Query().Get("confirmed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid confirmed value")
+			return
+		}
+		confirmed = &b
+	}
+
 	filter := &domain.PresenceFilter{
 		RegionIDs: regionIDs,
 		Start:     start,
 		End:       end,
+		Confirmed: confirmed,
 	}
 
 	precences, err := a.presenceSvc.List(ctx, userID, filter)
@@ -82,17 +97,257 @@ func (a *API) ListPresences(w http.ResponseWriter, r *http.Request) {
 }
 
 type CreatePresencesRequest struct {
+	RegionID  domain.RegionID `json:"regionId"`
+	Start     string          `json:"start"`
+	End       string          `json:"end"`
+	DeviceID  *int64          `json:"deviceId"`
+	Arrival   bool            `json:"arrival"`
+	Departure bool            `json:"departure"`
+	Transit   bool            `json:"transit"`
+	// Provenance records how the days were recorded, defaulting to manual. Days of any other
+	// provenance are unconfirmed until the user confirms them.
+	Provenance domain.PresenceProvenance `json:"provenance"`
+	// Evidence references what the days were derived from.
+	Evidence string `json:"evidence"`
+	// TimeZone is the IANA time zone timestamps are converted to calendar days in, defaulting to
+	// the region's time zone. Dates are already calendar days and take no time zone.
+	TimeZone string `json:"timeZone"`
+}
+
+func (a *API) CreatePresence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	var params CreatePresencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, http.StatusBadRequest, "malformed request body")
+		return
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	// Start and end are either both dates or both timestamps
+	timestamps := false
+
+	start, err := time.Parse(time.DateOnly, params.Start)
+	if err != nil {
+		start, err = time.Parse(time.RFC3339, params.Start)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid start time")
+			return
+		}
+		timestamps = true
+	}
+
+	layout := time.DateOnly
+	if timestamps {
+		layout = time.RFC3339
+	}
+
+	end, err := time.Parse(layout, params.End)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid end time")
+		return
+	}
+
+	if !timestamps && params.TimeZone != "" {
+		RespondError(w, http.StatusBadRequest, "time zone requires start and end timestamps")
+		return
+	}
+
+	opts := &domain.PresenceOpts{
+		Arrival:    params.Arrival,
+		Departure:  params.Departure,
+		Transit:    params.Transit,
+		Provenance: params.Provenance,
+		Evidence:   params.Evidence,
+	}
+
+	if timestamps {
+		err = a.presenceSvc.CreateFromTimestamps(ctx, userID, params.RegionID, params.DeviceID, start, end, params.TimeZone, opts)
+	} else {
+		err = a.presenceSvc.Create(ctx, userID, params.RegionID, params.DeviceID, start, end, opts)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			a.logger.Error("failed to create presence", "userId", userID, "regionId", params.RegionID, "deviceId", params.DeviceID, "start", start, "end", end, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to create presence")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// maxImportSize caps the size of an imported travel history.
+const maxImportSize = 4 << 20
+
+// maxTrackImportSize caps the size of an imported GPS track, which has a line for every point.
+const maxTrackImportSize = 64 << 20
+
+// ImportPresences imports a CSV travel history. A dry run only returns the diff against the user's
+// presences, and an import with invalid rows is not committed.
+func (a *API) ImportPresences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid dry run")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	rows, err := importer.ParseCSV(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := a.presenceSvc.Import(ctx, userID, rows, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			a.logger.Error("failed to import presences", "userId", userID, "rows", len(rows), "dryRun", dryRun, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to import presences")
+		}
+		return
+	}
+
+	respondImport(w, result)
+}
+
+// ImportTrack imports a GPX, KML or GeoJSON track, given by the format parameter or the content
+// type, as the days the user was located in each region.
+func (a *API) ImportTrack(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid dry run")
+		return
+	}
+
+	format := importer.TrackFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		var ok bool
+		if format, ok = importer.TrackFormatFromContentType(r.Header.Get("Content-Type")); !ok {
+			RespondError(w, http.StatusBadRequest, "track format is required")
+			return
+		}
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	points, err := importer.ParseTrack(format, http.MaxBytesReader(w, r.Body, maxTrackImportSize))
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := a.presenceSvc.ImportTrack(ctx, userID, points, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			a.logger.Error("failed to import track", "userId", userID, "format", format, "points", len(points), "dryRun", dryRun, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to import track")
+		}
+		return
+	}
+
+	respondImport(w, result)
+}
+
+// maxCalendarImportSize caps the size of an imported calendar.
+const maxCalendarImportSize = 16 << 20
+
+// ImportCalendar imports the events of an iCalendar file, resolving the region of each from its
+// region code, location or position.
+func (a *API) ImportCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+
+	dryRun, err := parseDryRun(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid dry run")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	events, err := ical.Parse(http.MaxBytesReader(w, r.Body, maxCalendarImportSize))
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := a.presenceSvc.ImportCalendar(ctx, userID, events, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			a.logger.Error("failed to import calendar", "userId", userID, "events", len(events), "dryRun", dryRun, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to import calendar")
+		}
+		return
+	}
+
+	respondImport(w, result)
+}
+
+func parseDryRun(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("dryRun")
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+// respondImport responds with the import diff, which is unprocessable when invalid rows stopped
+// the import from being committed.
+func respondImport(w http.ResponseWriter, result *domain.PresenceImport) {
+	switch {
+	case result.Committed:
+		RespondJSON(w, http.StatusCreated, result)
+	case result.DryRun:
+		RespondJSON(w, http.StatusOK, result)
+	default:
+		RespondJSON(w, http.StatusUnprocessableEntity, result)
+	}
+}
+
+type ConfirmPresencesRequest struct {
 	RegionID domain.RegionID `json:"regionId"`
 	Start    string          `json:"start"`
 	End      string          `json:"end"`
-	DeviceID *int64          `json:"deviceId"`
+	// Confirmed is false to return confirmed days to review.
+	Confirmed bool `json:"confirmed"`
 }
 
-func (a *API) CreatePresence(w http.ResponseWriter, r *http.Request) {
+// ConfirmPresences marks the user's days in a region as reviewed, or returns them to review.
+func (a *API) ConfirmPresences(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := UserID(ctx)
 
-	var params CreatePresencesRequest
+	var params ConfirmPresencesRequest
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 		RespondError(w, http.StatusBadRequest, "malformed request body")
 		return
@@ -113,17 +368,18 @@ func (a *API) CreatePresence(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.presenceSvc.Create(ctx, userID, params.RegionID, params.DeviceID, start, end); err != nil {
+	if err := a.presenceSvc.Confirm(ctx, userID, params.RegionID, start, end, params.Confirmed); err != nil {
 		switch {
 		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
 		default:
-			a.logger.Error("failed to create presence", "userId", userID, "regionId", params.RegionID, "deviceId", params.DeviceID, "start", start, "end", end, "error", err)
-			RespondError(w, http.StatusInternalServerError, "failed to create presence")
+			a.logger.Error("failed to confirm presences", "userId", userID, "regionId", params.RegionID, "start", start, "end", end, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to confirm presences")
 		}
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusOK)
 }
 
 func (a *API) DeletePresence(w http.ResponseWriter, r *http.Request) {
@@ -155,6 +155,28 @@ func TestListPresence(t *testing.T) {
 			},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name:          "listed unconfirmed presences",
+			authenticated: true,
+			query: url.Values{
+				"confirmed": []string{"false"},
+			},
+			mockList: func(ctx context.Context, userID int64, filter *domain.PresenceFilter) ([]*domain.Presence, error) {
+				require.NotNil(t, filter.Confirmed)
+				require.False(t, *filter.Confirmed)
+				return make([]*domain.Presence, 0), nil
+			},
+			expectedCode:      http.StatusOK,
+			expectedPresences: make([]domain.Presence, 0),
+		},
+		{
+			name:          "invalid confirmed param",
+			authenticated: true,
+			query: url.Values{
+				"confirmed": []string{"maybe"},
+			},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:         "missing userID",
 			expectedCode: http.StatusUnauthorized,
@@ -202,18 +224,58 @@ func TestCreatePresence(t *testing.T) {
 		name          string
 		authenticated bool
 		request       string
-		mockCreate    func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error
+		mockCreate    func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error
+		mockCreateTS  func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, timeZone string, opts *domain.PresenceOpts) error
 		expectedCode  int
 	}{
 		{
 			name:          "created presence",
 			authenticated: true,
 			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s"}`, testRegionID, testDate.Format(time.DateOnly), testDate.Format(time.DateOnly)),
-			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
+			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
+				return nil
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "created presence from timestamps",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"2025-03-29T23:30:00Z","end":"2025-03-30T23:30:00Z","timeZone":"Europe/London"}`, testRegionID),
+			mockCreateTS: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, timeZone string, opts *domain.PresenceOpts) error {
+				if !start.Equal(time.Date(2025, time.March, 29, 23, 30, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, time.March, 30, 23, 30, 0, 0, time.UTC)) {
+					return fmt.Errorf("unexpected range %s to %s", start, end)
+				}
+				if timeZone != "Europe/London" {
+					return fmt.Errorf("unexpected time zone %s", timeZone)
+				}
+				return nil
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "created inferred presence",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s","provenance":"inference","evidence":"trip:42"}`, testRegionID, testDate.Format(time.DateOnly), testDate.Format(time.DateOnly)),
+			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
+				if opts.Provenance != domain.PresenceProvenanceInference || opts.Evidence != "trip:42" {
+					return fmt.Errorf("unexpected provenance %s with evidence %s", opts.Provenance, opts.Evidence)
+				}
 				return nil
 			},
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name:          "mixed date and timestamp",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"2025-03-29T23:30:00Z","end":"%s"}`, testRegionID, testDate.Format(time.DateOnly)),
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "time zone with dates",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s","timeZone":"Europe/London"}`, testRegionID, testDate.Format(time.DateOnly), testDate.Format(time.DateOnly)),
+			expectedCode:  http.StatusBadRequest,
+		},
 		{
 			name:         "missing user ID",
 			expectedCode: http.StatusUnauthorized,
@@ -233,7 +295,7 @@ func TestCreatePresence(t *testing.T) {
 		{
 			name:          "validation error",
 			authenticated: true,
-			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
+			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
 				return domain.ErrValidation
 			},
 			expectedCode: http.StatusBadRequest,
@@ -242,7 +304,7 @@ func TestCreatePresence(t *testing.T) {
 			name:          "service error",
 			authenticated: true,
 			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s"}`, testRegionID, testDate.Format(time.DateOnly), testDate.Format(time.DateOnly)),
-			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
+			mockCreate: func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
 				return errors.New("database error")
 			},
 			expectedCode: http.StatusInternalServerError,
@@ -254,7 +316,10 @@ func TestCreatePresence(t *testing.T) {
 			t.Parallel()
 
 			opts := testAPIOptions{
-				presenceSvc: &mocks.PresenceService{CreateFunc: tc.mockCreate},
+				presenceSvc: &mocks.PresenceService{
+					CreateFunc:               tc.mockCreate,
+					CreateFromTimestampsFunc: tc.mockCreateTS,
+				},
 			}
 
 			api := newTestAPI(t, opts)
@@ -267,6 +332,396 @@ func TestCreatePresence(t *testing.T) {
 	}
 }
 
+func TestImportPresences(t *testing.T) {
+	t.Parallel()
+
+	csv := "region,start,end\nJE,2025-01-01,2025-01-10\n"
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		query         string
+		body          string
+		mockImport    func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error)
+		expectedCode  int
+	}{
+		{
+			name:          "committed import",
+			authenticated: true,
+			body:          csv,
+			mockImport: func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+				if len(rows) != 1 || rows[0].RegionID != "JE" || dryRun {
+					return nil, fmt.Errorf("unexpected import of %d rows, dry run %t", len(rows), dryRun)
+				}
+				return &domain.PresenceImport{Committed: true, NewDays: 10}, nil
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "dry run",
+			authenticated: true,
+			query:         "?dryRun=true",
+			body:          csv,
+			mockImport: func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+				if !dryRun {
+					return nil, errors.New("expected a dry run")
+				}
+				return &domain.PresenceImport{DryRun: true, NewDays: 10}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "invalid rows",
+			authenticated: true,
+			body:          csv,
+			mockImport: func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+				return &domain.PresenceImport{Invalid: 1}, nil
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "missing user ID",
+			body:         csv,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "invalid dry run",
+			authenticated: true,
+			query:         "?dryRun=maybe",
+			body:          csv,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "missing column",
+			authenticated: true,
+			body:          "region,start\nJE,2025-01-01\n",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "validation error",
+			authenticated: true,
+			body:          "region,start,end\n",
+			mockImport: func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+				return nil, domain.ValidationError("import has no rows")
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "service error",
+			authenticated: true,
+			body:          csv,
+			mockImport: func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				presenceSvc: &mocks.PresenceService{ImportFunc: tc.mockImport},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, "/presence/import"+tc.query, tc.body, tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+		})
+	}
+}
+
+func TestImportTrack(t *testing.T) {
+	t.Parallel()
+
+	gpx := `<gpx><wpt lat="49.21" lon="-2.13"><time>2025-06-01T09:00:00Z</time></wpt></gpx>`
+
+	tests := []struct {
+		name            string
+		authenticated   bool
+		query           string
+		contentType     string
+		body            string
+		mockImportTrack func(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error)
+		expectedCode    int
+	}{
+		{
+			name:          "committed import",
+			authenticated: true,
+			query:         "?format=gpx",
+			body:          gpx,
+			mockImportTrack: func(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error) {
+				if len(points) != 1 || points[0].LatLng != [2]float64{49.21, -2.13} || dryRun {
+					return nil, fmt.Errorf("unexpected import of %d points, dry run %t", len(points), dryRun)
+				}
+				return &domain.PresenceImport{Committed: true, NewDays: 1}, nil
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "format from content type",
+			authenticated: true,
+			query:         "?dryRun=true",
+			contentType:   "application/gpx+xml",
+			body:          gpx,
+			mockImportTrack: func(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error) {
+				return &domain.PresenceImport{DryRun: dryRun}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing user ID",
+			query:        "?format=gpx",
+			body:         gpx,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "missing format",
+			authenticated: true,
+			body:          gpx,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "unknown format",
+			authenticated: true,
+			query:         "?format=fit",
+			body:          gpx,
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "malformed track",
+			authenticated: true,
+			query:         "?format=geojson",
+			body:          "{",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "service error",
+			authenticated: true,
+			query:         "?format=gpx",
+			body:          gpx,
+			mockImportTrack: func(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				presenceSvc: &mocks.PresenceService{ImportTrackFunc: tc.mockImportTrack},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, "/presence/import/track"+tc.query, tc.body, tc.authenticated)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+		})
+	}
+}
+
+func TestImportCalendar(t *testing.T) {
+	t.Parallel()
+
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250601\r\nLOCATION:Jersey\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	tests := []struct {
+		name               string
+		authenticated      bool
+		query              string
+		body               string
+		mockImportCalendar func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error)
+		expectedCode       int
+	}{
+		{
+			name:          "committed import",
+			authenticated: true,
+			body:          ics,
+			mockImportCalendar: func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+				if len(events) != 1 || events[0].Location != "Jersey" || dryRun {
+					return nil, fmt.Errorf("unexpected import of %d events, dry run %t", len(events), dryRun)
+				}
+				return &domain.PresenceImport{Committed: true, NewDays: 1}, nil
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "dry run",
+			authenticated: true,
+			query:         "?dryRun=true",
+			body:          ics,
+			mockImportCalendar: func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+				return &domain.PresenceImport{DryRun: dryRun}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "unresolved events",
+			authenticated: true,
+			body:          ics,
+			mockImportCalendar: func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+				return &domain.PresenceImport{Invalid: 1}, nil
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "missing user ID",
+			body:         ics,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "not a calendar",
+			authenticated: true,
+			body:          "region,start,end\n",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "validation error",
+			authenticated: true,
+			body:          ics,
+			mockImportCalendar: func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+				return nil, fmt.Errorf("%w: calendar has no events", domain.ErrValidation)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "service error",
+			authenticated: true,
+			body:          ics,
+			mockImportCalendar: func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				presenceSvc: &mocks.PresenceService{ImportCalendarFunc: tc.mockImportCalendar},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, "/presence/import/calendar"+tc.query, tc.body, tc.authenticated)
+			req.Header.Set("Content-Type", "text/calendar")
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+		})
+	}
+}
+
+func TestConfirmPresences(t *testing.T) {
+	t.Parallel()
+
+	dateStr := testDate.Format(time.DateOnly)
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		request       string
+		mockConfirm   func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error
+		expectedCode  int
+	}{
+		{
+			name:          "confirmed presences",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s","confirmed":true}`, testRegionID, dateStr, dateStr),
+			mockConfirm: func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+				if !confirmed {
+					return errors.New("presences not confirmed")
+				}
+				return nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "unconfirmed presences",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s"}`, testRegionID, dateStr, dateStr),
+			mockConfirm: func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+				if confirmed {
+					return errors.New("presences confirmed")
+				}
+				return nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing userID",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "malformed body",
+			authenticated: true,
+			request:       "{",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid start",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"invalid time","end":"%s"}`, testRegionID, dateStr),
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "invalid end",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"invalid time"}`, testRegionID, dateStr),
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "validation error",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"start":"%s","end":"%s"}`, dateStr, dateStr),
+			mockConfirm: func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+				return domain.ErrValidation
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "service error",
+			authenticated: true,
+			request:       fmt.Sprintf(`{"regionId":"%s","start":"%s","end":"%s"}`, testRegionID, dateStr, dateStr),
+			mockConfirm: func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+				return errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				presenceSvc: &mocks.PresenceService{ConfirmFunc: tc.mockConfirm},
+			}
+
+			api := newTestAPI(t, opts)
+			req := newTestRequest(t, http.MethodPost, "/presence/confirm", tc.request, tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code", rr.Body.String())
+		})
+	}
+}
+
 func TestDeletePresence(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func (a *API) GetTreaty(w http.ResponseWriter, r *http.Request) {
+	treatyID := domain.Code(r.PathValue("treatyId"))
+
+	treaty, err := a.treatySvc.GetByID(r.Context(), treatyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "treaty not found")
+		default:
+			a.logger.Error("failed to get treaty", "treatyId", treatyID, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to get treaty")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, treaty)
+}
+
+func (a *API) ListTreaties(w http.ResponseWriter, r *http.Request) {
+	regionIDs := make([]domain.RegionID, 0)
+	for _, rid := range r.URL.Query()["regionId"] {
+		regionIDs = append(regionIDs, domain.RegionID(rid))
+	}
+
+	filter := &domain.TreatyFilter{
+		RegionIDs: regionIDs,
+	}
+
+	treaties, err := a.treatySvc.List(r.Context(), filter)
+	if err != nil {
+		a.logger.Error("failed to list treaties", "regionIds", regionIDs, "error", err)
+		RespondError(w, http.StatusInternalServerError, "failed to list treaties")
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, treaties)
+}
+
+func (a *API) EvaluateTreaty(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := UserID(ctx)
+	treatyID := domain.Code(r.PathValue("treatyId"))
+
+	var err error
+	var pit time.Time
+	if pitStr := r.URL.Query().Get("pointInTime"); pitStr != "" {
+		pit, err = time.Parse(time.DateOnly, pitStr)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid point in time format")
+			return
+		}
+	}
+
+	opts := &domain.EvaluateOpts{
+		PointInTime: pit,
+	}
+
+	evaluation, err := a.treatySvc.Evaluate(ctx, userID, treatyID, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			RespondError(w, http.StatusNotFound, "treaty not found")
+		default:
+			a.logger.Error("failed to evaluate treaty", "userId", userID, "treatyId", treatyID, "error", err)
+			RespondError(w, http.StatusInternalServerError, "failed to evaluate treaty")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, evaluation)
+}
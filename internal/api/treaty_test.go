@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/test/mocks"
+)
+
+func TestGetTreaty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		mockGetByID  func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error)
+		expectedCode int
+	}{
+		{
+			name: "treaty found",
+			mockGetByID: func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+				return &domain.Treaty{ID: treatyID}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "treaty not found",
+			mockGetByID: func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "repo returns error",
+			mockGetByID: func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				treatySvc: &mocks.TreatyService{GetByIDFunc: tc.mockGetByID},
+			}
+
+			api := newTestAPI(t, opts)
+			uri := fmt.Sprintf("/treaty/%s", testTreatyID)
+			req := newTestRequest(t, http.MethodGet, uri, "", false)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code")
+
+			if rr.Code == http.StatusOK {
+				var got domain.Treaty
+				err := json.NewDecoder(rr.Body).Decode(&got)
+				require.NoError(t, err, "cannot decode json response")
+				require.Equal(t, testTreatyID, got.ID)
+			}
+		})
+	}
+}
+
+func TestListTreaties(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		mockList     func(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error)
+		expectedCode int
+	}{
+		{
+			name: "listed treaties",
+			mockList: func(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error) {
+				require.Equal(t, []domain.RegionID{testRegionID}, filter.RegionIDs)
+				return []*domain.Treaty{}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "repo returns error",
+			mockList: func(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				treatySvc: &mocks.TreatyService{ListFunc: tc.mockList},
+			}
+
+			api := newTestAPI(t, opts)
+			uri := fmt.Sprintf("/treaty?regionId=%s", testRegionID)
+			req := newTestRequest(t, http.MethodGet, uri, "", false)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code")
+		})
+	}
+}
+
+func TestEvaluateTreaty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		query         string
+		mockEvaluate  func(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error)
+		expectedCode  int
+	}{
+		{
+			name:          "treaty evaluated",
+			authenticated: true,
+			mockEvaluate: func(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error) {
+				require.True(t, opts.PointInTime.IsZero())
+				return &domain.TreatyEvaluation{TreatyID: treatyID, Status: domain.TreatyStatusResolved}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "treaty evaluated at point in time",
+			authenticated: true,
+			query:         "?pointInTime=2025-04-05",
+			mockEvaluate: func(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error) {
+				require.Equal(t, time.Date(2025, time.April, 5, 0, 0, 0, 0, time.UTC), opts.PointInTime)
+				return &domain.TreatyEvaluation{TreatyID: treatyID, Status: domain.TreatyStatusSingle}, nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "invalid point in time",
+			authenticated: true,
+			query:         "?pointInTime=April",
+			expectedCode:  http.StatusBadRequest,
+		},
+		{
+			name:          "treaty not found",
+			authenticated: true,
+			mockEvaluate: func(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error) {
+				return nil, domain.ErrNotFound
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing userID",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "service returns error",
+			authenticated: true,
+			mockEvaluate: func(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error) {
+				return nil, errors.New("database error")
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := testAPIOptions{
+				treatySvc: &mocks.TreatyService{EvaluateFunc: tc.mockEvaluate},
+			}
+
+			api := newTestAPI(t, opts)
+			uri := fmt.Sprintf("/treaty/%s/evaluate%s", testTreatyID, tc.query)
+			req := newTestRequest(t, http.MethodGet, uri, "", tc.authenticated)
+			rr := httptest.NewRecorder()
+			api.Handler().ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedCode, rr.Code, "unexpected status code")
+
+			if rr.Code == http.StatusOK {
+				var got domain.TreatyEvaluation
+				err := json.NewDecoder(rr.Body).Decode(&got)
+				require.NoError(t, err, "cannot decode json response")
+				require.Equal(t, testTreatyID, got.TreatyID)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pumpkinlog/backend/internal/cmdutil"
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/ical"
+	"github.com/pumpkinlog/backend/internal/importer"
+	"github.com/pumpkinlog/backend/internal/service"
+)
+
+func ImportCmd(ctx context.Context) *cobra.Command {
+	var (
+		userID int64
+		file   string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Args:  cobra.ExactArgs(0),
+		Short: "Import a user's travel history from a CSV, GPX, KML, GeoJSON or iCalendar file.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return errors.New("file is required")
+			}
+
+			debug, err := cmd.Flags().GetBool("debug")
+			if err != nil {
+				return err
+			}
+			logger := cmdutil.NewLogger(debug)
+
+			f, err := os.Open(file)
+			if err != nil {
+				return fmt.Errorf("cannot open import file: %w", err)
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+
+			// Track and calendar files are recognised by their extension, and anything else is read as CSV
+			var (
+				rows   []*domain.ImportRow
+				points []domain.TrackPoint
+				events []*domain.CalendarEvent
+			)
+
+			switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), ".")); ext {
+			case "gpx", "kml", "geojson":
+				points, err = importer.ParseTrack(importer.TrackFormat(ext), f)
+			case "ics":
+				events, err = ical.Parse(f)
+			default:
+				rows, err = importer.ParseCSV(f)
+			}
+			if err != nil {
+				return err
+			}
+
+			db, err := cmdutil.NewDatabasePoolWithRetry(ctx, 3)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			_, ch, err := cmdutil.NewRabbitMQClient()
+			if err != nil {
+				return err
+			}
+
+			presenceSvc := service.NewPresenceService(logger, db, ch)
+
+			var result *domain.PresenceImport
+			switch {
+			case points != nil:
+				result, err = presenceSvc.ImportTrack(ctx, userID, points, dryRun)
+			case events != nil:
+				result, err = presenceSvc.ImportCalendar(ctx, userID, events, dryRun)
+			default:
+				result, err = presenceSvc.Import(ctx, userID, rows, dryRun)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := printImport(cmd.OutOrStdout(), result); err != nil {
+				return err
+			}
+
+			if !result.DryRun && !result.Committed {
+				return fmt.Errorf("import not committed, %d invalid rows", result.Invalid)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64Var(&userID, "user", 0, "The user to import presences for")
+	cmd.Flags().StringVar(&file, "file", "", "The CSV file to import, with region, start and end columns, a .gpx, .kml or .geojson track, or an .ics calendar")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the diff without importing")
+
+	return cmd
+}
+
+func printImport(out io.Writer, result *domain.PresenceImport) error {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "LINE\tREGION\tSTART\tEND\tSTATUS\tNEW\tOVERLAP\tAMBIGUOUS\tERROR")
+	for _, row := range result.Rows {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%t\t%s\n",
+			row.Line, row.RegionID, formatDate(row.Start), formatDate(row.End), row.Status, row.NewDays, row.OverlapDays, row.Ambiguous, row.Error)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	status := "dry run"
+	if result.Committed {
+		status = "committed"
+	} else if !result.DryRun {
+		status = "not committed"
+	}
+
+	_, err := fmt.Fprintf(out, "\n%d rows, %d new days, %d invalid rows, %d skipped points (%s)\n", len(result.Rows), result.NewDays, result.Invalid, result.SkippedPoints, status)
+	return err
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.DateOnly)
+}
@@ -58,6 +58,7 @@ func Execute(ctx context.Context) int {
 	rootCmd.AddCommand(APICmd(ctx))
 	rootCmd.AddCommand(WorkerCmd(ctx))
 	rootCmd.AddCommand(SeedCmd(ctx))
+	rootCmd.AddCommand(ImportCmd(ctx))
 
 	go func() {
 		_ = http.ListenAndServe("localhost:6060", nil)
@@ -12,6 +12,7 @@ import (
 
 var queues = map[string]worker.NewWorkerFn{
 	"presence": worker.NewEvaluationWorker,
+	"ping":     worker.NewPingWorker,
 }
 
 func WorkerCmd(ctx context.Context) *cobra.Command {
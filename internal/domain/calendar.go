@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxCalendarEvents caps the number of events a single calendar import can hold.
+const MaxCalendarEvents = MaxImportRows
+
+// CalendarEvent is an event read from an iCalendar file, such as a trip in a travel calendar.
+type CalendarEvent struct {
+	// Line is the line of the file the event began on.
+	Line     int
+	UID      string
+	Summary  string
+	Location string
+	// RegionID is the region code carried by the event, such as one exported by the calendar feed.
+	RegionID RegionID
+	// Geo is the [lat, lng] position of the event.
+	Geo   *[2]float64
+	Start time.Time
+	// End is exclusive, and is zero when the event has a duration or no end.
+	End      time.Time
+	Duration time.Duration
+	// AllDay events are dates rather than times, and end on the day before End.
+	AllDay bool
+	// Floating events are wall clock times in UTC, taken in the time zone of the region they are in.
+	Floating bool
+	// Err is set when the event could not be read.
+	Err error
+}
+
+// Days returns the first and last calendar day of the event in the time zone, at midnight UTC.
+func (e *CalendarEvent) Days(loc *time.Location) (time.Time, time.Time) {
+	end := e.End
+	if end.IsZero() {
+		end = e.Start.Add(e.Duration)
+	}
+
+	if e.AllDay {
+		last := end.AddDate(0, 0, -1)
+		if last.Before(e.Start) {
+			last = e.Start
+		}
+		return e.Start, last
+	}
+
+	if e.Floating {
+		loc = time.UTC
+	}
+
+	if end.Before(e.Start) {
+		end = e.Start
+	}
+
+	return LocalDate(e.Start, loc), LocalDate(end, loc)
+}
+
+// CalendarFeed is the calendar of a user's presences, served as an iCalendar feed.
+type CalendarFeed struct {
+	Name   string
+	Events []*CalendarFeedEvent
+}
+
+// CalendarFeedEvent is an all-day event of the calendar feed.
+type CalendarFeedEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	RegionID    RegionID
+	// Start and End are the first and last day of the event.
+	Start time.Time
+	End   time.Time
+	// Reminder marks an upcoming threshold date, which carries an alarm.
+	Reminder bool
+}
+
+// NewCalendarFeed builds the feed of the user's presences and evaluations. Each run of consecutive
+// days in a region is an event, and each rule threshold the user would reach by staying on in the
+// region, within the current period, is a reminder on the day it would be reached.
+func NewCalendarFeed(regions map[RegionID]*Region, presences []*Presence, evaluations []*RegionEvaluation) *CalendarFeed {
+	feed := &CalendarFeed{
+		Name:   "Pumpkinlog presences",
+		Events: make([]*CalendarFeedEvent, 0),
+	}
+
+	name := func(regionID RegionID) string {
+		if region, ok := regions[regionID]; ok && region.Name != "" {
+			return region.Name
+		}
+		return string(regionID)
+	}
+
+	sorted := slices.Clone(presences)
+	slices.SortFunc(sorted, func(a, b *Presence) int {
+		if c := strings.Compare(string(a.RegionID), string(b.RegionID)); c != 0 {
+			return c
+		}
+		return a.Date.Compare(b.Date)
+	})
+
+	var run *CalendarFeedEvent
+
+	for _, p := range sorted {
+		if run != nil && run.RegionID == p.RegionID && !p.Date.After(run.End.AddDate(0, 0, 1)) {
+			run.End = p.Date
+			continue
+		}
+
+		run = &CalendarFeedEvent{
+			UID:      fmt.Sprintf("presence-%s-%s@pumpkinlog", p.RegionID, p.Date.Format("20060102")),
+			Summary:  "In " + name(p.RegionID),
+			RegionID: p.RegionID,
+			Start:    p.Date,
+			End:      p.Date,
+		}
+		feed.Events = append(feed.Events, run)
+	}
+
+	for _, evaluation := range evaluations {
+		loc := time.UTC
+		if region, ok := regions[evaluation.RegionID]; ok {
+			loc = region.Location()
+		}
+		today := LocalDate(evaluation.PointInTime, loc)
+
+		for _, se := range strategyEvaluations(evaluation.Nodes) {
+			if se.Passed || se.Status != EvaluationStatusEvaluated || se.Remaining <= 0 {
+				continue
+			}
+
+			date := today.AddDate(0, 0, se.Remaining)
+			if !se.End.IsZero() && date.After(se.End) {
+				continue
+			}
+
+			uid := fmt.Sprintf("threshold-%s-%s-%d-%s@pumpkinlog", evaluation.RegionID, se.Strategy, se.Threshold, date.Format("20060102"))
+			if slices.ContainsFunc(feed.Events, func(e *CalendarFeedEvent) bool { return e.UID == uid }) {
+				continue
+			}
+
+			feed.Events = append(feed.Events, &CalendarFeedEvent{
+				UID:         uid,
+				Summary:     fmt.Sprintf("%s: %d day threshold", name(evaluation.RegionID), se.Threshold),
+				Description: fmt.Sprintf("Staying in %s until this day reaches the %d day threshold of the %s rule, with %d days remaining.", name(evaluation.RegionID), se.Threshold, se.Strategy, se.Remaining),
+				RegionID:    evaluation.RegionID,
+				Start:       date,
+				End:         date,
+				Reminder:    true,
+			})
+		}
+	}
+
+	slices.SortStableFunc(feed.Events, func(a, b *CalendarFeedEvent) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return feed
+}
+
+// strategyEvaluations returns the strategies of the components, including those nested in
+// composites. Referenced rules belong to other regions, and are left to their own evaluation.
+func strategyEvaluations(components []EvaluationComponent) []*StrategyEvaluation {
+	var strategies []*StrategyEvaluation
+
+	for _, component := range components {
+		switch c := component.(type) {
+		case *StrategyEvaluation:
+			strategies = append(strategies, c)
+		case *CompositeEvaluation:
+			strategies = append(strategies, strategyEvaluations(c.Components)...)
+		}
+	}
+
+	return strategies
+}
+
+type CalendarService interface {
+	// CreateFeedToken issues a new secret token for the user's calendar feed, revoking any earlier
+	// token.
+	CreateFeedToken(ctx context.Context, userID int64) (string, error)
+	// Feed returns the calendar feed of the user the token was issued to.
+	Feed(ctx context.Context, token string) (*CalendarFeed, error)
+}
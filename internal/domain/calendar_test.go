@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarEventDays(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	date := func(day int) time.Time {
+		return time.Date(2025, time.June, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		event     CalendarEvent
+		loc       *time.Location
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "all-day event ends the day before its end",
+			event:     CalendarEvent{Start: date(1), End: date(4), AllDay: true},
+			loc:       time.UTC,
+			wantStart: date(1),
+			wantEnd:   date(3),
+		},
+		{
+			name:      "all-day event without an end",
+			event:     CalendarEvent{Start: date(1), AllDay: true},
+			loc:       time.UTC,
+			wantStart: date(1),
+			wantEnd:   date(1),
+		},
+		{
+			name:      "timed event in the region time zone",
+			event:     CalendarEvent{Start: time.Date(2025, time.June, 10, 2, 0, 0, 0, time.UTC), End: time.Date(2025, time.June, 11, 2, 0, 0, 0, time.UTC)},
+			loc:       newYork,
+			wantStart: date(9),
+			wantEnd:   date(10),
+		},
+		{
+			name:      "floating event with a duration",
+			event:     CalendarEvent{Start: time.Date(2025, time.June, 10, 20, 0, 0, 0, time.UTC), Duration: 6 * time.Hour, Floating: true},
+			loc:       newYork,
+			wantStart: date(10),
+			wantEnd:   date(11),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := tc.event.Days(tc.loc)
+			require.Equal(t, tc.wantStart, start)
+			require.Equal(t, tc.wantEnd, end)
+		})
+	}
+}
+
+func TestNewCalendarFeed(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	regions := map[RegionID]*Region{
+		"JE": {ID: "JE", Name: "Jersey", TimeZone: "Europe/Jersey"},
+	}
+
+	presences := []*Presence{
+		{RegionID: "JE", Date: date(time.June, 2)},
+		{RegionID: "GG", Date: date(time.June, 4)},
+		{RegionID: "JE", Date: date(time.June, 1)},
+		{RegionID: "JE", Date: date(time.June, 3)},
+		{RegionID: "JE", Date: date(time.June, 10)},
+	}
+
+	evaluations := []*RegionEvaluation{
+		{
+			RegionID:    "JE",
+			PointInTime: time.Date(2025, time.June, 10, 23, 30, 0, 0, time.UTC),
+			Nodes: []EvaluationComponent{
+				&CompositeEvaluation{
+					NodeType: NodeTypeCompositeAny,
+					Components: []EvaluationComponent{
+						// Reached within the period, on the 11th in Jersey plus 90 days
+						&StrategyEvaluation{Strategy: "count", Status: EvaluationStatusEvaluated, Threshold: 91, Remaining: 90, End: date(time.December, 31)},
+						// Reached after the period ends
+						&StrategyEvaluation{Strategy: "count", Status: EvaluationStatusEvaluated, Threshold: 400, Remaining: 395, End: date(time.December, 31)},
+						// Already passed
+						&StrategyEvaluation{Strategy: "count", Status: EvaluationStatusEvaluated, Passed: true, Threshold: 4},
+					},
+				},
+				&StrategyEvaluation{Strategy: "count", Status: EvaluationStatusUnanswered, Threshold: 30, Remaining: 26},
+				&ReferenceEvaluation{RuleID: "other"},
+			},
+		},
+	}
+
+	feed := NewCalendarFeed(regions, presences, evaluations)
+
+	require.Equal(t, []*CalendarFeedEvent{
+		{UID: "presence-JE-20250601@pumpkinlog", Summary: "In Jersey", RegionID: "JE", Start: date(time.June, 1), End: date(time.June, 3)},
+		{UID: "presence-GG-20250604@pumpkinlog", Summary: "In GG", RegionID: "GG", Start: date(time.June, 4), End: date(time.June, 4)},
+		{UID: "presence-JE-20250610@pumpkinlog", Summary: "In Jersey", RegionID: "JE", Start: date(time.June, 10), End: date(time.June, 10)},
+		{
+			UID:         "threshold-JE-count-91-20250909@pumpkinlog",
+			Summary:     "Jersey: 91 day threshold",
+			Description: "Staying in Jersey until this day reaches the 91 day threshold of the count rule, with 90 days remaining.",
+			RegionID:    "JE",
+			Start:       date(time.September, 9),
+			End:         date(time.September, 9),
+			Reminder:    true,
+		},
+	}, feed.Events)
+}
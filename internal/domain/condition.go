@@ -12,6 +12,8 @@ const (
 	ConditionTypeInteger     ConditionType = "integer"
 	ConditionTypeSelect      ConditionType = "select"
 	ConditionTypeMultiSelect ConditionType = "multi_select"
+	// ConditionTypeDate is answered with a date formatted as YYYY-MM-DD.
+	ConditionTypeDate ConditionType = "date"
 )
 
 type Condition struct {
@@ -23,13 +25,29 @@ type Condition struct {
 
 func (t ConditionType) Valid() bool {
 	switch t {
-	case ConditionTypeString, ConditionTypeBoolean, ConditionTypeInteger, ConditionTypeSelect, ConditionTypeMultiSelect:
+	case ConditionTypeString, ConditionTypeBoolean, ConditionTypeInteger, ConditionTypeSelect, ConditionTypeMultiSelect, ConditionTypeDate:
 		return true
 	default:
 		return false
 	}
 }
 
+// Supports reports whether answers of the condition type can be compared with the comparator.
+func (t ConditionType) Supports(c Comparator) bool {
+	switch c {
+	case ComparatorEquals, ComparatorNotEquals:
+		return true
+	case ComparatorGreater, ComparatorGreaterOrEq, ComparatorLess, ComparatorLessOrEq:
+		return t == ConditionTypeInteger
+	case ComparatorIn:
+		return t == ConditionTypeString || t == ConditionTypeInteger || t == ConditionTypeSelect
+	case ComparatorContains, ComparatorContainsAny, ComparatorContainsAll:
+		return t == ConditionTypeMultiSelect
+	default:
+		return false
+	}
+}
+
 func (c *Condition) Validate() error {
 	if err := c.ID.Validate(); err != nil {
 		return err
@@ -37,6 +37,11 @@ func TestConditionType_Valid(t *testing.T) {
 			ConditionTypeMultiSelect,
 			true,
 		},
+		{
+			"valid date",
+			ConditionTypeDate,
+			true,
+		},
 		{
 			"invalid type",
 			ConditionType("invalid"),
@@ -115,3 +120,27 @@ func TestValidateCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestConditionType_Supports(t *testing.T) {
+	tests := []struct {
+		ct         ConditionType
+		comparator Comparator
+		want       bool
+	}{
+		{ConditionTypeBoolean, ComparatorEquals, true},
+		{ConditionTypeBoolean, ComparatorGreater, false},
+		{ConditionTypeInteger, ComparatorLessOrEq, true},
+		{ConditionTypeInteger, ComparatorIn, true},
+		{ConditionTypeSelect, ComparatorIn, true},
+		{ConditionTypeSelect, ComparatorContains, false},
+		{ConditionTypeMultiSelect, ComparatorContainsAll, true},
+		{ConditionTypeMultiSelect, ComparatorIn, false},
+		{ConditionTypeString, "invalid", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.ct)+"_"+string(tc.comparator), func(t *testing.T) {
+			require.Equal(t, tc.want, tc.ct.Supports(tc.comparator))
+		})
+	}
+}
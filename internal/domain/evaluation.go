@@ -2,24 +2,83 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
+// MaxConcurrentEvaluations caps how many regions are evaluated at once when evaluating every region
+// relevant to a user.
+const MaxConcurrentEvaluations = 4
+
 type EvaluationContext struct {
-	At        time.Time
-	Region    *Region
-	Presences []*Presence
-	Rules     []*Rule
-	Answers   map[Code]*Answer
+	At     time.Time
+	Region *Region
+	// SubregionIDs are the descendants of the region, counted only by rules that include subregions.
+	SubregionIDs []RegionID
+	Presences    []*Presence
+	Rules        []*Rule
+	Conditions   map[Code]*Condition
+	Answers      map[Code]*Answer
+	// Dependencies are the contexts of other regions with rules referenced by this region's rules.
+	Dependencies map[RegionID]*EvaluationContext
 }
 
 type RegionEvaluation struct {
-	RegionID    RegionID              `json:"regionId"`
-	UserID      int64                 `json:"userId"`
-	Passed      bool                  `json:"passed"`
-	Nodes       []EvaluationComponent `json:"nodes"`
-	PointInTime time.Time             `json:"pointInTime"`
-	EvaluatedAt time.Time             `json:"evaluatedAt"`
+	RegionID RegionID         `json:"regionId"`
+	UserID   int64            `json:"userId"`
+	Passed   bool             `json:"passed"`
+	Status   EvaluationStatus `json:"status"`
+	Reason   string           `json:"reason,omitempty"`
+	// Unresolved lists the unanswered conditions that could change an indeterminate outcome.
+	Unresolved []Code                `json:"unresolved,omitempty"`
+	Nodes      []EvaluationComponent `json:"nodes"`
+	// Explanations are only rendered in explain mode, one per rule, and are never cached.
+	Explanations []*Explanation `json:"explanations,omitempty"`
+	PointInTime  time.Time      `json:"pointInTime"`
+	EvaluatedAt  time.Time      `json:"evaluatedAt"`
+}
+
+func (e *RegionEvaluation) UnmarshalJSON(data []byte) error {
+	type alias RegionEvaluation
+
+	var raw struct {
+		*alias
+		Nodes []json.RawMessage `json:"nodes"`
+	}
+	raw.alias = (*alias)(e)
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.Nodes == nil {
+		e.Nodes = nil
+		return nil
+	}
+
+	nodes, err := unmarshalEvaluationComponents(raw.Nodes)
+	if err != nil {
+		return err
+	}
+	e.Nodes = nodes
+
+	return nil
+}
+
+// IsFresh reports whether a cached evaluation of the region still holds at t. Cached evaluations are
+// cleared when presences, answers or rules change, but periods move with every day, so an evaluation
+// is only fresh on the region's calendar day it was evaluated for. The point in time is already a
+// wall clock time in the region, so only t is converted.
+func (e *RegionEvaluation) IsFresh(region *Region, t time.Time) bool {
+	if e.Status == EvaluationStatusError {
+		return false
+	}
+
+	y1, m1, d1 := e.PointInTime.Date()
+	y2, m2, d2 := region.LocalTime(t).Date()
+
+	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
 type (
@@ -30,15 +89,84 @@ type (
 const (
 	EvaluationStatusEvaluated  EvaluationStatus = "evaluated"
 	EvaluationStatusUnanswered EvaluationStatus = "unanswered"
-	EvaluationStatusError      EvaluationStatus = "error"
+	// EvaluationStatusError means the outcome cannot be determined, e.g. an answer of the wrong type or
+	// a referenced rule that does not exist. Unlike indeterminate, no answer can resolve it.
+	EvaluationStatusError EvaluationStatus = "error"
+	// EvaluationStatusIndeterminate means the outcome depends on conditions that have not been answered.
+	EvaluationStatusIndeterminate EvaluationStatus = "indeterminate"
 
 	ComponentTypeComposite ComponentType = "composite"
 	ComponentTypeStrategy  ComponentType = "strategy"
 	ComponentTypeCondition ComponentType = "condition"
+	ComponentTypeReference ComponentType = "reference"
 )
 
+// EvaluationComponent is an evaluated rule node. Components follow three-valued logic: a component
+// is passed, failed or indeterminate when its outcome depends on an unanswered condition. A
+// component that cannot be evaluated at all is errored, which no answer can resolve.
 type EvaluationComponent interface {
 	IsPassed() bool
+	IsIndeterminate() bool
+	IsErrored() bool
+}
+
+// UnmarshalEvaluationComponents decodes evaluated rule nodes, such as cached evaluation details,
+// into their concrete component types.
+func UnmarshalEvaluationComponents(data []byte) ([]EvaluationComponent, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return unmarshalEvaluationComponents(raw)
+}
+
+func unmarshalEvaluationComponents(raw []json.RawMessage) ([]EvaluationComponent, error) {
+	components := make([]EvaluationComponent, 0, len(raw))
+
+	for _, data := range raw {
+		component, err := unmarshalEvaluationComponent(data)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	return components, nil
+}
+
+// unmarshalEvaluationComponent decodes a single component. Leaf components carry their type,
+// composites carry the type of the rule node they evaluated.
+func unmarshalEvaluationComponent(data json.RawMessage) (EvaluationComponent, error) {
+	var probe struct {
+		Type     ComponentType `json:"type"`
+		NodeType NodeType      `json:"nodeType"`
+	}
+
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	var component EvaluationComponent
+
+	switch {
+	case probe.Type == ComponentTypeStrategy:
+		component = &StrategyEvaluation{}
+	case probe.Type == ComponentTypeCondition:
+		component = &ConditionEvaluation{}
+	case probe.Type == ComponentTypeReference:
+		component = &ReferenceEvaluation{}
+	case probe.NodeType != "":
+		component = &CompositeEvaluation{}
+	default:
+		return nil, fmt.Errorf("unknown evaluation component type: %q", probe.Type)
+	}
+
+	if err := json.Unmarshal(data, component); err != nil {
+		return nil, err
+	}
+
+	return component, nil
 }
 
 type RuleEvaluation struct {
@@ -47,16 +175,50 @@ type RuleEvaluation struct {
 }
 
 type CompositeEvaluation struct {
-	NodeType   NodeType              `json:"nodeType"`
-	Status     EvaluationStatus      `json:"status"`
-	Passed     bool                  `json:"passed"`
+	NodeType NodeType         `json:"nodeType"`
+	Status   EvaluationStatus `json:"status"`
+	Passed   bool             `json:"passed"`
+	// PassedCount is the number of child components that passed.
+	PassedCount int `json:"passedCount"`
+	// Required is the number of child components that must pass for an "and", "any" or "atLeast" node.
+	Required   int                   `json:"required,omitempty"`
 	Components []EvaluationComponent `json:"components"`
 }
 
+func (e *CompositeEvaluation) UnmarshalJSON(data []byte) error {
+	type alias CompositeEvaluation
+
+	var raw struct {
+		*alias
+		Components []json.RawMessage `json:"components"`
+	}
+	raw.alias = (*alias)(e)
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	components, err := unmarshalEvaluationComponents(raw.Components)
+	if err != nil {
+		return err
+	}
+	e.Components = components
+
+	return nil
+}
+
 func (e *CompositeEvaluation) IsPassed() bool {
 	return e.Passed
 }
 
+func (e *CompositeEvaluation) IsIndeterminate() bool {
+	return e.Status == EvaluationStatusIndeterminate
+}
+
+func (e *CompositeEvaluation) IsErrored() bool {
+	return e.Status == EvaluationStatusError
+}
+
 type StrategyEvaluation struct {
 	Type      ComponentType    `json:"type"`
 	Strategy  string           `json:"strategy"`
@@ -66,13 +228,36 @@ type StrategyEvaluation struct {
 	Start     time.Time        `json:"start"`
 	End       time.Time        `json:"end"`
 	Count     int              `json:"count"`
+	Threshold int              `json:"threshold"`
 	Remaining int              `json:"remaining"`
+	Metadata  map[string]any   `json:"metadata,omitempty"`
+	// Split reports how the boundaries of a split-year period were derived.
+	Split *PeriodSplit `json:"split,omitempty"`
 }
 
 func (e *StrategyEvaluation) IsPassed() bool {
 	return e.Passed
 }
 
+func (e *StrategyEvaluation) IsIndeterminate() bool {
+	return e.Status == EvaluationStatusUnanswered
+}
+
+func (e *StrategyEvaluation) IsErrored() bool {
+	return e.Status == EvaluationStatusError
+}
+
+// PeriodSplit is the tax year a split-year period falls in, and the boundaries the period start
+// and end were derived from.
+type PeriodSplit struct {
+	YearStart     time.Time      `json:"yearStart"`
+	YearEnd       time.Time      `json:"yearEnd"`
+	StartBoundary PeriodBoundary `json:"startBoundary"`
+	EndBoundary   PeriodBoundary `json:"endBoundary"`
+	// Unresolved lists the date conditions that must be answered to derive the boundaries.
+	Unresolved []Code `json:"unresolved,omitempty"`
+}
+
 type ConditionEvaluation struct {
 	Type        ComponentType    `json:"type"`
 	ConditionID Code             `json:"conditionId"`
@@ -88,6 +273,67 @@ func (e *ConditionEvaluation) IsPassed() bool {
 	return e.Passed
 }
 
+func (e *ConditionEvaluation) IsIndeterminate() bool {
+	return e.Status == EvaluationStatusUnanswered
+}
+
+func (e *ConditionEvaluation) IsErrored() bool {
+	return e.Status == EvaluationStatusError
+}
+
+// ReferenceEvaluation is the result of a rule node referencing another rule, possibly in another region.
+type ReferenceEvaluation struct {
+	Type      ComponentType       `json:"type"`
+	RuleID    Code                `json:"ruleId"`
+	RegionID  RegionID            `json:"regionId,omitempty"`
+	Status    EvaluationStatus    `json:"status"`
+	Passed    bool                `json:"passed"`
+	Reason    string              `json:"reason"`
+	Component EvaluationComponent `json:"component,omitempty"`
+}
+
+func (e *ReferenceEvaluation) UnmarshalJSON(data []byte) error {
+	type alias ReferenceEvaluation
+
+	var raw struct {
+		*alias
+		Component json.RawMessage `json:"component"`
+	}
+	raw.alias = (*alias)(e)
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	e.Component = nil
+	if len(raw.Component) == 0 || string(raw.Component) == "null" {
+		return nil
+	}
+
+	component, err := unmarshalEvaluationComponent(raw.Component)
+	if err != nil {
+		return err
+	}
+	e.Component = component
+
+	return nil
+}
+
+func (e *ReferenceEvaluation) IsPassed() bool {
+	return e.Passed
+}
+
+func (e *ReferenceEvaluation) IsIndeterminate() bool {
+	return e.Component != nil && e.Component.IsIndeterminate()
+}
+
+func (e *ReferenceEvaluation) IsErrored() bool {
+	if e.Component == nil {
+		return e.Status == EvaluationStatusError
+	}
+	return e.Component.IsErrored()
+}
+
 type EvaluateOpts struct {
 	// PointInTime is the time at which to evaluate the region.
 	PointInTime time.Time
@@ -97,10 +343,20 @@ type EvaluateOpts struct {
 	Cache bool
 	// Publish indicates whether to publish the evaluation result to message queues.
 	Publish bool
+	// Explain renders a human-readable explanation of every rule. The region is always evaluated, as
+	// explanations need the rules and conditions behind a cached evaluation.
+	Explain bool
+	// ExcludeUnconfirmed evaluates only the days the user confirmed, leaving out derived days still to
+	// be reviewed. Such evaluations are never served from or written to the cache.
+	ExcludeUnconfirmed bool
 }
 
 type EvaluationService interface {
 	EvaluateRegion(ctx context.Context, userID int64, regionID RegionID, opts *EvaluateOpts) (*RegionEvaluation, error)
+	EvaluateRegions(ctx context.Context, userID int64) ([]*RegionEvaluation, error)
+	Forecast(ctx context.Context, userID int64, regionID RegionID, opts *ForecastOpts) (*Forecast, error)
+	WhatIf(ctx context.Context, userID int64, opts *WhatIfOpts) (*WhatIf, error)
+	Timeline(ctx context.Context, userID int64, regionID RegionID, opts *TimelineOpts) (*Timeline, error)
 }
 
 type EvaluationRepository interface {
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalEvaluationComponents(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	nodes := []EvaluationComponent{
+		&CompositeEvaluation{
+			NodeType:    NodeTypeCompositeAnd,
+			Status:      EvaluationStatusEvaluated,
+			Passed:      true,
+			PassedCount: 2,
+			Required:    2,
+			Components: []EvaluationComponent{
+				&StrategyEvaluation{
+					Type:     ComponentTypeStrategy,
+					Strategy: "aggregate",
+					Passed:   true,
+					Status:   EvaluationStatusEvaluated,
+					Start:    start,
+					End:      start.AddDate(1, 0, -1),
+					Count:    200,
+				},
+				&ConditionEvaluation{
+					Type:        ComponentTypeCondition,
+					ConditionID: "HAS_HOME",
+					Expected:    true,
+					Actual:      true,
+					Comparator:  ComparatorEquals,
+					Status:      EvaluationStatusEvaluated,
+					Passed:      true,
+				},
+			},
+		},
+		&ReferenceEvaluation{
+			Type:     ComponentTypeReference,
+			RuleID:   "GB_SRT",
+			RegionID: "GB",
+			Status:   EvaluationStatusUnanswered,
+			Component: &ConditionEvaluation{
+				Type:        ComponentTypeCondition,
+				ConditionID: "HAS_WORK",
+				Expected:    true,
+				Comparator:  ComparatorEquals,
+				Status:      EvaluationStatusUnanswered,
+			},
+		},
+		&ReferenceEvaluation{
+			Type:   ComponentTypeReference,
+			RuleID: "MISSING",
+			Status: EvaluationStatusError,
+			Reason: "referenced rule MISSING not found",
+		},
+	}
+
+	data, err := json.Marshal(nodes)
+	require.NoError(t, err)
+
+	got, err := UnmarshalEvaluationComponents(data)
+	require.NoError(t, err)
+	require.Equal(t, nodes, got)
+
+	_, err = UnmarshalEvaluationComponents([]byte(`[{"type":"unknown"}]`))
+	require.Error(t, err)
+}
+
+func TestRegionEvaluationIsFresh(t *testing.T) {
+	utc := &Region{ID: "GB"}
+	nz := &Region{ID: "NZ", TimeZone: "Pacific/Auckland"}
+	hi := &Region{ID: "US_HI", TimeZone: "Pacific/Honolulu"}
+
+	tests := []struct {
+		name   string
+		region *Region
+		// pit is the region's wall clock time the evaluation was made for
+		pit       time.Time
+		t         time.Time
+		wantFresh bool
+	}{
+		{
+			name:      "same day",
+			region:    utc,
+			pit:       time.Date(2025, time.June, 1, 9, 0, 0, 0, time.UTC),
+			t:         time.Date(2025, time.June, 1, 21, 0, 0, 0, time.UTC),
+			wantFresh: true,
+		},
+		{
+			name:   "next day",
+			region: utc,
+			pit:    time.Date(2025, time.June, 1, 9, 0, 0, 0, time.UTC),
+			t:      time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			// 23:30 on 1 June in Auckland is 11:30 UTC on 1 June, still the same local day
+			name:      "ahead of UTC late in the local day",
+			region:    nz,
+			pit:       time.Date(2025, time.June, 1, 1, 0, 0, 0, time.UTC),
+			t:         time.Date(2025, time.June, 1, 11, 30, 0, 0, time.UTC),
+			wantFresh: true,
+		},
+		{
+			// 00:30 on 2 June in Auckland is 12:30 UTC on 1 June, a new local day
+			name:   "ahead of UTC after local midnight",
+			region: nz,
+			pit:    time.Date(2025, time.June, 1, 23, 0, 0, 0, time.UTC),
+			t:      time.Date(2025, time.June, 1, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			// 23:30 on 1 June in Honolulu is 09:30 UTC on 2 June, still the same local day
+			name:      "behind UTC late in the local day",
+			region:    hi,
+			pit:       time.Date(2025, time.June, 1, 8, 0, 0, 0, time.UTC),
+			t:         time.Date(2025, time.June, 2, 9, 30, 0, 0, time.UTC),
+			wantFresh: true,
+		},
+		{
+			// 00:30 on 2 June in Honolulu is 10:30 UTC on 2 June, a new local day
+			name:   "behind UTC after local midnight",
+			region: hi,
+			pit:    time.Date(2025, time.June, 1, 23, 0, 0, 0, time.UTC),
+			t:      time.Date(2025, time.June, 2, 10, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			evaluation := &RegionEvaluation{Status: EvaluationStatusEvaluated, PointInTime: tc.pit}
+			require.Equal(t, tc.wantFresh, evaluation.IsFresh(tc.region, tc.t))
+
+			evaluation.Status = EvaluationStatusError
+			require.False(t, evaluation.IsFresh(tc.region, tc.t))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+// Explanation is a human-readable rendering of an evaluated rule or rule node. Explanations for
+// rules carry the rule name and description, and their children explain the nodes beneath.
+type Explanation struct {
+	RuleID      Code           `json:"ruleId,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Outcome     Outcome        `json:"outcome"`
+	Message     string         `json:"message"`
+	Children    []*Explanation `json:"children,omitempty"`
+}
+
+// ComparatorPhrase describes how an answer is compared against the expected value, e.g. "must be at least".
+func ComparatorPhrase(c Comparator) string {
+	switch c {
+	case ComparatorEquals:
+		return "must be"
+	case ComparatorNotEquals:
+		return "must not be"
+	case ComparatorGreater:
+		return "must be more than"
+	case ComparatorGreaterOrEq:
+		return "must be at least"
+	case ComparatorLess:
+		return "must be less than"
+	case ComparatorLessOrEq:
+		return "must be at most"
+	case ComparatorIn:
+		return "must be one of"
+	case ComparatorContains:
+		return "must include"
+	case ComparatorContainsAny:
+		return "must include any of"
+	case ComparatorContainsAll:
+		return "must include all of"
+	default:
+		return string(c)
+	}
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"time"
+)
+
+// MaxForecastDays caps how far ahead a forecast can look, as every day is evaluated.
+const MaxForecastDays = 730
+
+// Outcome is the tri-state result of a rule or region evaluation.
+type Outcome string
+
+const (
+	OutcomePassed        Outcome = "passed"
+	OutcomeFailed        Outcome = "failed"
+	OutcomeIndeterminate Outcome = "indeterminate"
+	// OutcomeError is the outcome of an evaluation that could not be determined whatever is answered.
+	OutcomeError Outcome = "error"
+)
+
+// OutcomeOf returns the outcome of an evaluated component.
+func OutcomeOf(c EvaluationComponent) Outcome {
+	switch {
+	case c.IsErrored():
+		return OutcomeError
+	case c.IsIndeterminate():
+		return OutcomeIndeterminate
+	case c.IsPassed():
+		return OutcomePassed
+	default:
+		return OutcomeFailed
+	}
+}
+
+// Outcome returns the outcome of the region evaluation.
+func (e *RegionEvaluation) Outcome() Outcome {
+	switch {
+	case e.Status == EvaluationStatusError:
+		return OutcomeError
+	case e.Status == EvaluationStatusIndeterminate:
+		return OutcomeIndeterminate
+	case e.Passed:
+		return OutcomePassed
+	default:
+		return OutcomeFailed
+	}
+}
+
+// PlannedPresence is a range of days the user intends to spend in a region.
+type PlannedPresence struct {
+	RegionID RegionID  `json:"regionId"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+func (p *PlannedPresence) Validate() error {
+	if err := p.RegionID.Validate(); err != nil {
+		return err
+	}
+
+	if p.Start.IsZero() || p.End.IsZero() {
+		return ValidationError("planned presence start and end are required")
+	}
+
+	if p.End.Before(p.Start) {
+		return ValidationError("planned presence end cannot be before start")
+	}
+
+	return nil
+}
+
+// Presences expands the planned range into daily presences for the user.
+func (p *PlannedPresence) Presences(userID int64) []*Presence {
+	var presences []*Presence
+
+	start := time.Date(p.Start.Year(), p.Start.Month(), p.Start.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(p.End.Year(), p.End.Month(), p.End.Day(), 0, 0, 0, 0, time.UTC)
+
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		presences = append(presences, &Presence{
+			UserID:   userID,
+			RegionID: p.RegionID,
+			Date:     date,
+		})
+	}
+
+	return presences
+}
+
+type ForecastOpts struct {
+	// From is the first day of the forecast. It defaults to today.
+	From time.Time
+	// Until is the last day of the forecast.
+	Until time.Time
+	// Planned are presences the user intends to log, on top of those already logged.
+	Planned []PlannedPresence
+	// Stay assumes the user stays in the forecast region every day from the first day of the forecast.
+	Stay bool
+}
+
+func (o *ForecastOpts) Validate() error {
+	if o.Until.IsZero() {
+		return ValidationError("forecast until date is required")
+	}
+
+	if o.Until.Before(o.From) {
+		return ValidationError("forecast until date cannot be before from date")
+	}
+
+	if days := int(o.Until.Sub(o.From).Hours() / 24); days > MaxForecastDays {
+		return ValidationError("forecast cannot cover more than %d days", MaxForecastDays)
+	}
+
+	for _, p := range o.Planned {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Forecast projects a region evaluation across future days, reporting the first day the region and
+// each of its rules change outcome.
+type Forecast struct {
+	RegionID RegionID  `json:"regionId"`
+	From     time.Time `json:"from"`
+	Until    time.Time `json:"until"`
+	// Outcome is the outcome of the region on the first day of the forecast.
+	Outcome Outcome `json:"outcome"`
+	// ChangesOn is the first day the region outcome differs from the first day, if any.
+	ChangesOn *time.Time      `json:"changesOn,omitempty"`
+	ChangesTo Outcome         `json:"changesTo,omitempty"`
+	Rules     []*RuleForecast `json:"rules"`
+}
+
+type RuleForecast struct {
+	RuleID    Code       `json:"ruleId"`
+	Outcome   Outcome    `json:"outcome"`
+	ChangesOn *time.Time `json:"changesOn,omitempty"`
+	ChangesTo Outcome    `json:"changesTo,omitempty"`
+}
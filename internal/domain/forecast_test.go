@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateForecastOpts(t *testing.T) {
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	base := ForecastOpts{
+		From:  from,
+		Until: from.AddDate(0, 3, 0),
+		Planned: []PlannedPresence{
+			{RegionID: "JE", Start: from, End: from.AddDate(0, 0, 10)},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o ForecastOpts) ForecastOpts
+		wantErr error
+	}{
+		{
+			name:   "valid opts",
+			modify: func(o ForecastOpts) ForecastOpts { return o },
+		},
+		{
+			name: "missing until",
+			modify: func(o ForecastOpts) ForecastOpts {
+				o.Until = time.Time{}
+				return o
+			},
+			wantErr: ValidationError("forecast until date is required"),
+		},
+		{
+			name: "until before from",
+			modify: func(o ForecastOpts) ForecastOpts {
+				o.Until = from.AddDate(0, 0, -1)
+				return o
+			},
+			wantErr: ValidationError("forecast until date cannot be before from date"),
+		},
+		{
+			name: "too many days",
+			modify: func(o ForecastOpts) ForecastOpts {
+				o.Until = from.AddDate(0, 0, MaxForecastDays+1)
+				return o
+			},
+			wantErr: ValidationError("forecast cannot cover more than 730 days"),
+		},
+		{
+			name: "invalid planned region",
+			modify: func(o ForecastOpts) ForecastOpts {
+				o.Planned = []PlannedPresence{{Start: from, End: from}}
+				return o
+			},
+			wantErr: ValidationError("region ID is required"),
+		},
+		{
+			name: "planned end before start",
+			modify: func(o ForecastOpts) ForecastOpts {
+				o.Planned = []PlannedPresence{{RegionID: "JE", Start: from, End: from.AddDate(0, 0, -1)}}
+				return o
+			},
+			wantErr: ValidationError("planned presence end cannot be before start"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := tc.modify(base)
+			err := o.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPlannedPresencePresences(t *testing.T) {
+	start := time.Date(2025, time.June, 1, 15, 0, 0, 0, time.UTC)
+
+	p := PlannedPresence{RegionID: "JE", Start: start, End: start.AddDate(0, 0, 2)}
+
+	presences := p.Presences(1)
+	require.Len(t, presences, 3)
+
+	for i, presence := range presences {
+		require.Equal(t, int64(1), presence.UserID)
+		require.Equal(t, RegionID("JE"), presence.RegionID)
+		require.Equal(t, time.Date(2025, time.June, 1+i, 0, 0, 0, 0, time.UTC), presence.Date)
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MaxPingBatch caps the number of pings a device can send at once.
+const MaxPingBatch = 1000
+
+// maxPingClockSkew is how far ahead of the server clock a device's clock may run.
+const maxPingClockSkew = 5 * time.Minute
+
+// DevicePing is a location check-in sent by a device, with either a position or a region code.
+// Pings are only ever appended, and are folded into daily presences asynchronously.
+type DevicePing struct {
+	ID       int64 `json:"id"`
+	UserID   int64 `json:"userId"`
+	DeviceID int64 `json:"deviceId"`
+	// At is when the device was at the location.
+	At       time.Time   `json:"at"`
+	LatLng   *[2]float64 `json:"latLng,omitempty"`
+	RegionID *RegionID   `json:"regionId,omitempty"`
+	// ReceivedAt is when the ping was received, which may be long after it was taken.
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+func (p *DevicePing) Validate() error {
+	if p.UserID <= 0 {
+		return ValidationError("user ID is required")
+	}
+
+	if p.DeviceID <= 0 {
+		return ValidationError("device ID is required")
+	}
+
+	if p.At.IsZero() {
+		return ValidationError("ping time is required")
+	}
+
+	if p.At.After(time.Now().UTC().Add(maxPingClockSkew)) {
+		return ValidationError("ping time cannot be in the future")
+	}
+
+	if (p.LatLng == nil) == (p.RegionID == nil) {
+		return ValidationError("ping must have either a position or a region ID")
+	}
+
+	if p.LatLng != nil && !ValidLatLng(*p.LatLng) {
+		return ValidationError("ping position out of range: %v", *p.LatLng)
+	}
+
+	if p.RegionID != nil {
+		if err := p.RegionID.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if p.ReceivedAt.IsZero() {
+		return ValidationError("received at timestamp is required")
+	}
+
+	return nil
+}
+
+// PingReceipt reports how many pings of a batch were stored. Pings a device already sent for the
+// same time are duplicates, and are dropped.
+type PingReceipt struct {
+	Received   int `json:"received"`
+	Stored     int `json:"stored"`
+	Duplicates int `json:"duplicates"`
+}
+
+type PingService interface {
+	// Record stores a batch of pings from the user's device, and queues the days they cover to be
+	// folded into presences.
+	Record(ctx context.Context, userID, deviceID int64, pings []*DevicePing) (*PingReceipt, error)
+	// Fold records a presence for every region-local day the device was located in between start and
+	// end. Every ping of the days is folded, whenever it was received, so folding is idempotent and
+	// batches may arrive in any order.
+	Fold(ctx context.Context, userID, deviceID int64, start, end time.Time) error
+}
+
+type PingRepository interface {
+	// CreateBatch appends the pings, skipping any the device already sent for the same time, and
+	// returns the number stored.
+	CreateBatch(ctx context.Context, pings []*DevicePing) (int, error)
+	ListByPeriod(ctx context.Context, userID, deviceID int64, start, end time.Time) ([]*DevicePing, error)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDevicePing(t *testing.T) {
+	timestamp := time.Now()
+	regionID := RegionID("JE")
+	ping := DevicePing{
+		UserID:     1,
+		DeviceID:   1,
+		At:         timestamp.Add(-time.Hour),
+		LatLng:     &[2]float64{49.21, -2.13},
+		ReceivedAt: timestamp,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p DevicePing) DevicePing
+		wantErr error
+	}{
+		{
+			name:   "valid position ping",
+			modify: func(p DevicePing) DevicePing { return p },
+		},
+		{
+			name: "valid region ping",
+			modify: func(p DevicePing) DevicePing {
+				p.LatLng = nil
+				p.RegionID = &regionID
+				return p
+			},
+		},
+		{
+			name: "clock slightly ahead",
+			modify: func(p DevicePing) DevicePing {
+				p.At = time.Now().Add(time.Minute)
+				return p
+			},
+		},
+		{
+			name: "missing device ID",
+			modify: func(p DevicePing) DevicePing {
+				p.DeviceID = 0
+				return p
+			},
+			wantErr: ValidationError("device ID is required"),
+		},
+		{
+			name: "missing time",
+			modify: func(p DevicePing) DevicePing {
+				p.At = time.Time{}
+				return p
+			},
+			wantErr: ValidationError("ping time is required"),
+		},
+		{
+			name: "time in the future",
+			modify: func(p DevicePing) DevicePing {
+				p.At = time.Now().Add(time.Hour)
+				return p
+			},
+			wantErr: ValidationError("ping time cannot be in the future"),
+		},
+		{
+			name: "position and region",
+			modify: func(p DevicePing) DevicePing {
+				p.RegionID = &regionID
+				return p
+			},
+			wantErr: ValidationError("ping must have either a position or a region ID"),
+		},
+		{
+			name: "neither position nor region",
+			modify: func(p DevicePing) DevicePing {
+				p.LatLng = nil
+				return p
+			},
+			wantErr: ValidationError("ping must have either a position or a region ID"),
+		},
+		{
+			name: "position out of range",
+			modify: func(p DevicePing) DevicePing {
+				p.LatLng = &[2]float64{0, 181}
+				return p
+			},
+			wantErr: ValidationError("ping position out of range: [0 181]"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := tc.modify(ping)
+			err := p.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -5,15 +5,94 @@ import (
 	"time"
 )
 
+// maxUTCOffset is the furthest any time zone is ahead of UTC.
+const maxUTCOffset = 14 * time.Hour
+
+// PresenceProvenance records how a presence was recorded.
+type PresenceProvenance string
+
+const (
+	// PresenceProvenanceManual is a day entered by the user.
+	PresenceProvenanceManual PresenceProvenance = "manual"
+	// PresenceProvenanceGPS is a day derived from imported GPS location history.
+	PresenceProvenanceGPS PresenceProvenance = "gps"
+	// PresenceProvenanceCalendar is a day derived from an imported calendar event.
+	PresenceProvenanceCalendar PresenceProvenance = "calendar"
+	// PresenceProvenanceInference is a day inferred from the user's other days, such as the nights
+	// between a departure and a return.
+	PresenceProvenanceInference PresenceProvenance = "inference"
+)
+
+func (p PresenceProvenance) Valid() bool {
+	switch p {
+	case PresenceProvenanceManual, PresenceProvenanceGPS, PresenceProvenanceCalendar, PresenceProvenanceInference:
+		return true
+	default:
+		return false
+	}
+}
+
+// Confirmed reports whether days of the provenance count as confirmed when recorded. Only days the
+// user entered are, and other days stay unconfirmed until the user reviews them.
+func (p PresenceProvenance) Confirmed() bool {
+	return p == "" || p == PresenceProvenanceManual
+}
+
+// maxEvidenceLength caps the length of a presence's evidence reference.
+const maxEvidenceLength = 512
+
 type Presence struct {
-	UserID    int64     `json:"userId"`
-	RegionID  RegionID  `json:"regionId"`
-	Date      time.Time `json:"date"`
-	DeviceID  *string   `json:"deviceId,omitempty"`
+	UserID   int64     `json:"userId"`
+	RegionID RegionID  `json:"regionId"`
+	Date     time.Time `json:"date"`
+	DeviceID *string   `json:"deviceId,omitempty"`
+	// Arrival marks the day the user arrived in the region.
+	Arrival bool `json:"arrival"`
+	// Departure marks the day the user left the region.
+	Departure bool `json:"departure"`
+	// Transit marks a day the user only passed through the region, e.g. a connecting flight.
+	Transit    bool               `json:"transit"`
+	Provenance PresenceProvenance `json:"provenance"`
+	// Evidence references what the day was derived from, such as the UID of a calendar event or the
+	// ID of a device ping.
+	Evidence *string `json:"evidence,omitempty"`
+	// Confirmed marks a day the user entered or reviewed. Derived days are unconfirmed until then.
+	Confirmed bool `json:"confirmed"`
+	// Ambiguous marks a derived day the user was located in several regions, which is recorded in
+	// each of them.
+	Ambiguous bool      `json:"ambiguous"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// PresenceOpts holds optional details recorded against a range of presences.
+type PresenceOpts struct {
+	// Arrival marks the first day of the range as an arrival day.
+	Arrival bool
+	// Departure marks the last day of the range as a departure day.
+	Departure bool
+	// Transit marks every day of the range as a transit day.
+	Transit bool
+	// Provenance records how the range was recorded, defaulting to manual.
+	Provenance PresenceProvenance
+	// Evidence references what the range was derived from. Days already recorded keep their evidence.
+	Evidence string
+	// Ambiguous marks every day of the range as located in several regions.
+	Ambiguous bool
+}
+
+func (o *PresenceOpts) Validate() error {
+	if o.Provenance != "" && !o.Provenance.Valid() {
+		return ValidationError("unknown provenance: %s", o.Provenance)
+	}
+
+	if len(o.Evidence) > maxEvidenceLength {
+		return ValidationError("evidence cannot be longer than %d characters", maxEvidenceLength)
+	}
+
+	return nil
+}
+
 func (p *Presence) Validate() error {
 	if p.UserID <= 0 {
 		return ValidationError("user ID is required")
@@ -23,14 +102,29 @@ func (p *Presence) Validate() error {
 		return err
 	}
 
-	if p.Date.After(time.Now().UTC()) {
+	// Dates are region-local, so today in the furthest ahead time zone is still allowed
+	if p.Date.After(time.Now().UTC().Add(maxUTCOffset)) {
 		return ValidationError("date cannot be in the future")
 	}
 
+	if !p.Provenance.Valid() {
+		return ValidationError("unknown provenance: %s", p.Provenance)
+	}
+
 	if p.DeviceID != nil && *p.DeviceID == "" {
 		return ValidationError("device ID cannot be empty")
 	}
 
+	if p.Evidence != nil {
+		if *p.Evidence == "" {
+			return ValidationError("evidence cannot be empty")
+		}
+
+		if len(*p.Evidence) > maxEvidenceLength {
+			return ValidationError("evidence cannot be longer than %d characters", maxEvidenceLength)
+		}
+	}
+
 	if p.CreatedAt.IsZero() {
 		return ValidationError("created at timestamp is required")
 	}
@@ -52,10 +146,137 @@ func (p *Presence) Validate() error {
 	return nil
 }
 
+// MaxImportRows caps the number of rows a single presence import can hold.
+const MaxImportRows = 10000
+
+// MaxImportRowDays caps the number of days a single import row can span, ten years of travel.
+const MaxImportRowDays = 3660
+
+// MaxTrackPoints caps the number of points a single GPS track import can hold.
+const MaxTrackPoints = 500000
+
+// TrackPoint is a timestamped location from GPS location history.
+type TrackPoint struct {
+	At     time.Time
+	LatLng [2]float64
+}
+
+// ImportRow is a range of days to import, such as a line of a CSV travel history.
+type ImportRow struct {
+	// Line is the line of the source file the row was read from, if any.
+	Line       int                `json:"line,omitempty"`
+	RegionID   RegionID           `json:"regionId"`
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+	Arrival    bool               `json:"arrival"`
+	Departure  bool               `json:"departure"`
+	Transit    bool               `json:"transit"`
+	Provenance PresenceProvenance `json:"provenance,omitempty"`
+	Evidence   string             `json:"evidence,omitempty"`
+	Ambiguous  bool               `json:"ambiguous"`
+	// Err is set when the row could not be read, and is reported instead of validating the row.
+	Err error `json:"-"`
+}
+
+func (r *ImportRow) Validate() error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	if err := r.RegionID.Validate(); err != nil {
+		return err
+	}
+
+	if r.Provenance != "" && !r.Provenance.Valid() {
+		return ValidationError("unknown provenance: %s", r.Provenance)
+	}
+
+	if len(r.Evidence) > maxEvidenceLength {
+		return ValidationError("evidence cannot be longer than %d characters", maxEvidenceLength)
+	}
+
+	if r.Start.IsZero() {
+		return ValidationError("start date is required")
+	}
+
+	if r.End.IsZero() {
+		return ValidationError("end date is required")
+	}
+
+	if r.Start.After(r.End) {
+		return ValidationError("start date cannot be after end date")
+	}
+
+	if r.End.After(r.Start.AddDate(0, 0, MaxImportRowDays-1)) {
+		return ValidationError("row cannot span more than %d days", MaxImportRowDays)
+	}
+
+	if r.End.After(time.Now().UTC().Add(maxUTCOffset)) {
+		return ValidationError("end date cannot be in the future")
+	}
+
+	return nil
+}
+
+type ImportStatus string
+
+const (
+	// ImportStatusCreate means every day of the row is new.
+	ImportStatusCreate ImportStatus = "create"
+	// ImportStatusOverlap means some days of the row are already recorded in the region, either
+	// before the import or by an earlier row. Overlapping days are merged rather than duplicated.
+	ImportStatusOverlap ImportStatus = "overlap"
+	// ImportStatusInvalid means the row cannot be imported.
+	ImportStatusInvalid ImportStatus = "invalid"
+)
+
+// ImportRowResult is the diff of a single import row against the user's presences.
+type ImportRowResult struct {
+	ImportRow
+	Status ImportStatus `json:"status"`
+	// NewDays is the number of days the row adds.
+	NewDays int `json:"newDays"`
+	// OverlapDays is the number of days of the row that are already recorded.
+	OverlapDays int    `json:"overlapDays"`
+	Error       string `json:"error,omitempty"`
+}
+
+// PresenceImport is the diff of an import. An import is only committed when every row is valid,
+// and a dry run is never committed.
+type PresenceImport struct {
+	DryRun    bool               `json:"dryRun"`
+	Committed bool               `json:"committed"`
+	Rows      []*ImportRowResult `json:"rows"`
+	// NewDays is the number of days the import adds across every row.
+	NewDays int `json:"newDays"`
+	// Invalid is the number of invalid rows.
+	Invalid int `json:"invalid"`
+	// SkippedPoints is the number of track points without a time or outside every region boundary.
+	SkippedPoints int `json:"skippedPoints,omitempty"`
+}
+
 type PresenceService interface {
 	GetByID(ctx context.Context, userID int64, regionID RegionID, date time.Time) (*Presence, error)
 	List(ctx context.Context, userID int64, filter *PresenceFilter) ([]*Presence, error)
-	Create(ctx context.Context, userID int64, regionID RegionID, deviceID *int64, start, end time.Time) error
+	Create(ctx context.Context, userID int64, regionID RegionID, deviceID *int64, start, end time.Time, opts *PresenceOpts) error
+	// CreateFromTimestamps records the calendar days between two timestamps, taken in the IANA time
+	// zone or, when empty, in the region's time zone.
+	CreateFromTimestamps(ctx context.Context, userID int64, regionID RegionID, deviceID *int64, start, end time.Time, timeZone string, opts *PresenceOpts) error
+	// CreateRows records rows derived from a device, such as folded pings, in a single transaction
+	// without diffing them, and clears the evaluations they affect once.
+	CreateRows(ctx context.Context, userID int64, deviceID *int64, rows []*ImportRow) error
+	// Import diffs the rows against the user's presences and, unless a dry run, creates every row
+	// in a single transaction.
+	Import(ctx context.Context, userID int64, rows []*ImportRow, dryRun bool) (*PresenceImport, error)
+	// ImportTrack locates each point in a region and imports a presence for every region-local day
+	// the user was located in, as with Import.
+	ImportTrack(ctx context.Context, userID int64, points []TrackPoint, dryRun bool) (*PresenceImport, error)
+	// ImportCalendar resolves the region of each calendar event and imports the region-local days
+	// it covers, as with Import.
+	ImportCalendar(ctx context.Context, userID int64, events []*CalendarEvent, dryRun bool) (*PresenceImport, error)
+	// Confirm marks the days between start and end as reviewed by the user, or, when confirmed is
+	// false, returns them to review.
+	Confirm(ctx context.Context, userID int64, regionID RegionID, start, end time.Time, confirmed bool) error
 	Delete(ctx context.Context, userID int64, regionID RegionID, start, end time.Time) error
 }
 
@@ -63,14 +284,19 @@ type PresenceFilter struct {
 	RegionIDs []RegionID
 	Start     *time.Time
 	End       *time.Time
+	// Confirmed filters by whether the days are confirmed, when set.
+	Confirmed *bool
 }
 
 type PresenceRepository interface {
 	GetByID(ctx context.Context, userID int64, regionID RegionID, date time.Time) (*Presence, error)
 	List(ctx context.Context, userID int64, filter *PresenceFilter) ([]*Presence, error)
-	ListByRegionPeriod(ctx context.Context, userID int64, regionID RegionID, start, end time.Time) ([]*Presence, error)
+	ListByRegionPeriod(ctx context.Context, userID int64, regionIDs []RegionID, start, end time.Time) ([]*Presence, error)
+	ListRegionIDs(ctx context.Context, userID int64) ([]RegionID, error)
 	Create(ctx context.Context, location *Presence) error
-	CreateRange(ctx context.Context, userID int64, regionID RegionID, deviceID *int64, start, end time.Time) error
+	CreateRange(ctx context.Context, userID int64, regionID RegionID, deviceID *int64, start, end time.Time, opts *PresenceOpts) error
+	// ConfirmRange sets whether the recorded days between start and end are confirmed.
+	ConfirmRange(ctx context.Context, userID int64, regionID RegionID, start, end time.Time, confirmed bool) error
 	Delete(ctx context.Context, userID int64, regionID RegionID, date time.Time) error
 	DeleteRange(ctx context.Context, userID int64, regionID RegionID, start, end time.Time) error
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -11,12 +12,13 @@ func TestValidatePresence(t *testing.T) {
 	timestamp := time.Now()
 	deviceID := "device-123"
 	presence := Presence{
-		UserID:    1,
-		RegionID:  "JE",
-		Date:      timestamp,
-		DeviceID:  &deviceID,
-		CreatedAt: timestamp,
-		UpdatedAt: timestamp,
+		UserID:     1,
+		RegionID:   "JE",
+		Date:       timestamp,
+		DeviceID:   &deviceID,
+		Provenance: PresenceProvenanceManual,
+		CreatedAt:  timestamp,
+		UpdatedAt:  timestamp,
 	}
 
 	tests := []struct {
@@ -47,11 +49,26 @@ func TestValidatePresence(t *testing.T) {
 		{
 			name: "date in the future",
 			modify: func(p Presence) Presence {
-				p.Date = time.Now().Add(time.Minute)
+				p.Date = time.Now().Add(maxUTCOffset + time.Minute)
 				return p
 			},
 			wantErr: ValidationError("date cannot be in the future"),
 		},
+		{
+			name: "today in a time zone ahead of UTC",
+			modify: func(p Presence) Presence {
+				p.Date = LocalDate(time.Now(), time.FixedZone("UTC+14", int(maxUTCOffset.Seconds())))
+				return p
+			},
+		},
+		{
+			name: "unknown provenance",
+			modify: func(p Presence) Presence {
+				p.Provenance = "guess"
+				return p
+			},
+			wantErr: ValidationError("unknown provenance: guess"),
+		},
 		{
 			name: "device ID empty string",
 			modify: func(p Presence) Presence {
@@ -84,6 +101,30 @@ func TestValidatePresence(t *testing.T) {
 			},
 			wantErr: ValidationError("updated at timestamp is required"),
 		},
+		{
+			name: "inferred day",
+			modify: func(p Presence) Presence {
+				p.Provenance = PresenceProvenanceInference
+				return p
+			},
+		},
+		{
+			name: "empty evidence",
+			modify: func(p Presence) Presence {
+				p.Evidence = new(string)
+				return p
+			},
+			wantErr: ValidationError("evidence cannot be empty"),
+		},
+		{
+			name: "evidence too long",
+			modify: func(p Presence) Presence {
+				evidence := strings.Repeat("x", maxEvidenceLength+1)
+				p.Evidence = &evidence
+				return p
+			},
+			wantErr: ValidationError("evidence cannot be longer than %d characters", maxEvidenceLength),
+		},
 		{
 			name: "created at in the future",
 			modify: func(p Presence) Presence {
@@ -114,3 +155,140 @@ func TestValidatePresence(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateImportRow(t *testing.T) {
+	row := ImportRow{
+		Line:     2,
+		RegionID: "JE",
+		Start:    time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		End:      time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r ImportRow) ImportRow
+		wantErr error
+	}{
+		{
+			name:   "valid row",
+			modify: func(r ImportRow) ImportRow { return r },
+		},
+		{
+			name: "single day",
+			modify: func(r ImportRow) ImportRow {
+				r.End = r.Start
+				return r
+			},
+		},
+		{
+			name: "read error",
+			modify: func(r ImportRow) ImportRow {
+				r.Err = ValidationError("invalid start date: 01/02/2025")
+				return r
+			},
+			wantErr: ValidationError("invalid start date: 01/02/2025"),
+		},
+		{
+			name: "invalid region ID",
+			modify: func(r ImportRow) ImportRow {
+				r.RegionID = "Jersey"
+				return r
+			},
+			wantErr: ValidationError("region ID must match regular expression: %s", regionIDRegex),
+		},
+		{
+			name: "unknown provenance",
+			modify: func(r ImportRow) ImportRow {
+				r.Provenance = "guess"
+				return r
+			},
+			wantErr: ValidationError("unknown provenance: guess"),
+		},
+		{
+			name: "evidence too long",
+			modify: func(r ImportRow) ImportRow {
+				r.Evidence = strings.Repeat("x", maxEvidenceLength+1)
+				return r
+			},
+			wantErr: ValidationError("evidence cannot be longer than %d characters", maxEvidenceLength),
+		},
+		{
+			name: "missing start",
+			modify: func(r ImportRow) ImportRow {
+				r.Start = time.Time{}
+				return r
+			},
+			wantErr: ValidationError("start date is required"),
+		},
+		{
+			name: "missing end",
+			modify: func(r ImportRow) ImportRow {
+				r.End = time.Time{}
+				return r
+			},
+			wantErr: ValidationError("end date is required"),
+		},
+		{
+			name: "start after end",
+			modify: func(r ImportRow) ImportRow {
+				r.Start, r.End = r.End, r.Start
+				return r
+			},
+			wantErr: ValidationError("start date cannot be after end date"),
+		},
+		{
+			name: "longest span",
+			modify: func(r ImportRow) ImportRow {
+				r.Start = r.End.AddDate(0, 0, -(MaxImportRowDays - 1))
+				return r
+			},
+		},
+		{
+			name: "span too long",
+			modify: func(r ImportRow) ImportRow {
+				r.Start = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+				return r
+			},
+			wantErr: ValidationError("row cannot span more than %d days", MaxImportRowDays),
+		},
+		{
+			name: "end in the future",
+			modify: func(r ImportRow) ImportRow {
+				r.End = time.Now().AddDate(0, 0, 2)
+				return r
+			},
+			wantErr: ValidationError("end date cannot be in the future"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.modify(row)
+			err := r.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPresenceProvenanceConfirmed(t *testing.T) {
+	tests := []struct {
+		provenance PresenceProvenance
+		want       bool
+	}{
+		{provenance: "", want: true},
+		{provenance: PresenceProvenanceManual, want: true},
+		{provenance: PresenceProvenanceGPS, want: false},
+		{provenance: PresenceProvenanceCalendar, want: false},
+		{provenance: PresenceProvenanceInference, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.provenance), func(t *testing.T) {
+			require.Equal(t, tc.want, tc.provenance.Confirmed())
+		})
+	}
+}
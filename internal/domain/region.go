@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"time"
+	_ "time/tzdata" // regions resolve the same time zones on every host
 )
 
 type (
@@ -37,8 +38,93 @@ type Region struct {
 	Continent      Continent  `json:"continent"`
 	YearStartMonth time.Month `json:"yearStartMonth"`
 	YearStartDay   int        `json:"yearStartDay"`
-	LatLng         [2]float64 `json:"latLng"`
-	Sources        []Source   `json:"sources"`
+	// TimeZone is the IANA time zone whose calendar days presences in the region are counted in.
+	TimeZone string     `json:"timeZone"`
+	LatLng   [2]float64 `json:"latLng"`
+	Sources  []Source   `json:"sources"`
+	// MemberRegionIDs are the regions that make up a zone. A day present in any member region
+	// counts as a day present in the zone.
+	MemberRegionIDs []RegionID `json:"memberRegionIds,omitempty"`
+	// FiscalCalendar lists changes to the tax year start in date order. YearStartMonth and
+	// YearStartDay apply until the first change.
+	FiscalCalendar []FiscalYearStart `json:"fiscalCalendar,omitempty"`
+	// Boundary is the area of the region, used to locate GPS points offline.
+	Boundary []Polygon `json:"boundary,omitempty"`
+}
+
+// Polygon is a list of rings of [lat, lng] points. The first ring is the outer boundary and any
+// further rings are holes. Rings are implicitly closed.
+type Polygon [][][2]float64
+
+// ValidLatLng reports whether the point is a latitude and longitude in range.
+func ValidLatLng(p [2]float64) bool {
+	return p[0] >= -90 && p[0] <= 90 && p[1] >= -180 && p[1] <= 180
+}
+
+// FiscalYearStart is a tax year start in effect from a date. The first tax year under it starts on
+// From, cutting the previous tax year short.
+type FiscalYearStart struct {
+	From  time.Time  `json:"from"`
+	Month time.Month `json:"month"`
+	Day   int        `json:"day"`
+}
+
+// Location returns the region's time zone, or UTC when the region has none.
+func (r *Region) Location() *time.Location {
+	if r.TimeZone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// LocalTime returns the wall clock time in the region at t. The result is expressed in UTC, as
+// presence dates are region-local calendar days stored at midnight UTC.
+func (r *Region) LocalTime(t time.Time) time.Time {
+	local := t.In(r.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// LocalDate returns the calendar day in the time zone at t, at midnight UTC.
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// YearStartAt returns the tax year start in effect at t, and the date it took effect from, which is
+// zero for the region's original year start.
+func (r *Region) YearStartAt(t time.Time) (time.Month, int, time.Time) {
+	month, day, from := r.YearStartMonth, r.YearStartDay, time.Time{}
+	for _, fy := range r.FiscalCalendar {
+		if fy.From.After(t) {
+			break
+		}
+		month, day, from = fy.Month, fy.Day, fy.From
+	}
+	return month, day, from
+}
+
+// NextFiscalChange returns the first change to the tax year start after t.
+func (r *Region) NextFiscalChange(t time.Time) (time.Time, bool) {
+	for _, fy := range r.FiscalCalendar {
+		if fy.From.After(t) {
+			return fy.From, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// RegionIDs returns the region ID followed by the IDs of any member regions.
+func (r *Region) RegionIDs() []RegionID {
+	ids := make([]RegionID, 0, len(r.MemberRegionIDs)+1)
+	ids = append(ids, r.ID)
+	ids = append(ids, r.MemberRegionIDs...)
+	return ids
 }
 
 func (rt RegionType) Valid() bool {
@@ -68,6 +154,10 @@ func (r *Region) Validate() error {
 		if err := r.ParentRegionID.Validate(); err != nil {
 			return err
 		}
+
+		if *r.ParentRegionID == r.ID {
+			return ValidationError("region cannot be its own parent")
+		}
 	}
 
 	if r.Name == "" {
@@ -90,10 +180,64 @@ func (r *Region) Validate() error {
 		return ValidationError("year start day must be between 1-31")
 	}
 
+	if loc, err := time.LoadLocation(r.TimeZone); err != nil || loc == time.Local {
+		return ValidationError("unknown time zone: %s", r.TimeZone)
+	}
+
 	if r.Sources == nil {
 		return ValidationError("sources cannot be empty")
 	}
 
+	for i, fy := range r.FiscalCalendar {
+		if fy.From.IsZero() {
+			return ValidationError("fiscal calendar change must have a from date")
+		}
+
+		if i > 0 && !fy.From.After(r.FiscalCalendar[i-1].From) {
+			return ValidationError("fiscal calendar changes must be in date order")
+		}
+
+		if fy.Month < 1 || fy.Month > 12 {
+			return ValidationError("fiscal calendar month must be between 1-12")
+		}
+
+		if fy.Day < 1 || fy.Day > 31 {
+			return ValidationError("fiscal calendar day must be between 1-31")
+		}
+	}
+
+	for _, polygon := range r.Boundary {
+		if len(polygon) == 0 {
+			return ValidationError("boundary polygon must have an outer ring")
+		}
+
+		for _, ring := range polygon {
+			if len(ring) < 3 {
+				return ValidationError("boundary ring must have at least 3 points")
+			}
+
+			for _, point := range ring {
+				if !ValidLatLng(point) {
+					return ValidationError("boundary point out of range: %v", point)
+				}
+			}
+		}
+	}
+
+	if len(r.MemberRegionIDs) > 0 && r.Type != RegionTypeZone {
+		return ValidationError("only zone regions can have member regions")
+	}
+
+	for _, id := range r.MemberRegionIDs {
+		if err := id.Validate(); err != nil {
+			return err
+		}
+
+		if id == r.ID {
+			return ValidationError("region cannot be a member of itself")
+		}
+	}
+
 	return nil
 }
 
@@ -105,10 +249,14 @@ type RegionService interface {
 
 type RegionFilter struct {
 	RegionIDs []RegionID
+	// MemberRegionIDs matches zones containing any of the given member regions.
+	MemberRegionIDs []RegionID
 }
 
 type RegionRepository interface {
 	GetByID(ctx context.Context, regionID RegionID) (*Region, error)
 	List(ctx context.Context, filter *RegionFilter) ([]*Region, error)
+	ListDescendants(ctx context.Context, regionID RegionID) ([]*Region, error)
+	ListAncestors(ctx context.Context, regionID RegionID) ([]*Region, error)
 	CreateOrUpdate(ctx context.Context, region *Region) error
 }
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -48,6 +49,14 @@ func TestValidateRegion(t *testing.T) {
 			},
 			wantErr: ValidationError("region ID is required"),
 		},
+		{
+			name: "region is its own parent",
+			modify: func(r Region) Region {
+				r.ParentRegionID = &r.ID
+				return r
+			},
+			wantErr: ValidationError("region cannot be its own parent"),
+		},
 		{
 			name: "missing name",
 			modify: func(r Region) Region {
@@ -112,6 +121,125 @@ func TestValidateRegion(t *testing.T) {
 			},
 			wantErr: ValidationError("sources cannot be empty"),
 		},
+		{
+			name: "zone with member regions",
+			modify: func(r Region) Region {
+				r.ID = "EU"
+				r.ParentRegionID = nil
+				r.Type = RegionTypeZone
+				r.MemberRegionIDs = []RegionID{"FR", "DE"}
+				return r
+			},
+		},
+		{
+			name: "member regions on non-zone region",
+			modify: func(r Region) Region {
+				r.MemberRegionIDs = []RegionID{"FR"}
+				return r
+			},
+			wantErr: ValidationError("only zone regions can have member regions"),
+		},
+		{
+			name: "invalid member region ID",
+			modify: func(r Region) Region {
+				r.Type = RegionTypeZone
+				r.MemberRegionIDs = []RegionID{""}
+				return r
+			},
+			wantErr: ValidationError("region ID is required"),
+		},
+		{
+			name: "zone is a member of itself",
+			modify: func(r Region) Region {
+				r.Type = RegionTypeZone
+				r.MemberRegionIDs = []RegionID{r.ID}
+				return r
+			},
+			wantErr: ValidationError("region cannot be a member of itself"),
+		},
+		{
+			name: "valid time zone",
+			modify: func(r Region) Region {
+				r.TimeZone = "Europe/Jersey"
+				return r
+			},
+		},
+		{
+			name: "unknown time zone",
+			modify: func(r Region) Region {
+				r.TimeZone = "Europe/Atlantis"
+				return r
+			},
+			wantErr: ValidationError("unknown time zone: Europe/Atlantis"),
+		},
+		{
+			name: "valid fiscal calendar",
+			modify: func(r Region) Region {
+				r.FiscalCalendar = []FiscalYearStart{
+					{From: time.Date(2010, time.April, 1, 0, 0, 0, 0, time.UTC), Month: time.April, Day: 1},
+					{From: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), Month: time.January, Day: 1},
+				}
+				return r
+			},
+		},
+		{
+			name: "fiscal calendar change without a from date",
+			modify: func(r Region) Region {
+				r.FiscalCalendar = []FiscalYearStart{{Month: time.April, Day: 1}}
+				return r
+			},
+			wantErr: ValidationError("fiscal calendar change must have a from date"),
+		},
+		{
+			name: "fiscal calendar out of order",
+			modify: func(r Region) Region {
+				r.FiscalCalendar = []FiscalYearStart{
+					{From: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), Month: time.January, Day: 1},
+					{From: time.Date(2010, time.April, 1, 0, 0, 0, 0, time.UTC), Month: time.April, Day: 1},
+				}
+				return r
+			},
+			wantErr: ValidationError("fiscal calendar changes must be in date order"),
+		},
+		{
+			name: "fiscal calendar with invalid month",
+			modify: func(r Region) Region {
+				r.FiscalCalendar = []FiscalYearStart{{From: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), Month: 13, Day: 1}}
+				return r
+			},
+			wantErr: ValidationError("fiscal calendar month must be between 1-12"),
+		},
+		{
+			name: "valid boundary",
+			modify: func(r Region) Region {
+				r.Boundary = []Polygon{{{{49.16, -2.26}, {49.27, -2.26}, {49.27, -2.00}, {49.16, -2.00}}}}
+				return r
+			},
+		},
+		{
+			name: "boundary polygon without rings",
+			modify: func(r Region) Region {
+				r.Boundary = []Polygon{{}}
+				return r
+			},
+			wantErr: ValidationError("boundary polygon must have an outer ring"),
+		},
+		{
+			name: "boundary ring with too few points",
+			modify: func(r Region) Region {
+				r.Boundary = []Polygon{{{{49.16, -2.26}, {49.27, -2.26}}}}
+				return r
+			},
+			wantErr: ValidationError("boundary ring must have at least 3 points"),
+		},
+		{
+			name: "boundary point out of range",
+			modify: func(r Region) Region {
+				r.Boundary = []Polygon{{{{49.16, -2.26}, {49.27, -2.26}, {-2.00, 190}}}}
+				return r
+			},
+			wantErr: ValidationError("boundary point out of range: [-2 190]"),
+		},
 	}
 
 	for _, tc := range tests {
@@ -126,3 +254,85 @@ func TestValidateRegion(t *testing.T) {
 		})
 	}
 }
+
+func TestRegionYearStartAt(t *testing.T) {
+	change := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	region := Region{
+		YearStartMonth: time.April,
+		YearStartDay:   1,
+		FiscalCalendar: []FiscalYearStart{{From: change, Month: time.January, Day: 1}},
+	}
+
+	month, day, from := region.YearStartAt(change.AddDate(0, 0, -1))
+	require.Equal(t, time.April, month)
+	require.Equal(t, 1, day)
+	require.True(t, from.IsZero())
+
+	month, day, from = region.YearStartAt(change)
+	require.Equal(t, time.January, month)
+	require.Equal(t, 1, day)
+	require.Equal(t, change, from)
+
+	next, ok := region.NextFiscalChange(change.AddDate(-1, 0, 0))
+	require.True(t, ok)
+	require.Equal(t, change, next)
+
+	_, ok = region.NextFiscalChange(change)
+	require.False(t, ok)
+}
+
+func TestRegionLocalTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeZone string
+		at       time.Time
+		want     time.Time
+	}{
+		{
+			name: "no time zone is UTC",
+			at:   time.Date(2025, time.March, 30, 23, 30, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 30, 23, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "before the clocks go forward",
+			timeZone: "Europe/London",
+			at:       time.Date(2025, time.March, 29, 23, 30, 0, 0, time.UTC),
+			want:     time.Date(2025, time.March, 29, 23, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "after the clocks go forward",
+			timeZone: "Europe/London",
+			at:       time.Date(2025, time.March, 30, 23, 30, 0, 0, time.UTC),
+			want:     time.Date(2025, time.March, 31, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "before the clocks go back",
+			timeZone: "America/New_York",
+			at:       time.Date(2025, time.November, 2, 3, 30, 0, 0, time.UTC),
+			want:     time.Date(2025, time.November, 1, 23, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "after the clocks go back",
+			timeZone: "America/New_York",
+			at:       time.Date(2025, time.November, 3, 4, 30, 0, 0, time.UTC),
+			want:     time.Date(2025, time.November, 2, 23, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "ahead of UTC across a year",
+			timeZone: "Pacific/Auckland",
+			at:       time.Date(2025, time.December, 31, 12, 0, 0, 0, time.UTC),
+			want:     time.Date(2026, time.January, 1, 1, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			region := Region{TimeZone: tc.timeZone}
+			require.Equal(t, tc.want, region.LocalTime(tc.at))
+
+			date := LocalDate(tc.at, region.Location())
+			require.Equal(t, time.Date(tc.want.Year(), tc.want.Month(), tc.want.Day(), 0, 0, 0, 0, time.UTC), date)
+		})
+	}
+}
@@ -3,17 +3,30 @@ package domain
 import (
 	"context"
 	"encoding/json"
+	"strings"
 )
 
 type NodeType string
 
 const (
-	NodeTypeCompositeAnd NodeType = "and"
-	NodeTypeCompositeAny NodeType = "any"
-	NodeTypeStrategy     NodeType = "strategy"
-	NodeTypeCondition    NodeType = "condition"
+	NodeTypeCompositeAnd     NodeType = "and"
+	NodeTypeCompositeAny     NodeType = "any"
+	NodeTypeCompositeNot     NodeType = "not"
+	NodeTypeCompositeAtLeast NodeType = "atLeast"
+	NodeTypeStrategy         NodeType = "strategy"
+	NodeTypeCondition        NodeType = "condition"
+	NodeTypeRule             NodeType = "rule"
 )
 
+func (nt NodeType) Valid() bool {
+	switch nt {
+	case NodeTypeCompositeAnd, NodeTypeCompositeAny, NodeTypeCompositeNot, NodeTypeCompositeAtLeast, NodeTypeStrategy, NodeTypeCondition, NodeTypeRule:
+		return true
+	default:
+		return false
+	}
+}
+
 type RuleNode struct {
 	Type  NodeType        `json:"type"`
 	Props json.RawMessage `json:"props"`
@@ -24,6 +37,10 @@ func (rn *RuleNode) Validate() error {
 		return ValidationError("type is required")
 	}
 
+	if !rn.Type.Valid() {
+		return ValidationError("unknown node type: %s", rn.Type)
+	}
+
 	if rn.Props == nil {
 		return ValidationError("props is required")
 	}
@@ -35,12 +52,86 @@ func (rn *RuleNode) Unmarshal(dst any) error {
 	return json.Unmarshal(rn.Props, dst)
 }
 
+// References returns the IDs of the rules referenced anywhere in the node tree.
+func (rn *RuleNode) References() ([]Code, error) {
+	var ids []Code
+
+	err := rn.Walk(func(node RuleNode) error {
+		if node.Type != NodeTypeRule {
+			return nil
+		}
+
+		var ref RuleRefNode
+		if err := node.Unmarshal(&ref); err != nil {
+			return err
+		}
+		ids = append(ids, ref.RuleID)
+
+		return nil
+	})
+
+	return ids, err
+}
+
+// Walk calls fn for the node and then every node beneath it, depth first. Node types with children
+// only need to be handled by Children to be walked.
+func (rn *RuleNode) Walk(fn func(node RuleNode) error) error {
+	if err := fn(*rn); err != nil {
+		return err
+	}
+
+	children, err := rn.Children()
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Children returns the child nodes of a composite node, or nil for leaf nodes.
+func (rn *RuleNode) Children() ([]RuleNode, error) {
+	switch rn.Type {
+	case NodeTypeCompositeAnd, NodeTypeCompositeAny:
+		var nodes []RuleNode
+		if err := rn.Unmarshal(&nodes); err != nil {
+			return nil, err
+		}
+		return nodes, nil
+
+	case NodeTypeCompositeNot:
+		var nn NotNode
+		if err := rn.Unmarshal(&nn); err != nil {
+			return nil, err
+		}
+		return []RuleNode{nn.Node}, nil
+
+	case NodeTypeCompositeAtLeast:
+		var tn ThresholdNode
+		if err := rn.Unmarshal(&tn); err != nil {
+			return nil, err
+		}
+		return tn.Nodes, nil
+
+	default:
+		return nil, nil
+	}
+}
+
 type Rule struct {
 	ID          Code     `json:"id"`
 	RegionID    RegionID `json:"regionId"`
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
 	Node        RuleNode `json:"node"`
+	// IncludeSubregions counts presences logged in any descendant region (e.g. US-CA for US)
+	// towards the rule.
+	IncludeSubregions bool `json:"includeSubregions"`
 }
 
 func (r *Rule) Validate() error {
@@ -64,9 +155,86 @@ func (r *Rule) Validate() error {
 		return err
 	}
 
+	// Node props are not validated here, so references are only checked when they can be read
+	references, _ := r.Node.References()
+	for _, id := range references {
+		if err := id.Validate(); err != nil {
+			return err
+		}
+
+		if id == r.ID {
+			return ValidationError("rule %s cannot reference itself", r.ID)
+		}
+	}
+
 	return nil
 }
 
+// SortRules orders rules so that every rule comes after the rules it references. It fails when a
+// reference does not resolve to one of the given rules or when rules reference each other in a cycle.
+func SortRules(rules []*Rule) ([]*Rule, error) {
+	byID := make(map[Code]*Rule, len(rules))
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		sorted = make([]*Rule, 0, len(rules))
+		state  = make(map[Code]int, len(rules))
+		visit  func(id Code, path []Code) error
+	)
+
+	visit = func(id Code, path []Code) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := make([]string, 0, len(path)+1)
+			for _, p := range path {
+				cycle = append(cycle, string(p))
+			}
+			cycle = append(cycle, string(id))
+			return ValidationError("rule reference cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		rule, ok := byID[id]
+		if !ok {
+			return ValidationError("referenced rule %s not found", id)
+		}
+
+		state[id] = visiting
+
+		references, err := rule.Node.References()
+		if err != nil {
+			return ValidationError("invalid rule node: %s", err)
+		}
+
+		for _, ref := range references {
+			if err := visit(ref, append(path, id)); err != nil {
+				return err
+			}
+		}
+
+		state[id] = visited
+		sorted = append(sorted, rule)
+
+		return nil
+	}
+
+	for _, r := range rules {
+		if err := visit(r.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
 type Operator string
 
 const (
@@ -99,10 +267,54 @@ func (n *CompositeNode) Validate() error {
 	return nil
 }
 
+// NotNode negates its child node, e.g. "unless" clauses in residency statutes.
+type NotNode struct {
+	Node RuleNode `json:"node"`
+}
+
+func (n *NotNode) Validate() error {
+	return n.Node.Validate()
+}
+
+// ThresholdNode passes when at least K of its child nodes pass, e.g. "at least two of the following ties apply".
+type ThresholdNode struct {
+	K     int        `json:"k"`
+	Nodes []RuleNode `json:"nodes"`
+}
+
+func (n *ThresholdNode) Validate() error {
+	if len(n.Nodes) < 2 {
+		return ValidationError("threshold node requires at least 2 child nodes")
+	}
+
+	if n.K < 1 || n.K > len(n.Nodes) {
+		return ValidationError("threshold node k must be between 1 and %d", len(n.Nodes))
+	}
+
+	for _, cn := range n.Nodes {
+		if err := cn.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RuleRefNode passes when the referenced rule passes, e.g. a province test that depends on the
+// country result. The referenced rule may belong to another region.
+type RuleRefNode struct {
+	RuleID Code `json:"ruleId"`
+}
+
+func (n *RuleRefNode) Validate() error {
+	return n.RuleID.Validate()
+}
+
 type EvaluatorNode struct {
-	Type   string          `json:"type"`
-	Period Period          `json:"period"`
-	Props  json.RawMessage `json:"props,omitempty"`
+	Type     string          `json:"type"`
+	Period   Period          `json:"period"`
+	DayCount DayCountMode    `json:"dayCount,omitempty"`
+	Props    json.RawMessage `json:"props,omitempty"`
 }
 
 func (n *EvaluatorNode) Validate() error {
@@ -114,20 +326,92 @@ func (n *EvaluatorNode) Validate() error {
 		return err
 	}
 
+	if n.DayCount != "" && !n.DayCount.Valid() {
+		return ValidationError("unknown day count mode: %s", n.DayCount)
+	}
+
 	return nil
 }
 
+// DayCountMode decides which presence days count towards a strategy, as jurisdictions differ on
+// whether arrival, departure and transit days are included.
+type DayCountMode string
+
+const (
+	// DayCountAnyPart counts any day the user was present for part of, excluding transit days.
+	DayCountAnyPart DayCountMode = "any"
+	// DayCountMidnight counts only days the user was present at midnight, so departure and transit
+	// days are excluded (e.g. the UK midnight rule).
+	DayCountMidnight DayCountMode = "midnight"
+	// DayCountExcludeTravel excludes arrival, departure and transit days.
+	DayCountExcludeTravel DayCountMode = "excludeTravel"
+	// DayCountTransit counts every day, including transit days.
+	DayCountTransit DayCountMode = "transit"
+)
+
+func (m DayCountMode) Valid() bool {
+	switch m {
+	case DayCountAnyPart, DayCountMidnight, DayCountExcludeTravel, DayCountTransit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Counts reports whether the presence counts as a day under the mode. An empty mode counts any part of a day.
+func (m DayCountMode) Counts(p *Presence) bool {
+	switch m {
+	case DayCountMidnight:
+		return !p.Departure && !p.Transit
+	case DayCountExcludeTravel:
+		return !p.Arrival && !p.Departure && !p.Transit
+	case DayCountTransit:
+		return true
+	default:
+		return !p.Transit
+	}
+}
+
 type Comparator string
 
 const (
-	ComparatorEquals    Comparator = "eq"
-	ComparatorNotEquals Comparator = "neq"
+	ComparatorEquals      Comparator = "eq"
+	ComparatorNotEquals   Comparator = "neq"
+	ComparatorGreater     Comparator = "gt"
+	ComparatorGreaterOrEq Comparator = "gte"
+	ComparatorLess        Comparator = "lt"
+	ComparatorLessOrEq    Comparator = "lte"
+	ComparatorIn          Comparator = "in"
+	ComparatorContains    Comparator = "contains"
+	ComparatorContainsAny Comparator = "containsAny"
+	ComparatorContainsAll Comparator = "containsAll"
 )
 
+func (c Comparator) Valid() bool {
+	switch c {
+	case ComparatorEquals, ComparatorNotEquals,
+		ComparatorGreater, ComparatorGreaterOrEq, ComparatorLess, ComparatorLessOrEq,
+		ComparatorIn, ComparatorContains, ComparatorContainsAny, ComparatorContainsAll:
+		return true
+	default:
+		return false
+	}
+}
+
 type ConditionNode struct {
-	ConditionID Code       `json:"conditionId"`
-	Equals      any        `json:"equals"`
-	Comparator  Comparator `json:"comparator"`
+	ConditionID Code `json:"conditionId"`
+	// Equals is the expected value. It is kept for equality comparators, Value is preferred for all others.
+	Equals     any        `json:"equals,omitempty"`
+	Value      any        `json:"value,omitempty"`
+	Comparator Comparator `json:"comparator"`
+}
+
+// Expected returns the value the answer is compared against.
+func (n *ConditionNode) Expected() any {
+	if n.Value != nil {
+		return n.Value
+	}
+	return n.Equals
 }
 
 func (n *ConditionNode) Validate() error {
@@ -135,9 +419,7 @@ func (n *ConditionNode) Validate() error {
 		return err
 	}
 
-	switch n.Comparator {
-	case ComparatorEquals, ComparatorNotEquals:
-	default:
+	if !n.Comparator.Valid() {
 		return ValidationError("unsupported comparator: %s", n.Comparator)
 	}
 
@@ -149,6 +431,29 @@ type PeriodType string
 const (
 	PeriodTypeYear    PeriodType = "year"
 	PeriodTypeRolling PeriodType = "rolling"
+	// PeriodTypeMonth is a calendar month.
+	PeriodTypeMonth PeriodType = "month"
+	// PeriodTypeQuarter is a calendar quarter starting in January, April, July or October.
+	PeriodTypeQuarter PeriodType = "quarter"
+	// PeriodTypeFullMonths is the most recent whole calendar months, excluding the current month.
+	PeriodTypeFullMonths PeriodType = "full_months"
+	// PeriodTypeSplitYear is a single tax year that starts or ends at a boundary derived from the
+	// user's presences or answers, for jurisdictions with split-year treatment.
+	PeriodTypeSplitYear PeriodType = "split_year"
+)
+
+// PeriodBoundary decides where a split-year period starts or ends within the tax year.
+type PeriodBoundary string
+
+const (
+	// PeriodBoundaryYear is the start or end of the tax year itself.
+	PeriodBoundaryYear PeriodBoundary = "year"
+	// PeriodBoundaryArrival is the first day counted in the region within the tax year.
+	PeriodBoundaryArrival PeriodBoundary = "arrival"
+	// PeriodBoundaryDeparture is the last day counted in the region within the tax year.
+	PeriodBoundaryDeparture PeriodBoundary = "departure"
+	// PeriodBoundaryCondition is the date given in the answer to a date condition.
+	PeriodBoundaryCondition PeriodBoundary = "condition"
 )
 
 type Period struct {
@@ -158,6 +463,16 @@ type Period struct {
 	RollingDays   int        `json:"rollingDays"`
 	RollingMonths int        `json:"rollingMonths"`
 	RollingYears  int        `json:"rollingYears"`
+	// Offset is the number of months or quarters before the one containing the point in time.
+	Offset int `json:"offset,omitempty"`
+	// Months is the number of whole months a full months period covers.
+	Months int `json:"months,omitempty"`
+	// SplitStart and SplitEnd are the boundaries of a split-year period, defaulting to the tax year.
+	SplitStart PeriodBoundary `json:"splitStart,omitempty"`
+	SplitEnd   PeriodBoundary `json:"splitEnd,omitempty"`
+	// StartConditionID and EndConditionID are the date conditions of condition boundaries.
+	StartConditionID Code `json:"startConditionId,omitempty"`
+	EndConditionID   Code `json:"endConditionId,omitempty"`
 }
 
 func (p *Period) Validate() error {
@@ -170,6 +485,42 @@ func (p *Period) Validate() error {
 		if p.RollingDays <= 0 && p.RollingMonths <= 0 && p.RollingYears <= 0 {
 			return ValidationError("rolling period must have one days/months/years greater than 0")
 		}
+	case PeriodTypeMonth, PeriodTypeQuarter:
+		if p.Offset < 0 {
+			return ValidationError("%s period offset cannot be negative", p.Type)
+		}
+	case PeriodTypeFullMonths:
+		if p.Months <= 0 {
+			return ValidationError("full months period must have months greater than 0")
+		}
+	case PeriodTypeSplitYear:
+		if p.Years > 1 {
+			return ValidationError("split-year period must cover a single year")
+		}
+
+		switch p.SplitStart {
+		case "", PeriodBoundaryYear, PeriodBoundaryArrival:
+		case PeriodBoundaryCondition:
+			if err := p.StartConditionID.Validate(); err != nil {
+				return err
+			}
+		default:
+			return ValidationError("unsupported split-year start: %s", p.SplitStart)
+		}
+
+		switch p.SplitEnd {
+		case "", PeriodBoundaryYear, PeriodBoundaryDeparture:
+		case PeriodBoundaryCondition:
+			if err := p.EndConditionID.Validate(); err != nil {
+				return err
+			}
+		default:
+			return ValidationError("unsupported split-year end: %s", p.SplitEnd)
+		}
+
+		if p.StartBoundary() == PeriodBoundaryYear && p.EndBoundary() == PeriodBoundaryYear {
+			return ValidationError("split-year period must split the start or end of the year")
+		}
 	default:
 		return ValidationError("unknown period type: %s", p.Type)
 	}
@@ -177,6 +528,22 @@ func (p *Period) Validate() error {
 	return nil
 }
 
+// StartBoundary returns the start boundary of a split-year period.
+func (p *Period) StartBoundary() PeriodBoundary {
+	if p.SplitStart == "" {
+		return PeriodBoundaryYear
+	}
+	return p.SplitStart
+}
+
+// EndBoundary returns the end boundary of a split-year period.
+func (p *Period) EndBoundary() PeriodBoundary {
+	if p.SplitEnd == "" {
+		return PeriodBoundaryYear
+	}
+	return p.SplitEnd
+}
+
 type RuleService interface {
 	GetByID(ctx context.Context, ruleID Code) (*Rule, error)
 	List(ctx context.Context, filter *RuleFilter) ([]*Rule, error)
@@ -191,5 +558,8 @@ type RuleRepository interface {
 	GetByID(ctx context.Context, ruleID Code) (*Rule, error)
 	List(ctx context.Context, filter *RuleFilter) ([]*Rule, error)
 	ListByRegionID(ctx context.Context, regionID RegionID) ([]*Rule, error)
+	// ListDependentRegionIDs returns the regions with rules that reference, directly or transitively,
+	// a rule in the given region.
+	ListDependentRegionIDs(ctx context.Context, regionID RegionID) ([]RegionID, error)
 	CreateOrUpdate(ctx context.Context, rule *Rule) error
 }
@@ -31,6 +31,14 @@ func TestValidateRuleNode(t *testing.T) {
 			},
 			wantErr: ValidationError("type is required"),
 		},
+		{
+			name: "unknown type",
+			modify: func(rn RuleNode) RuleNode {
+				rn.Type = "unknown"
+				return rn
+			},
+			wantErr: ValidationError("unknown node type: unknown"),
+		},
 		{
 			name: "missing props",
 			modify: func(rn RuleNode) RuleNode {
@@ -115,6 +123,29 @@ func TestValidateRule(t *testing.T) {
 			},
 			wantErr: ValidationError("type is required"),
 		},
+		{
+			name: "references another rule",
+			modify: func(r Rule) Rule {
+				r.Node = RuleNode{Type: NodeTypeRule, Props: json.RawMessage(`{"ruleId":"GB_OTHER"}`)}
+				return r
+			},
+		},
+		{
+			name: "references itself",
+			modify: func(r Rule) Rule {
+				r.Node = RuleNode{Type: NodeTypeRule, Props: json.RawMessage(`{"ruleId":"VALID_ID"}`)}
+				return r
+			},
+			wantErr: ValidationError("rule VALID_ID cannot reference itself"),
+		},
+		{
+			name: "references itself in a nested node",
+			modify: func(r Rule) Rule {
+				r.Node = RuleNode{Type: NodeTypeCompositeNot, Props: json.RawMessage(`{"node":{"type":"rule","props":{"ruleId":"VALID_ID"}}}`)}
+				return r
+			},
+			wantErr: ValidationError("rule VALID_ID cannot reference itself"),
+		},
 	}
 
 	for _, tc := range tests {
@@ -130,6 +161,66 @@ func TestValidateRule(t *testing.T) {
 	}
 }
 
+func TestSortRules(t *testing.T) {
+	rule := func(id Code, node string) *Rule {
+		return &Rule{ID: id, RegionID: "GB", Node: RuleNode{Type: NodeTypeRule, Props: json.RawMessage(node)}}
+	}
+
+	leaf := func(id Code) *Rule {
+		return &Rule{ID: id, RegionID: "GB", Node: RuleNode{Type: NodeTypeCondition, Props: json.RawMessage(`{}`)}}
+	}
+
+	tests := []struct {
+		name    string
+		rules   []*Rule
+		want    []Code
+		wantErr error
+	}{
+		{
+			name:  "referenced rules come first",
+			rules: []*Rule{rule("GB_A", `{"ruleId":"GB_B"}`), rule("GB_B", `{"ruleId":"GB_C"}`), leaf("GB_C")},
+			want:  []Code{"GB_C", "GB_B", "GB_A"},
+		},
+		{
+			name: "shared references",
+			rules: []*Rule{
+				{ID: "GB_A", RegionID: "GB", Node: RuleNode{Type: NodeTypeCompositeAnd, Props: json.RawMessage(`[{"type":"rule","props":{"ruleId":"GB_C"}},{"type":"rule","props":{"ruleId":"GB_B"}}]`)}},
+				rule("GB_B", `{"ruleId":"GB_C"}`),
+				leaf("GB_C"),
+			},
+			want: []Code{"GB_C", "GB_B", "GB_A"},
+		},
+		{
+			name:    "unknown reference",
+			rules:   []*Rule{rule("GB_A", `{"ruleId":"GB_B"}`)},
+			wantErr: ValidationError("referenced rule GB_B not found"),
+		},
+		{
+			name:    "cycle",
+			rules:   []*Rule{rule("GB_A", `{"ruleId":"GB_B"}`), rule("GB_B", `{"ruleId":"GB_C"}`), rule("GB_C", `{"ruleId":"GB_A"}`)},
+			wantErr: ValidationError("rule reference cycle: GB_A -> GB_B -> GB_C -> GB_A"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted, err := SortRules(tc.rules)
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+				return
+			}
+
+			require.NoError(t, err)
+
+			ids := make([]Code, len(sorted))
+			for i, r := range sorted {
+				ids[i] = r.ID
+			}
+			require.Equal(t, tc.want, ids)
+		})
+	}
+}
+
 func TestValidateCompositeNode(t *testing.T) {
 	validNode := RuleNode{
 		Type:  NodeTypeCondition,
@@ -190,6 +281,84 @@ func TestValidateCompositeNode(t *testing.T) {
 	}
 }
 
+func TestValidateNotNode(t *testing.T) {
+	validNode := RuleNode{
+		Type:  NodeTypeCondition,
+		Props: json.RawMessage(`{"conditionId":"valid"}`),
+	}
+
+	require.NoError(t, (&NotNode{Node: validNode}).Validate())
+	require.EqualError(t, (&NotNode{}).Validate(), ValidationError("type is required").Error())
+}
+
+func TestValidateThresholdNode(t *testing.T) {
+	validNode := RuleNode{
+		Type:  NodeTypeCondition,
+		Props: json.RawMessage(`{"conditionId":"valid"}`),
+	}
+
+	baseThreshold := ThresholdNode{
+		K:     2,
+		Nodes: []RuleNode{validNode, validNode, validNode},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(tn ThresholdNode) ThresholdNode
+		wantErr error
+	}{
+		{
+			name:   "valid threshold node",
+			modify: func(tn ThresholdNode) ThresholdNode { return tn },
+		},
+		{
+			name: "less than two child nodes",
+			modify: func(tn ThresholdNode) ThresholdNode {
+				tn.K = 1
+				tn.Nodes = []RuleNode{validNode}
+				return tn
+			},
+			wantErr: ValidationError("threshold node requires at least 2 child nodes"),
+		},
+		{
+			name: "k is zero",
+			modify: func(tn ThresholdNode) ThresholdNode {
+				tn.K = 0
+				return tn
+			},
+			wantErr: ValidationError("threshold node k must be between 1 and 3"),
+		},
+		{
+			name: "k greater than child count",
+			modify: func(tn ThresholdNode) ThresholdNode {
+				tn.K = 4
+				return tn
+			},
+			wantErr: ValidationError("threshold node k must be between 1 and 3"),
+		},
+		{
+			name: "invalid child node",
+			modify: func(tn ThresholdNode) ThresholdNode {
+				tn.Nodes = []RuleNode{validNode, validNode, {}}
+				return tn
+			},
+			wantErr: ValidationError("type is required"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tn := tc.modify(baseThreshold)
+			err := tn.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateEvaluatorNode(t *testing.T) {
 	validPeriod := Period{
 		Type:  PeriodTypeYear,
@@ -227,6 +396,21 @@ func TestValidateEvaluatorNode(t *testing.T) {
 			},
 			wantErr: ValidationError("unknown period type: invalid"),
 		},
+		{
+			name: "valid day count mode",
+			modify: func(en EvaluatorNode) EvaluatorNode {
+				en.DayCount = DayCountMidnight
+				return en
+			},
+		},
+		{
+			name: "invalid day count mode",
+			modify: func(en EvaluatorNode) EvaluatorNode {
+				en.DayCount = "invalid"
+				return en
+			},
+			wantErr: ValidationError("unknown day count mode: invalid"),
+		},
 	}
 
 	for _, tc := range tests {
@@ -242,6 +426,36 @@ func TestValidateEvaluatorNode(t *testing.T) {
 	}
 }
 
+func TestDayCountModeCounts(t *testing.T) {
+	full := &Presence{}
+	arrival := &Presence{Arrival: true}
+	departure := &Presence{Departure: true}
+	transit := &Presence{Transit: true}
+
+	tests := []struct {
+		mode DayCountMode
+		want [4]bool // full, arrival, departure, transit
+	}{
+		{mode: "", want: [4]bool{true, true, true, false}},
+		{mode: DayCountAnyPart, want: [4]bool{true, true, true, false}},
+		{mode: DayCountMidnight, want: [4]bool{true, true, false, false}},
+		{mode: DayCountExcludeTravel, want: [4]bool{true, false, false, false}},
+		{mode: DayCountTransit, want: [4]bool{true, true, true, true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			got := [4]bool{
+				tc.mode.Counts(full),
+				tc.mode.Counts(arrival),
+				tc.mode.Counts(departure),
+				tc.mode.Counts(transit),
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestValidateConditionNode(t *testing.T) {
 	baseCond := ConditionNode{
 		ConditionID: Code("VALID_CODE"),
@@ -338,6 +552,72 @@ func TestValidatePeriod(t *testing.T) {
 			},
 			wantErr: ValidationError("rolling period must have one days/months/years greater than 0"),
 		},
+		{
+			name:   "valid month period",
+			period: Period{Type: PeriodTypeMonth, Offset: 1},
+		},
+		{
+			name:    "quarter period with negative offset",
+			period:  Period{Type: PeriodTypeQuarter, Offset: -1},
+			wantErr: ValidationError("quarter period offset cannot be negative"),
+		},
+		{
+			name:   "valid full months period",
+			period: Period{Type: PeriodTypeFullMonths, Months: 12},
+		},
+		{
+			name:    "full months period with zero months",
+			period:  Period{Type: PeriodTypeFullMonths},
+			wantErr: ValidationError("full months period must have months greater than 0"),
+		},
+		{
+			name: "valid split-year period starting on arrival",
+			period: Period{
+				Type:       PeriodTypeSplitYear,
+				SplitStart: PeriodBoundaryArrival,
+			},
+		},
+		{
+			name: "valid split-year period ending on a condition date",
+			period: Period{
+				Type:           PeriodTypeSplitYear,
+				SplitEnd:       PeriodBoundaryCondition,
+				EndConditionID: "GB_DEPARTURE_DATE",
+			},
+		},
+		{
+			name: "split-year period without a split",
+			period: Period{
+				Type:       PeriodTypeSplitYear,
+				SplitStart: PeriodBoundaryYear,
+			},
+			wantErr: ValidationError("split-year period must split the start or end of the year"),
+		},
+		{
+			name: "split-year period over several years",
+			period: Period{
+				Type:       PeriodTypeSplitYear,
+				Years:      2,
+				SplitStart: PeriodBoundaryArrival,
+			},
+			wantErr: ValidationError("split-year period must cover a single year"),
+		},
+		{
+			name: "split-year period starting on departure",
+			period: Period{
+				Type:       PeriodTypeSplitYear,
+				SplitStart: PeriodBoundaryDeparture,
+			},
+			wantErr: ValidationError("unsupported split-year start: departure"),
+		},
+		{
+			name: "split-year condition boundary without a condition",
+			period: Period{
+				Type:       PeriodTypeSplitYear,
+				SplitStart: PeriodBoundaryCondition,
+			},
+			wantErr: ValidationError("code is required"),
+		},
 		{
 			name: "unknown period type",
 			period: Period{
@@ -358,3 +638,22 @@ func TestValidatePeriod(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleNodeWalk(t *testing.T) {
+	var node RuleNode
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"and","props":[`+
+		`{"type":"condition","props":{"conditionId":"JE_MAINTAIN_ABODE","equals":false,"comparator":"eq"}},`+
+		`{"type":"not","props":{"node":{"type":"rule","props":{"ruleId":"JE_OTHER"}}}},`+
+		`{"type":"strategy","props":{"type":"average","period":{"type":"year","years":4},"props":{"threshold":90}}}]}`), &node))
+
+	var types []NodeType
+	require.NoError(t, node.Walk(func(n RuleNode) error {
+		types = append(types, n.Type)
+		return nil
+	}))
+	require.Equal(t, []NodeType{NodeTypeCompositeAnd, NodeTypeCondition, NodeTypeCompositeNot, NodeTypeRule, NodeTypeStrategy}, types)
+
+	references, err := node.References()
+	require.NoError(t, err)
+	require.Equal(t, []Code{"JE_OTHER"}, references)
+}
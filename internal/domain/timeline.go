@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+)
+
+// MaxTimelineDays caps the range of a timeline, as every day in it may be evaluated.
+const MaxTimelineDays = 3660
+
+type TimelineStep string
+
+const (
+	// TimelineStepDay evaluates the region on every day of the range.
+	TimelineStepDay TimelineStep = "day"
+	// TimelineStepPeriod evaluates the region on the last day of each region year in the range, and
+	// on the last day of the range.
+	TimelineStepPeriod TimelineStep = "period"
+)
+
+func (s TimelineStep) Valid() bool {
+	switch s {
+	case TimelineStepDay, TimelineStepPeriod:
+		return true
+	default:
+		return false
+	}
+}
+
+type TimelineOpts struct {
+	From  time.Time
+	Until time.Time
+	// Step defaults to evaluating every day.
+	Step TimelineStep
+}
+
+func (o *TimelineOpts) Validate() error {
+	if o.From.IsZero() || o.Until.IsZero() {
+		return ValidationError("timeline from and until dates are required")
+	}
+
+	if o.Until.Before(o.From) {
+		return ValidationError("timeline until date cannot be before from date")
+	}
+
+	if days := int(o.Until.Sub(o.From).Hours() / 24); days > MaxTimelineDays {
+		return ValidationError("timeline cannot cover more than %d days", MaxTimelineDays)
+	}
+
+	if !o.Step.Valid() {
+		return ValidationError("unknown timeline step: %s", o.Step)
+	}
+
+	return nil
+}
+
+// Timeline is the residency status of a region across a range of days, as intervals of equal outcome.
+type Timeline struct {
+	RegionID  RegionID            `json:"regionId"`
+	From      time.Time           `json:"from"`
+	Until     time.Time           `json:"until"`
+	Step      TimelineStep        `json:"step"`
+	Intervals []*TimelineInterval `json:"intervals"`
+}
+
+type TimelineInterval struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Outcome Outcome   `json:"outcome"`
+	// Triggers are the rules whose outcome changed at the start of the interval, causing the
+	// transition from the previous interval.
+	Triggers []*RuleDiff `json:"triggers,omitempty"`
+}
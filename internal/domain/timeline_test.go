@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTimelineOpts(t *testing.T) {
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	base := TimelineOpts{
+		From:  from,
+		Until: from.AddDate(1, 0, 0),
+		Step:  TimelineStepDay,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o TimelineOpts) TimelineOpts
+		wantErr error
+	}{
+		{
+			name:   "valid opts",
+			modify: func(o TimelineOpts) TimelineOpts { return o },
+		},
+		{
+			name: "period step",
+			modify: func(o TimelineOpts) TimelineOpts {
+				o.Step = TimelineStepPeriod
+				return o
+			},
+		},
+		{
+			name: "missing from",
+			modify: func(o TimelineOpts) TimelineOpts {
+				o.From = time.Time{}
+				return o
+			},
+			wantErr: ValidationError("timeline from and until dates are required"),
+		},
+		{
+			name: "until before from",
+			modify: func(o TimelineOpts) TimelineOpts {
+				o.Until = from.AddDate(0, 0, -1)
+				return o
+			},
+			wantErr: ValidationError("timeline until date cannot be before from date"),
+		},
+		{
+			name: "too many days",
+			modify: func(o TimelineOpts) TimelineOpts {
+				o.Until = from.AddDate(0, 0, MaxTimelineDays+1)
+				return o
+			},
+			wantErr: ValidationError("timeline cannot cover more than 3660 days"),
+		},
+		{
+			name: "unknown step",
+			modify: func(o TimelineOpts) TimelineOpts {
+				o.Step = "week"
+				return o
+			},
+			wantErr: ValidationError("unknown timeline step: week"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := tc.modify(base)
+			err := o.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MaxTieBreakerDays caps the window a presence tie-breaker compares days present over.
+const MaxTieBreakerDays = 3660
+
+type TieBreakerType string
+
+const (
+	// TieBreakerTypeCondition favours the region whose condition is answered true, such as
+	// "Do you have a permanent home available in Jersey?".
+	TieBreakerTypeCondition TieBreakerType = "condition"
+	// TieBreakerTypePresence favours the region with more days present in the window, as with the
+	// habitual abode test.
+	TieBreakerTypePresence TieBreakerType = "presence"
+)
+
+func (t TieBreakerType) Valid() bool {
+	switch t {
+	case TieBreakerTypeCondition, TieBreakerTypePresence:
+		return true
+	default:
+		return false
+	}
+}
+
+// Treaty links two regions that resolve dual residency with an ordered list of tie-breaker tests,
+// such as the permanent home, centre of vital interests, habitual abode and nationality tests of a
+// double taxation agreement.
+type Treaty struct {
+	ID        Code         `json:"id"`
+	Name      string       `json:"name"`
+	RegionIDs [2]RegionID  `json:"regionIds"`
+	Tests     []TieBreaker `json:"tests"`
+	Sources   []Source     `json:"sources"`
+}
+
+// TieBreaker is a single treaty test. Tests are applied in order, and the first test that favours
+// exactly one region decides the treaty residence.
+type TieBreaker struct {
+	Name string         `json:"name"`
+	Type TieBreakerType `json:"type"`
+	// Conditions are the boolean conditions asked for each region of a condition test.
+	Conditions map[RegionID]Code `json:"conditions,omitempty"`
+	// Days is the number of days, ending at the point in time, a presence test compares.
+	Days int `json:"days,omitempty"`
+}
+
+func (t *Treaty) Validate() error {
+	if err := t.ID.Validate(); err != nil {
+		return err
+	}
+
+	if t.Name == "" {
+		return ValidationError("treaty name is required")
+	}
+
+	for _, regionID := range t.RegionIDs {
+		if err := regionID.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if t.RegionIDs[0] == t.RegionIDs[1] {
+		return ValidationError("treaty must link two different regions")
+	}
+
+	if len(t.Tests) == 0 {
+		return ValidationError("treaty must have at least one tie-breaker test")
+	}
+
+	for _, test := range t.Tests {
+		if err := test.validate(t.RegionIDs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TieBreaker) validate(regionIDs [2]RegionID) error {
+	if t.Name == "" {
+		return ValidationError("tie-breaker name is required")
+	}
+
+	switch t.Type {
+	case TieBreakerTypeCondition:
+		if len(t.Conditions) != len(regionIDs) {
+			return ValidationError("tie-breaker %s must have a condition for each treaty region", t.Name)
+		}
+
+		for _, regionID := range regionIDs {
+			conditionID, ok := t.Conditions[regionID]
+			if !ok {
+				return ValidationError("tie-breaker %s must have a condition for region %s", t.Name, regionID)
+			}
+
+			if err := conditionID.Validate(); err != nil {
+				return err
+			}
+		}
+	case TieBreakerTypePresence:
+		if t.Days <= 0 || t.Days > MaxTieBreakerDays {
+			return ValidationError("tie-breaker %s days must be between 1 and %d", t.Name, MaxTieBreakerDays)
+		}
+	default:
+		return ValidationError("unknown tie-breaker type: %s", t.Type)
+	}
+
+	return nil
+}
+
+// ConditionIDs returns the conditions asked by the treaty's tie-breakers.
+func (t *Treaty) ConditionIDs() []Code {
+	var ids []Code
+	for _, test := range t.Tests {
+		for _, regionID := range t.RegionIDs {
+			if id, ok := test.Conditions[regionID]; ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// MaxDays returns the largest window of the treaty's presence tie-breakers.
+func (t *Treaty) MaxDays() int {
+	var days int
+	for _, test := range t.Tests {
+		days = max(days, test.Days)
+	}
+	return days
+}
+
+type TreatyStatus string
+
+const (
+	// TreatyStatusResolved means the user is resident in both regions and a tie-breaker decided the
+	// treaty residence.
+	TreatyStatusResolved TreatyStatus = "resolved"
+	// TreatyStatusSingle means the user is resident in only one of the regions, so no tie-breaker
+	// is needed.
+	TreatyStatusSingle TreatyStatus = "single"
+	// TreatyStatusNone means the user is resident in neither region.
+	TreatyStatusNone TreatyStatus = "none"
+	// TreatyStatusIndeterminate means the outcome depends on unanswered conditions.
+	TreatyStatusIndeterminate TreatyStatus = "indeterminate"
+	// TreatyStatusUnresolved means every tie-breaker was applied without deciding, leaving the
+	// residence to mutual agreement between the regions.
+	TreatyStatusUnresolved TreatyStatus = "unresolved"
+	// TreatyStatusError means the residence in a region, or a tie-breaker, could not be evaluated
+	// whatever is answered.
+	TreatyStatusError TreatyStatus = "error"
+)
+
+// TreatyContext is everything needed to resolve a treaty residence at a point in time.
+type TreatyContext struct {
+	At      time.Time
+	Treaty  *Treaty
+	Regions map[RegionID]*Region
+	// Evaluations are the evaluations of both treaty regions at the point in time.
+	Evaluations map[RegionID]*RegionEvaluation
+	Answers     map[Code]*Answer
+	Presences   []*Presence
+}
+
+type TreatyEvaluation struct {
+	TreatyID Code         `json:"treatyId"`
+	Status   TreatyStatus `json:"status"`
+	// Residence is the single region the user is treaty resident in, if any.
+	Residence *RegionID `json:"residence,omitempty"`
+	// DecidedBy is the name of the tie-breaker that decided the residence.
+	DecidedBy string `json:"decidedBy,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Unresolved lists the unanswered conditions that could change an indeterminate outcome.
+	Unresolved  []Code               `json:"unresolved,omitempty"`
+	Regions     map[RegionID]Outcome `json:"regions"`
+	Tests       []*TieBreakerResult  `json:"tests"`
+	PointInTime time.Time            `json:"pointInTime"`
+}
+
+type TieBreakerResult struct {
+	Name string         `json:"name"`
+	Type TieBreakerType `json:"type"`
+	// Favours is the region the test favours, if it favours exactly one.
+	Favours *RegionID        `json:"favours,omitempty"`
+	Status  EvaluationStatus `json:"status"`
+	Reason  string           `json:"reason"`
+}
+
+type TreatyFilter struct {
+	RegionIDs []RegionID
+}
+
+type TreatyService interface {
+	GetByID(ctx context.Context, treatyID Code) (*Treaty, error)
+	List(ctx context.Context, filter *TreatyFilter) ([]*Treaty, error)
+	CreateOrUpdate(ctx context.Context, treaty *Treaty) error
+	Evaluate(ctx context.Context, userID int64, treatyID Code, opts *EvaluateOpts) (*TreatyEvaluation, error)
+}
+
+type TreatyRepository interface {
+	GetByID(ctx context.Context, treatyID Code) (*Treaty, error)
+	List(ctx context.Context, filter *TreatyFilter) ([]*Treaty, error)
+	CreateOrUpdate(ctx context.Context, treaty *Treaty) error
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTreaty(t *testing.T) {
+	base := Treaty{
+		ID:        "JE_GG",
+		Name:      "Jersey and Guernsey arrangement",
+		RegionIDs: [2]RegionID{"JE", "GG"},
+		Tests: []TieBreaker{
+			{
+				Name:       "Permanent home",
+				Type:       TieBreakerTypeCondition,
+				Conditions: map[RegionID]Code{"JE": "JE_PERMANENT_HOME", "GG": "GG_PERMANENT_HOME"},
+			},
+			{Name: "Habitual abode", Type: TieBreakerTypePresence, Days: 730},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(t Treaty) Treaty
+		wantErr error
+	}{
+		{
+			name:   "valid treaty",
+			modify: func(t Treaty) Treaty { return t },
+		},
+		{
+			name: "missing name",
+			modify: func(t Treaty) Treaty {
+				t.Name = ""
+				return t
+			},
+			wantErr: ValidationError("treaty name is required"),
+		},
+		{
+			name: "same region twice",
+			modify: func(t Treaty) Treaty {
+				t.RegionIDs = [2]RegionID{"JE", "JE"}
+				return t
+			},
+			wantErr: ValidationError("treaty must link two different regions"),
+		},
+		{
+			name: "no tests",
+			modify: func(t Treaty) Treaty {
+				t.Tests = nil
+				return t
+			},
+			wantErr: ValidationError("treaty must have at least one tie-breaker test"),
+		},
+		{
+			name: "condition for one region only",
+			modify: func(t Treaty) Treaty {
+				t.Tests = []TieBreaker{{Name: "Permanent home", Type: TieBreakerTypeCondition, Conditions: map[RegionID]Code{"JE": "JE_PERMANENT_HOME"}}}
+				return t
+			},
+			wantErr: ValidationError("tie-breaker Permanent home must have a condition for each treaty region"),
+		},
+		{
+			name: "condition for another region",
+			modify: func(t Treaty) Treaty {
+				t.Tests = []TieBreaker{{Name: "Permanent home", Type: TieBreakerTypeCondition, Conditions: map[RegionID]Code{"JE": "JE_PERMANENT_HOME", "GB": "GB_PERMANENT_HOME"}}}
+				return t
+			},
+			wantErr: ValidationError("tie-breaker Permanent home must have a condition for region GG"),
+		},
+		{
+			name: "presence without days",
+			modify: func(t Treaty) Treaty {
+				t.Tests = []TieBreaker{{Name: "Habitual abode", Type: TieBreakerTypePresence}}
+				return t
+			},
+			wantErr: ValidationError("tie-breaker Habitual abode days must be between 1 and 3660"),
+		},
+		{
+			name: "unknown type",
+			modify: func(t Treaty) Treaty {
+				t.Tests = []TieBreaker{{Name: "Nationality", Type: "citizenship"}}
+				return t
+			},
+			wantErr: ValidationError("unknown tie-breaker type: citizenship"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			treaty := tc.modify(base)
+			err := treaty.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -60,4 +60,7 @@ type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, userID int64) error
+	// SetCalendarTokenHash stores the hash of the user's calendar feed token, replacing any earlier one.
+	SetCalendarTokenHash(ctx context.Context, userID int64, hash string) error
+	GetByCalendarTokenHash(ctx context.Context, hash string) (*User, error)
 }
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"time"
+)
+
+// MaxWhatIfTrips caps the number of trips in a single what-if request.
+const MaxWhatIfTrips = 50
+
+type WhatIfOpts struct {
+	// PointInTime is the time at which to evaluate the regions. It defaults to the last day of the
+	// latest trip, so every trip is counted.
+	PointInTime time.Time
+	// Trips are the hypothetical presences evaluated alongside the user's logged presences.
+	Trips []PlannedPresence
+}
+
+func (o *WhatIfOpts) Validate() error {
+	if len(o.Trips) == 0 {
+		return ValidationError("at least one trip is required")
+	}
+
+	if len(o.Trips) > MaxWhatIfTrips {
+		return ValidationError("cannot plan more than %d trips", MaxWhatIfTrips)
+	}
+
+	for _, trip := range o.Trips {
+		if err := trip.Validate(); err != nil {
+			return err
+		}
+
+		if days := int(trip.End.Sub(trip.Start).Hours() / 24); days > MaxForecastDays {
+			return ValidationError("trip cannot cover more than %d days", MaxForecastDays)
+		}
+	}
+
+	return nil
+}
+
+// WhatIf compares the evaluations of every region affected by a set of hypothetical trips with and
+// without them.
+type WhatIf struct {
+	PointInTime time.Time     `json:"pointInTime"`
+	Regions     []*RegionDiff `json:"regions"`
+}
+
+type RegionDiff struct {
+	RegionID RegionID `json:"regionId"`
+	Before   Outcome  `json:"before"`
+	After    Outcome  `json:"after"`
+	Changed  bool     `json:"changed"`
+	// Rules lists only the rules whose outcome would change.
+	Rules []*RuleDiff `json:"rules"`
+}
+
+type RuleDiff struct {
+	RuleID Code    `json:"ruleId"`
+	Before Outcome `json:"before"`
+	After  Outcome `json:"after"`
+}
+
+// NewRegionDiff compares two evaluations of the same region and rules.
+func NewRegionDiff(before, after *RegionEvaluation, rules []*Rule) *RegionDiff {
+	diff := &RegionDiff{
+		RegionID: before.RegionID,
+		Before:   before.Outcome(),
+		After:    after.Outcome(),
+		Rules:    make([]*RuleDiff, 0),
+	}
+	diff.Changed = diff.Before != diff.After
+
+	for i, rule := range rules {
+		b, a := OutcomeOf(before.Nodes[i]), OutcomeOf(after.Nodes[i])
+		if b != a {
+			diff.Rules = append(diff.Rules, &RuleDiff{RuleID: rule.ID, Before: b, After: a})
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWhatIfOpts(t *testing.T) {
+	start := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	base := WhatIfOpts{
+		Trips: []PlannedPresence{
+			{RegionID: "JE", Start: start, End: start.AddDate(0, 0, 10)},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o WhatIfOpts) WhatIfOpts
+		wantErr error
+	}{
+		{
+			name:   "valid opts",
+			modify: func(o WhatIfOpts) WhatIfOpts { return o },
+		},
+		{
+			name: "no trips",
+			modify: func(o WhatIfOpts) WhatIfOpts {
+				o.Trips = nil
+				return o
+			},
+			wantErr: ValidationError("at least one trip is required"),
+		},
+		{
+			name: "too many trips",
+			modify: func(o WhatIfOpts) WhatIfOpts {
+				o.Trips = make([]PlannedPresence, MaxWhatIfTrips+1)
+				return o
+			},
+			wantErr: ValidationError("cannot plan more than 50 trips"),
+		},
+		{
+			name: "invalid trip",
+			modify: func(o WhatIfOpts) WhatIfOpts {
+				o.Trips = []PlannedPresence{{RegionID: "JE", Start: start, End: start.AddDate(0, 0, -1)}}
+				return o
+			},
+			wantErr: ValidationError("planned presence end cannot be before start"),
+		},
+		{
+			name: "trip too long",
+			modify: func(o WhatIfOpts) WhatIfOpts {
+				o.Trips = []PlannedPresence{{RegionID: "JE", Start: start, End: start.AddDate(3, 0, 0)}}
+				return o
+			},
+			wantErr: ValidationError("trip cannot cover more than 730 days"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := tc.modify(base)
+			err := o.Validate()
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewRegionDiff(t *testing.T) {
+	rules := []*Rule{{ID: "JE_A"}, {ID: "JE_B"}, {ID: "JE_C"}}
+
+	before := &RegionEvaluation{
+		RegionID: "JE",
+		Status:   EvaluationStatusEvaluated,
+		Nodes: []EvaluationComponent{
+			&StrategyEvaluation{Passed: true},
+			&StrategyEvaluation{Passed: false},
+			&ConditionEvaluation{Status: EvaluationStatusUnanswered},
+		},
+	}
+
+	after := &RegionEvaluation{
+		RegionID: "JE",
+		Status:   EvaluationStatusIndeterminate,
+		Nodes: []EvaluationComponent{
+			&StrategyEvaluation{Passed: true},
+			&StrategyEvaluation{Passed: true},
+			&ConditionEvaluation{Status: EvaluationStatusUnanswered},
+		},
+	}
+
+	diff := NewRegionDiff(before, after, rules)
+
+	require.Equal(t, &RegionDiff{
+		RegionID: "JE",
+		Before:   OutcomeFailed,
+		After:    OutcomeIndeterminate,
+		Changed:  true,
+		Rules: []*RuleDiff{
+			{RuleID: "JE_B", Before: OutcomeFailed, After: OutcomePassed},
+		},
+	}, diff)
+}
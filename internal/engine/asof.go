@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// asOfView evaluates a context on successive days without re-filtering every presence each day.
+// Presences are sorted by date once, and the presences up to a day are a prefix of the sorted slice.
+type asOfView struct {
+	ctx          *domain.EvaluationContext
+	presences    []*domain.Presence
+	dependencies map[domain.RegionID]*asOfView
+}
+
+func newAsOfView(ctx *domain.EvaluationContext) *asOfView {
+	v := &asOfView{
+		ctx:       ctx,
+		presences: slices.Clone(ctx.Presences),
+	}
+
+	slices.SortStableFunc(v.presences, func(a, b *domain.Presence) int { return a.Date.Compare(b.Date) })
+
+	if len(ctx.Dependencies) > 0 {
+		v.dependencies = make(map[domain.RegionID]*asOfView, len(ctx.Dependencies))
+		for id, dep := range ctx.Dependencies {
+			v.dependencies[id] = newAsOfView(dep)
+		}
+	}
+
+	return v
+}
+
+// on returns a copy of the context evaluated on the day, counting only presences up to and
+// including the day.
+func (v *asOfView) on(day time.Time) *domain.EvaluationContext {
+	c := *v.ctx
+	c.At = day
+
+	n := sort.Search(len(v.presences), func(i int) bool { return v.presences[i].Date.After(day) })
+	c.Presences = v.presences[:n:n]
+
+	if v.dependencies != nil {
+		c.Dependencies = make(map[domain.RegionID]*domain.EvaluationContext, len(v.dependencies))
+		for id, dep := range v.dependencies {
+			c.Dependencies[id] = dep.on(day)
+		}
+	}
+
+	return &c
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// compare compares an answer against the expected value of a condition node. Values are
+// interpreted according to the condition type, as answers decoded from JSON carry no type of
+// their own (e.g. every number is a float64).
+func compare(ct domain.ConditionType, comparator domain.Comparator, expected, actual any) (bool, error) {
+	if !ct.Supports(comparator) {
+		return false, fmt.Errorf("comparator %s is not supported for %s conditions", comparator, ct)
+	}
+
+	switch comparator {
+	case domain.ComparatorEquals:
+		return equal(ct, expected, actual)
+
+	case domain.ComparatorNotEquals:
+		eq, err := equal(ct, expected, actual)
+		return !eq, err
+
+	case domain.ComparatorGreater, domain.ComparatorGreaterOrEq, domain.ComparatorLess, domain.ComparatorLessOrEq:
+		e, err := toNumber(expected)
+		if err != nil {
+			return false, fmt.Errorf("expected value: %w", err)
+		}
+
+		a, err := toNumber(actual)
+		if err != nil {
+			return false, fmt.Errorf("answer: %w", err)
+		}
+
+		switch comparator {
+		case domain.ComparatorGreater:
+			return a > e, nil
+		case domain.ComparatorGreaterOrEq:
+			return a >= e, nil
+		case domain.ComparatorLess:
+			return a < e, nil
+		default:
+			return a <= e, nil
+		}
+
+	case domain.ComparatorIn:
+		options, err := toList(expected)
+		if err != nil {
+			return false, fmt.Errorf("expected value: %w", err)
+		}
+
+		return containsValue(ct, options, actual)
+
+	case domain.ComparatorContains:
+		items, err := toList(actual)
+		if err != nil {
+			return false, fmt.Errorf("answer: %w", err)
+		}
+
+		return containsValue(domain.ConditionTypeSelect, items, expected)
+
+	case domain.ComparatorContainsAny, domain.ComparatorContainsAll:
+		wanted, err := toList(expected)
+		if err != nil {
+			return false, fmt.Errorf("expected value: %w", err)
+		}
+
+		items, err := toList(actual)
+		if err != nil {
+			return false, fmt.Errorf("answer: %w", err)
+		}
+
+		for _, w := range wanted {
+			found, err := containsValue(domain.ConditionTypeSelect, items, w)
+			if err != nil {
+				return false, err
+			}
+
+			if found && comparator == domain.ComparatorContainsAny {
+				return true, nil
+			}
+
+			if !found && comparator == domain.ComparatorContainsAll {
+				return false, nil
+			}
+		}
+
+		return comparator == domain.ComparatorContainsAll, nil
+
+	default:
+		return false, fmt.Errorf("unsupported comparator: %s", comparator)
+	}
+}
+
+// equal compares two values of the given condition type. Multi-select values are equal when they
+// hold the same options regardless of order.
+func equal(ct domain.ConditionType, expected, actual any) (bool, error) {
+	switch ct {
+	case domain.ConditionTypeInteger:
+		e, err := toNumber(expected)
+		if err != nil {
+			return false, fmt.Errorf("expected value: %w", err)
+		}
+
+		a, err := toNumber(actual)
+		if err != nil {
+			return false, fmt.Errorf("answer: %w", err)
+		}
+
+		return e == a, nil
+
+	case domain.ConditionTypeBoolean:
+		e, ok := expected.(bool)
+		if !ok {
+			return false, fmt.Errorf("expected value %v is not a boolean", expected)
+		}
+
+		a, ok := actual.(bool)
+		if !ok {
+			return false, fmt.Errorf("answer %v is not a boolean", actual)
+		}
+
+		return e == a, nil
+
+	case domain.ConditionTypeMultiSelect:
+		e, err := toList(expected)
+		if err != nil {
+			return false, fmt.Errorf("expected value: %w", err)
+		}
+
+		a, err := toList(actual)
+		if err != nil {
+			return false, fmt.Errorf("answer: %w", err)
+		}
+
+		if len(e) != len(a) {
+			return false, nil
+		}
+
+		for _, v := range e {
+			found, err := containsValue(domain.ConditionTypeSelect, a, v)
+			if err != nil || !found {
+				return false, err
+			}
+		}
+
+		return true, nil
+
+	default:
+		e, ok := expected.(string)
+		if !ok {
+			return false, fmt.Errorf("expected value %v is not a string", expected)
+		}
+
+		a, ok := actual.(string)
+		if !ok {
+			return false, fmt.Errorf("answer %v is not a string", actual)
+		}
+
+		return e == a, nil
+	}
+}
+
+func containsValue(ct domain.ConditionType, list []any, value any) (bool, error) {
+	for _, item := range list {
+		eq, err := equal(ct, item, value)
+		if err != nil {
+			return false, err
+		}
+
+		if eq {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func toNumber(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
+
+func toList(v any) ([]any, error) {
+	if list, ok := v.([]any); ok {
+		return list, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%v is not a list", v)
+	}
+
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+
+	return list, nil
+}
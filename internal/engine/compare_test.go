@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name       string
+		ct         domain.ConditionType
+		comparator domain.Comparator
+		expected   any
+		actual     any
+		want       bool
+		wantErr    bool
+	}{
+		{name: "boolean equal", ct: domain.ConditionTypeBoolean, comparator: domain.ComparatorEquals, expected: true, actual: true, want: true},
+		{name: "boolean not equal", ct: domain.ConditionTypeBoolean, comparator: domain.ComparatorNotEquals, expected: true, actual: false, want: true},
+		{name: "boolean type mismatch", ct: domain.ConditionTypeBoolean, comparator: domain.ComparatorEquals, expected: true, actual: "yes", wantErr: true},
+		{name: "integer equal across number types", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorEquals, expected: 3, actual: float64(3), want: true},
+		{name: "integer greater", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorGreater, expected: float64(2), actual: float64(3), want: true},
+		{name: "integer greater or equal", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorGreaterOrEq, expected: float64(3), actual: float64(3), want: true},
+		{name: "integer less", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorLess, expected: float64(3), actual: float64(3)},
+		{name: "integer less or equal", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorLessOrEq, expected: float64(3), actual: float64(3), want: true},
+		{name: "integer in", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorIn, expected: []any{float64(1), float64(2)}, actual: float64(2), want: true},
+		{name: "integer not a number", ct: domain.ConditionTypeInteger, comparator: domain.ComparatorGreater, expected: float64(2), actual: "3", wantErr: true},
+		{name: "select in", ct: domain.ConditionTypeSelect, comparator: domain.ComparatorIn, expected: []any{"a", "b"}, actual: "b", want: true},
+		{name: "select not in", ct: domain.ConditionTypeSelect, comparator: domain.ComparatorIn, expected: []any{"a", "b"}, actual: "c"},
+		{name: "select in requires list", ct: domain.ConditionTypeSelect, comparator: domain.ComparatorIn, expected: "a", actual: "a", wantErr: true},
+		{name: "select ordering unsupported", ct: domain.ConditionTypeSelect, comparator: domain.ComparatorGreater, expected: "a", actual: "b", wantErr: true},
+		{name: "multi select contains", ct: domain.ConditionTypeMultiSelect, comparator: domain.ComparatorContains, expected: "b", actual: []any{"a", "b"}, want: true},
+		{name: "multi select contains any", ct: domain.ConditionTypeMultiSelect, comparator: domain.ComparatorContainsAny, expected: []any{"c", "b"}, actual: []any{"a", "b"}, want: true},
+		{name: "multi select contains all", ct: domain.ConditionTypeMultiSelect, comparator: domain.ComparatorContainsAll, expected: []any{"a", "c"}, actual: []any{"a", "b"}},
+		{name: "multi select equal ignores order", ct: domain.ConditionTypeMultiSelect, comparator: domain.ComparatorEquals, expected: []string{"b", "a"}, actual: []any{"a", "b"}, want: true},
+		{name: "multi select answer not a list", ct: domain.ConditionTypeMultiSelect, comparator: domain.ComparatorContains, expected: "a", actual: "a", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := compare(tc.ct, tc.comparator, tc.expected, tc.actual)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/pumpkinlog/backend/internal/domain"
@@ -10,72 +13,270 @@ import (
 
 type Engine struct {
 	strategies *strategies.Strategies
+	// templates render explanations of strategy evaluations, keyed by strategy type.
+	templates map[string]*template.Template
 }
 
 func NewEngine() *Engine {
-	return &Engine{
+	e := &Engine{
 		strategies: strategies.NewStrategies(),
+		templates:  make(map[string]*template.Template),
+	}
+	e.registerDefaultTemplates()
+	return e
+}
+
+// evaluator holds the state of a single region evaluation. Rule results are memoised so a rule
+// referenced from several places is evaluated once, and rules being evaluated are tracked to detect
+// reference cycles.
+type evaluator struct {
+	*Engine
+	contexts []*domain.EvaluationContext
+	results  map[domain.Code]domain.EvaluationComponent
+	visiting map[domain.Code]struct{}
+}
+
+func (e *Engine) newEvaluator(ctx *domain.EvaluationContext) *evaluator {
+	contexts := []*domain.EvaluationContext{ctx}
+	for _, dep := range ctx.Dependencies {
+		contexts = append(contexts, dep)
+	}
+
+	return &evaluator{
+		Engine:   e,
+		contexts: contexts,
+		results:  make(map[domain.Code]domain.EvaluationComponent),
+		visiting: make(map[domain.Code]struct{}),
 	}
 }
 
 // EvaluateRegion evaluates all rules and returns their details plus overall pass status.
-func (e *Engine) EvaluateRegion(ctx *domain.EvaluationContext) ([]domain.EvaluationComponent, bool, error) {
-	passed := true
+//
+// The region passes when every rule passes. When no rule has failed but some could not be evaluated
+// the region is errored, and the errored rules are reported. Otherwise, when some rules depend on
+// unanswered conditions the region is indeterminate, and the conditions that could change the
+// outcome are listed.
+func (e *Engine) EvaluateRegion(ctx *domain.EvaluationContext) (*domain.RegionEvaluation, error) {
+	var (
+		failed, indeterminate bool
+		errored               []domain.Code
+	)
 	evaluations := make([]domain.EvaluationComponent, len(ctx.Rules))
 
+	ev := e.newEvaluator(ctx)
+
 	for i, rule := range ctx.Rules {
-		evaluation, err := e.evaluateRuleNode(rule.Node, ctx)
+		evaluation, err := ev.evaluateRule(rule, ctx)
 		if err != nil {
-			return nil, false, fmt.Errorf("evaluate rule %s: %w", rule.ID, err)
+			return nil, err
 		}
 
 		evaluations[i] = evaluation
 
-		if passed && !evaluation.IsPassed() {
-			passed = false
+		switch {
+		case evaluation.IsErrored():
+			errored = append(errored, rule.ID)
+		case evaluation.IsIndeterminate():
+			indeterminate = true
+		case !evaluation.IsPassed():
+			failed = true
+		}
+	}
+
+	evaluation := &domain.RegionEvaluation{
+		RegionID:    ctx.Region.ID,
+		Passed:      !failed && !indeterminate && len(errored) == 0,
+		Status:      domain.EvaluationStatusEvaluated,
+		Nodes:       evaluations,
+		PointInTime: ctx.At,
+	}
+
+	switch {
+	case failed:
+	case len(errored) > 0:
+		evaluation.Status = domain.EvaluationStatusError
+		evaluation.Reason = fmt.Sprintf("error, %s could not be evaluated", joinCodes(errored))
+	case indeterminate:
+		unresolved := unresolvedConditions(evaluations)
+
+		evaluation.Status = domain.EvaluationStatusIndeterminate
+		evaluation.Unresolved = unresolved
+		evaluation.Reason = fmt.Sprintf("indeterminate, answer %s to resolve", joinCodes(unresolved))
+	}
+
+	return evaluation, nil
+}
+
+// unresolvedConditions returns the conditions that could change the outcome of the indeterminate
+// components. Determined subtrees are skipped, as no answer beneath them can change their result.
+func unresolvedConditions(components []domain.EvaluationComponent) []domain.Code {
+	var (
+		ids  []domain.Code
+		seen = make(map[domain.Code]struct{})
+		walk func(c domain.EvaluationComponent)
+	)
+
+	walk = func(c domain.EvaluationComponent) {
+		if !c.IsIndeterminate() {
+			return
+		}
+
+		switch v := c.(type) {
+		case *domain.CompositeEvaluation:
+			for _, child := range v.Components {
+				walk(child)
+			}
+		case *domain.ReferenceEvaluation:
+			if v.Component != nil {
+				walk(v.Component)
+			}
+		case *domain.ConditionEvaluation:
+			if _, ok := seen[v.ConditionID]; !ok {
+				seen[v.ConditionID] = struct{}{}
+				ids = append(ids, v.ConditionID)
+			}
+		case *domain.StrategyEvaluation:
+			if v.Split == nil {
+				return
+			}
+			for _, id := range v.Split.Unresolved {
+				if _, ok := seen[id]; !ok {
+					seen[id] = struct{}{}
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	for _, c := range components {
+		walk(c)
+	}
+
+	return ids
+}
+
+func joinCodes(ids []domain.Code) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = string(id)
+	}
+	return strings.Join(s, ", ")
+}
+
+// evaluateRule evaluates a rule in the context of its region, reusing the result when the rule has
+// already been evaluated.
+func (e *evaluator) evaluateRule(rule *domain.Rule, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
+	if result, ok := e.results[rule.ID]; ok {
+		return result, nil
+	}
+
+	if _, ok := e.visiting[rule.ID]; ok {
+		return nil, fmt.Errorf("rule reference cycle at %s", rule.ID)
+	}
+
+	e.visiting[rule.ID] = struct{}{}
+	defer delete(e.visiting, rule.ID)
+
+	ruleCtx := *ctx
+	ruleCtx.Presences = rulePresences(rule, ctx)
+
+	result, err := e.evaluateRuleNode(rule.Node, &ruleCtx)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate rule %s: %w", rule.ID, err)
+	}
+
+	e.results[rule.ID] = result
+
+	return result, nil
+}
+
+// lookupRule finds a rule and the context of its region.
+func (e *evaluator) lookupRule(ruleID domain.Code) (*domain.Rule, *domain.EvaluationContext) {
+	for _, ctx := range e.contexts {
+		for _, rule := range ctx.Rules {
+			if rule.ID == ruleID {
+				return rule, ctx
+			}
 		}
 	}
 
-	return evaluations, passed, nil
+	return nil, nil
+}
+
+// rulePresences returns the presences that count toward a rule. These are presences in the region
+// and its zone members, plus presences in any subregion when the rule includes subregions.
+func rulePresences(rule *domain.Rule, ctx *domain.EvaluationContext) []*domain.Presence {
+	scope := make(map[domain.RegionID]struct{})
+	for _, id := range ctx.Region.RegionIDs() {
+		scope[id] = struct{}{}
+	}
+
+	if rule.IncludeSubregions {
+		for _, id := range ctx.SubregionIDs {
+			scope[id] = struct{}{}
+		}
+	}
+
+	presences := make([]*domain.Presence, 0, len(ctx.Presences))
+	for _, p := range ctx.Presences {
+		if _, ok := scope[p.RegionID]; ok {
+			presences = append(presences, p)
+		}
+	}
+
+	return presences
 }
 
 // evaluateRuleNode dispatches evaluation based on node operator.
-func (e *Engine) evaluateRuleNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
+func (e *evaluator) evaluateRuleNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
 	switch node.Type {
-	case domain.NodeTypeCompositeAnd, domain.NodeTypeCompositeAny:
+	case domain.NodeTypeCompositeAnd, domain.NodeTypeCompositeAny, domain.NodeTypeCompositeAtLeast:
 		return e.evaluateCompositeNode(node, ctx)
+	case domain.NodeTypeCompositeNot:
+		return e.evaluateNotNode(node, ctx)
 	case domain.NodeTypeStrategy:
 		return e.evaluateStrategyNode(node, ctx)
 	case domain.NodeTypeCondition:
 		return e.evaluateConditionNode(node, ctx)
+	case domain.NodeTypeRule:
+		return e.evaluateRuleRefNode(node)
 	default:
 		return nil, fmt.Errorf("unsupported node type: %s", node.Type)
 	}
 }
 
-func (e *Engine) evaluateCompositeNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
-	var nodes []domain.RuleNode
-	if err := node.Unmarshal(&nodes); err != nil {
-		return nil, fmt.Errorf("cannot unmarshal composite node: %w", err)
-	}
-
-	evaluations := make([]domain.EvaluationComponent, len(nodes))
+func (e *evaluator) evaluateCompositeNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
+	var (
+		nodes    []domain.RuleNode
+		required int
+	)
 
-	var passed bool
 	switch node.Type {
-	case domain.NodeTypeCompositeAnd:
-		passed = true
-	case domain.NodeTypeCompositeAny:
+	case domain.NodeTypeCompositeAnd, domain.NodeTypeCompositeAny:
+		if err := node.Unmarshal(&nodes); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal composite node: %w", err)
+		}
+
+		required = 1
+		if node.Type == domain.NodeTypeCompositeAnd {
+			required = len(nodes)
+		}
+	case domain.NodeTypeCompositeAtLeast:
+		var tn domain.ThresholdNode
+		if err := node.Unmarshal(&tn); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal threshold node: %w", err)
+		}
+
+		nodes = tn.Nodes
+		required = tn.K
 	default:
 		return nil, fmt.Errorf("unknown composite node type: %s", node.Type)
 	}
 
-	for i, n := range nodes {
-		var cn domain.CompositeNode
-		if err := n.Unmarshal(&cn); err != nil {
-			return nil, fmt.Errorf("cannot unmarshal composite node: %w", err)
-		}
+	evaluations := make([]domain.EvaluationComponent, len(nodes))
 
+	var passedCount, unknownCount, erroredCount int
+	for i, n := range nodes {
 		evaluation, err := e.evaluateRuleNode(n, ctx)
 		if err != nil {
 			return nil, err
@@ -83,37 +284,115 @@ func (e *Engine) evaluateCompositeNode(node domain.RuleNode, ctx *domain.Evaluat
 
 		evaluations[i] = evaluation
 
-		switch node.Type {
-		case domain.NodeTypeCompositeAnd:
-			passed = true
-			for _, evaluation := range evaluations {
-				if !evaluation.IsPassed() {
-					passed = false
-					break
-				}
-			}
-		case domain.NodeTypeCompositeAny:
-			passed = false
-			for _, evaluation := range evaluations {
-				if evaluation.IsPassed() {
-					passed = true
-					break
-				}
-			}
-		default:
-			return nil, fmt.Errorf("unknown composite node type: %s", node.Type)
+		switch {
+		case evaluation.IsErrored():
+			erroredCount++
+		case evaluation.IsIndeterminate():
+			unknownCount++
+		case evaluation.IsPassed():
+			passedCount++
+		}
+	}
+
+	// Kleene logic: the node is only undecided when the unknown or errored children could still decide
+	// it, and it is errored when any of those children can never be decided
+	status := domain.EvaluationStatusEvaluated
+	if passedCount < required && passedCount+unknownCount+erroredCount >= required {
+		status = domain.EvaluationStatusIndeterminate
+		if erroredCount > 0 {
+			status = domain.EvaluationStatusError
 		}
 	}
 
 	return &domain.CompositeEvaluation{
-		NodeType:   node.Type,
-		Status:     domain.EvaluationStatusEvaluated,
-		Passed:     passed,
-		Components: evaluations,
+		NodeType:    node.Type,
+		Status:      status,
+		Passed:      passedCount >= required,
+		PassedCount: passedCount,
+		Required:    required,
+		Components:  evaluations,
+	}, nil
+}
+
+func (e *evaluator) evaluateNotNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
+	var nn domain.NotNode
+	if err := node.Unmarshal(&nn); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal not node: %w", err)
+	}
+
+	evaluation, err := e.evaluateRuleNode(nn.Node, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if evaluation.IsErrored() || evaluation.IsIndeterminate() {
+		status := domain.EvaluationStatusIndeterminate
+		if evaluation.IsErrored() {
+			status = domain.EvaluationStatusError
+		}
+
+		return &domain.CompositeEvaluation{
+			NodeType:   node.Type,
+			Status:     status,
+			Components: []domain.EvaluationComponent{evaluation},
+		}, nil
+	}
+
+	var passedCount int
+	if evaluation.IsPassed() {
+		passedCount++
+	}
+
+	return &domain.CompositeEvaluation{
+		NodeType:    node.Type,
+		Status:      domain.EvaluationStatusEvaluated,
+		Passed:      !evaluation.IsPassed(),
+		PassedCount: passedCount,
+		Components:  []domain.EvaluationComponent{evaluation},
+	}, nil
+}
+
+func (e *evaluator) evaluateRuleRefNode(node domain.RuleNode) (domain.EvaluationComponent, error) {
+	var rn domain.RuleRefNode
+	if err := node.Unmarshal(&rn); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal rule node: %w", err)
+	}
+
+	rule, ruleCtx := e.lookupRule(rn.RuleID)
+	if rule == nil {
+		return &domain.ReferenceEvaluation{
+			Type:   domain.ComponentTypeReference,
+			RuleID: rn.RuleID,
+			Status: domain.EvaluationStatusError,
+			Reason: fmt.Sprintf("rule %s not found", rn.RuleID),
+		}, nil
+	}
+
+	evaluation, err := e.evaluateRule(rule, ruleCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.EvaluationStatusEvaluated
+	switch {
+	case evaluation.IsErrored():
+		status = domain.EvaluationStatusError
+	case evaluation.IsIndeterminate():
+		status = domain.EvaluationStatusIndeterminate
+	}
+
+	return &domain.ReferenceEvaluation{
+		Type:      domain.ComponentTypeReference,
+		RuleID:    rule.ID,
+		RegionID:  rule.RegionID,
+		Status:    status,
+		Passed:    evaluation.IsPassed(),
+		Reason:    fmt.Sprintf("rule %s evaluated", rule.ID),
+		Component: evaluation,
 	}, nil
 }
 
-func (e *Engine) evaluateStrategyNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
+func (e *evaluator) evaluateStrategyNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
 	var sn domain.EvaluatorNode
 	if err := node.Unmarshal(&sn); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal strategy node: %w", err)
@@ -124,18 +403,84 @@ func (e *Engine) evaluateStrategyNode(node domain.RuleNode, ctx *domain.Evaluati
 		return nil, fmt.Errorf("compute period: %w", err)
 	}
 
+	var split *domain.PeriodSplit
+	if sn.Period.Type == domain.PeriodTypeSplitYear {
+		start, end, split, err = splitPeriod(ctx, sn, start, end)
+
+		// Boundaries that cannot be derived leave the strategy undecided rather than failing the
+		// whole region evaluation
+		switch {
+		case err != nil:
+			return &domain.StrategyEvaluation{
+				Type:     domain.ComponentTypeStrategy,
+				Strategy: sn.Type,
+				Status:   domain.EvaluationStatusError,
+				Reason:   fmt.Sprintf("split-year period: %s", err),
+				Start:    start,
+				End:      end,
+				Split:    split,
+			}, nil
+		case len(split.Unresolved) > 0:
+			return &domain.StrategyEvaluation{
+				Type:     domain.ComponentTypeStrategy,
+				Strategy: sn.Type,
+				Status:   domain.EvaluationStatusUnanswered,
+				Reason:   fmt.Sprintf("split-year period: answer %s to derive the period", joinCodes(split.Unresolved)),
+				Start:    start,
+				End:      end,
+				Split:    split,
+			}, nil
+		}
+	}
+
+	lookback, err := e.strategies.Lookback(sn.Type, sn.Props)
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s lookback: %w", sn.Type, err)
+	}
+
+	from := start.AddDate(0, 0, -lookback)
+
+	// Presences in different regions on the same date collapse into a single day, which counts
+	// if any of them counts under the day count mode
 	presences := make(map[time.Time]struct{})
 	for _, p := range ctx.Presences {
-		if !p.Date.Before(start) && !p.Date.After(end) {
+		if !sn.DayCount.Counts(p) {
+			continue
+		}
+
+		if !p.Date.Before(from) && !p.Date.After(end) {
 			presences[p.Date] = struct{}{}
 		}
 	}
 
-	se, err := e.strategies.Evaluate(sn.Type, sn.Props, presences)
+	period := strategies.Period{
+		At:    wallClock(ctx.At),
+		Start: start,
+		End:   end,
+	}
+
+	// Configs are validated when rules are saved, so a strategy that fails here failed on the
+	// presences, e.g. an expression dividing by a year without days. It is reported against the
+	// strategy rather than failing the whole region evaluation
+	se, err := e.strategies.Evaluate(sn.Type, sn.Props, period, presences)
 	if err != nil {
-		return nil, fmt.Errorf("evaluate strategy %s: %w", node.Type, err)
+		return &domain.StrategyEvaluation{
+			Type:     domain.ComponentTypeStrategy,
+			Strategy: sn.Type,
+			Status:   domain.EvaluationStatusError,
+			Reason:   fmt.Sprintf("strategy %s: %s", sn.Type, err),
+			Start:    start,
+			End:      end,
+			Split:    split,
+		}, nil
 	}
 
+	// Every strategy config has a threshold, it is reported so evaluations can be explained
+	var cfg struct {
+		Threshold int `json:"threshold"`
+	}
+	_ = json.Unmarshal(sn.Props, &cfg)
+
 	return &domain.StrategyEvaluation{
 		Type:      domain.ComponentTypeStrategy,
 		Strategy:  sn.Type,
@@ -145,37 +490,64 @@ func (e *Engine) evaluateStrategyNode(node domain.RuleNode, ctx *domain.Evaluati
 		Start:     start,
 		End:       end,
 		Count:     se.Count,
+		Threshold: cfg.Threshold,
 		Remaining: se.Remaining,
+		Metadata:  se.Metadata,
+		Split:     split,
 	}, nil
 }
 
-func (e *Engine) evaluateConditionNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
+func (e *evaluator) evaluateConditionNode(node domain.RuleNode, ctx *domain.EvaluationContext) (domain.EvaluationComponent, error) {
 	var cn domain.ConditionNode
 	if err := node.Unmarshal(&cn); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal condition node: %w", err)
 	}
 
+	expected := cn.Expected()
+
 	answer, ok := ctx.Answers[cn.ConditionID]
 	if !ok || answer.Value == nil {
 		return &domain.ConditionEvaluation{
 			Type:        domain.ComponentTypeCondition,
 			ConditionID: cn.ConditionID,
-			Expected:    cn.Equals,
+			Expected:    expected,
 			Comparator:  cn.Comparator,
 			Status:      domain.EvaluationStatusUnanswered,
 			Reason:      fmt.Sprintf("condition %s not answered", cn.ConditionID),
 		}, nil
 	}
 
-	passed, err := compareGeneric(cn.Comparator, cn.Equals, answer.Value)
+	// Type errors are reported against the condition rather than failing the whole region evaluation
+	condition, ok := ctx.Conditions[cn.ConditionID]
+	if !ok {
+		return &domain.ConditionEvaluation{
+			Type:        domain.ComponentTypeCondition,
+			ConditionID: cn.ConditionID,
+			Expected:    expected,
+			Actual:      answer.Value,
+			Comparator:  cn.Comparator,
+			Status:      domain.EvaluationStatusError,
+			Reason:      fmt.Sprintf("condition %s not found", cn.ConditionID),
+		}, nil
+	}
+
+	passed, err := compare(condition.Type, cn.Comparator, expected, answer.Value)
 	if err != nil {
-		return nil, fmt.Errorf("compare condition %s: %w", cn.ConditionID, err)
+		return &domain.ConditionEvaluation{
+			Type:        domain.ComponentTypeCondition,
+			ConditionID: cn.ConditionID,
+			Expected:    expected,
+			Actual:      answer.Value,
+			Comparator:  cn.Comparator,
+			Status:      domain.EvaluationStatusError,
+			Reason:      fmt.Sprintf("compare condition %s: %s", cn.ConditionID, err),
+		}, nil
 	}
 
 	return &domain.ConditionEvaluation{
 		Type:        domain.ComponentTypeCondition,
 		ConditionID: cn.ConditionID,
-		Expected:    cn.Equals,
+		Expected:    expected,
 		Actual:      answer.Value,
 		Comparator:  cn.Comparator,
 		Status:      domain.EvaluationStatusEvaluated,
@@ -183,14 +555,3 @@ func (e *Engine) evaluateConditionNode(node domain.RuleNode, ctx *domain.Evaluat
 		Reason:      fmt.Sprintf("condition %s evaluated", cn.ConditionID),
 	}, nil
 }
-
-func compareGeneric(comparator domain.Comparator, expected, actual any) (bool, error) {
-	switch comparator {
-	case domain.ComparatorEquals:
-		return expected == actual, nil
-	case domain.ComparatorNotEquals:
-		return expected != actual, nil
-	default:
-		return false, fmt.Errorf("unsupported operator: %s", comparator)
-	}
-}
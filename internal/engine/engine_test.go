@@ -0,0 +1,514 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+var testAt = time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+func testRegion(id domain.RegionID) *domain.Region {
+	return &domain.Region{
+		ID:             id,
+		Type:           domain.RegionTypeCountry,
+		YearStartMonth: time.January,
+		YearStartDay:   1,
+	}
+}
+
+func testPresences(regionID domain.RegionID, start time.Time, days int) []*domain.Presence {
+	presences := make([]*domain.Presence, days)
+	for i := range days {
+		presences[i] = &domain.Presence{RegionID: regionID, Date: start.AddDate(0, 0, i)}
+	}
+	return presences
+}
+
+func strategyNode(t *testing.T, strategy string, period domain.Period, props string) domain.RuleNode {
+	t.Helper()
+
+	raw, err := json.Marshal(domain.EvaluatorNode{
+		Type:   strategy,
+		Period: period,
+		Props:  json.RawMessage(props),
+	})
+	require.NoError(t, err)
+
+	return domain.RuleNode{Type: domain.NodeTypeStrategy, Props: raw}
+}
+
+func TestEvaluateRegionPresenceScope(t *testing.T) {
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+
+	zone := testRegion("EU")
+	zone.Type = domain.RegionTypeZone
+	zone.MemberRegionIDs = []domain.RegionID{"FR", "DE"}
+
+	tests := []struct {
+		name              string
+		region            *domain.Region
+		subregionIDs      []domain.RegionID
+		includeSubregions bool
+		presences         []*domain.Presence
+		wantCount         int
+	}{
+		{
+			name:      "only region presences count",
+			region:    testRegion("US"),
+			presences: append(testPresences("US", jan, 5), testPresences("GB", jan.AddDate(0, 1, 0), 5)...),
+			wantCount: 5,
+		},
+		{
+			name:         "subregion presences ignored by default",
+			region:       testRegion("US"),
+			subregionIDs: []domain.RegionID{"US-CA"},
+			presences:    append(testPresences("US", jan, 5), testPresences("US-CA", jan.AddDate(0, 1, 0), 5)...),
+			wantCount:    5,
+		},
+		{
+			name:              "subregion presences rolled up",
+			region:            testRegion("US"),
+			subregionIDs:      []domain.RegionID{"US-CA"},
+			includeSubregions: true,
+			presences:         append(testPresences("US", jan, 5), testPresences("US-CA", jan.AddDate(0, 1, 0), 5)...),
+			wantCount:         10,
+		},
+		{
+			name:      "zone member days on the same date count once",
+			region:    zone,
+			presences: append(testPresences("FR", jan, 5), testPresences("DE", jan.AddDate(0, 0, 3), 5)...),
+			wantCount: 8,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &domain.Rule{
+				ID:                "TEST_RULE",
+				RegionID:          tc.region.ID,
+				Node:              strategyNode(t, "aggregate", yearPeriod, `{"threshold":183}`),
+				IncludeSubregions: tc.includeSubregions,
+			}
+
+			ctx := &domain.EvaluationContext{
+				At:           testAt,
+				Region:       tc.region,
+				SubregionIDs: tc.subregionIDs,
+				Presences:    tc.presences,
+				Rules:        []*domain.Rule{rule},
+			}
+
+			evaluation, err := NewEngine().EvaluateRegion(ctx)
+			require.NoError(t, err)
+			require.Len(t, evaluation.Nodes, 1)
+
+			se, ok := evaluation.Nodes[0].(*domain.StrategyEvaluation)
+			require.True(t, ok)
+			require.Equal(t, tc.wantCount, se.Count)
+		})
+	}
+}
+
+func TestEvaluateRegionExpressionPriorYears(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+	props := `{"expression":"daysInYear(0) + daysInYear(1) / 3 + daysInYear(2) / 6 >= 183"}`
+
+	current := testPresences("US", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 100)
+	prior := append(
+		testPresences("US", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 180),
+		testPresences("US", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC), 180)...,
+	)
+
+	tests := []struct {
+		name       string
+		presences  []*domain.Presence
+		wantPassed bool
+	}{
+		{
+			name:      "current year alone is not enough",
+			presences: current,
+		},
+		{
+			// 100 + 180 / 3 + 180 / 6 = 190 days
+			name:       "days in prior years before the period count",
+			presences:  append(append([]*domain.Presence{}, current...), prior...),
+			wantPassed: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &domain.EvaluationContext{
+				At:        testAt,
+				Region:    testRegion("US"),
+				Presences: tc.presences,
+				Rules:     []*domain.Rule{{ID: "US_SPT", RegionID: "US", Node: strategyNode(t, "expression", yearPeriod, props)}},
+			}
+
+			evaluation, err := NewEngine().EvaluateRegion(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPassed, evaluation.Passed)
+
+			se, ok := evaluation.Nodes[0].(*domain.StrategyEvaluation)
+			require.True(t, ok)
+			require.Equal(t, 100, se.Count)
+		})
+	}
+}
+
+func TestEvaluateRegionStrategyError(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+
+	// Without days in the prior year the expression divides by zero
+	ctx := &domain.EvaluationContext{
+		At:        testAt,
+		Region:    testRegion("US"),
+		Presences: testPresences("US", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 100),
+		Rules: []*domain.Rule{
+			{ID: "US_RATIO", RegionID: "US", Node: strategyNode(t, "expression", yearPeriod, `{"expression":"daysInYear(0) / daysInYear(1) > 2"}`)},
+		},
+	}
+
+	evaluation, err := NewEngine().EvaluateRegion(ctx)
+	require.NoError(t, err)
+	require.False(t, evaluation.Passed)
+	require.Equal(t, domain.EvaluationStatusError, evaluation.Status)
+	require.Equal(t, "error, US_RATIO could not be evaluated", evaluation.Reason)
+
+	se, ok := evaluation.Nodes[0].(*domain.StrategyEvaluation)
+	require.True(t, ok)
+	require.Equal(t, domain.EvaluationStatusError, se.Status)
+	require.Equal(t, "strategy expression: evaluate expression: division by zero", se.Reason)
+}
+
+func conditionNode(t *testing.T, conditionID domain.Code, equals any) domain.RuleNode {
+	t.Helper()
+
+	raw, err := json.Marshal(domain.ConditionNode{
+		ConditionID: conditionID,
+		Equals:      equals,
+		Comparator:  domain.ComparatorEquals,
+	})
+	require.NoError(t, err)
+
+	return domain.RuleNode{Type: domain.NodeTypeCondition, Props: raw}
+}
+
+func compositeNode(t *testing.T, nodeType domain.NodeType, props any) domain.RuleNode {
+	t.Helper()
+
+	raw, err := json.Marshal(props)
+	require.NoError(t, err)
+
+	return domain.RuleNode{Type: nodeType, Props: raw}
+}
+
+func TestEvaluateRegionCompositeNodes(t *testing.T) {
+	conditions := map[domain.Code]*domain.Condition{
+		"US_A": {ID: "US_A", Type: domain.ConditionTypeBoolean},
+		"US_B": {ID: "US_B", Type: domain.ConditionTypeBoolean},
+		"US_C": {ID: "US_C", Type: domain.ConditionTypeBoolean},
+	}
+
+	answers := map[domain.Code]*domain.Answer{
+		"US_A": {ConditionID: "US_A", Value: true},
+		"US_B": {ConditionID: "US_B", Value: true},
+		"US_C": {ConditionID: "US_C", Value: false},
+	}
+
+	tests := []struct {
+		name            string
+		node            func(t *testing.T) domain.RuleNode
+		wantPassed      bool
+		wantPassedCount int
+	}{
+		{
+			name: "not negates child",
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeNot, domain.NotNode{Node: conditionNode(t, "US_C", true)})
+			},
+			wantPassed: true,
+		},
+		{
+			name: "at least two of three",
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAtLeast, domain.ThresholdNode{
+					K:     2,
+					Nodes: []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true), conditionNode(t, "US_C", true)},
+				})
+			},
+			wantPassed:      true,
+			wantPassedCount: 2,
+		},
+		{
+			name: "at least three of three",
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAtLeast, domain.ThresholdNode{
+					K:     3,
+					Nodes: []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true), conditionNode(t, "US_C", true)},
+				})
+			},
+			wantPassedCount: 2,
+		},
+		{
+			name: "nested composites",
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{
+					conditionNode(t, "US_A", true),
+					compositeNode(t, domain.NodeTypeCompositeAny, []domain.RuleNode{
+						conditionNode(t, "US_B", false),
+						compositeNode(t, domain.NodeTypeCompositeNot, domain.NotNode{Node: conditionNode(t, "US_C", true)}),
+					}),
+				})
+			},
+			wantPassed:      true,
+			wantPassedCount: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &domain.EvaluationContext{
+				At:         testAt,
+				Region:     testRegion("US"),
+				Rules:      []*domain.Rule{{ID: "US_TEST", RegionID: "US", Node: tc.node(t)}},
+				Conditions: conditions,
+				Answers:    answers,
+			}
+
+			evaluation, err := NewEngine().EvaluateRegion(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPassed, evaluation.Passed)
+
+			ce, ok := evaluation.Nodes[0].(*domain.CompositeEvaluation)
+			require.True(t, ok)
+			require.Equal(t, tc.wantPassedCount, ce.PassedCount)
+		})
+	}
+}
+
+func TestEvaluateRegionIndeterminate(t *testing.T) {
+	conditions := map[domain.Code]*domain.Condition{
+		"US_A": {ID: "US_A", Type: domain.ConditionTypeBoolean},
+		"US_B": {ID: "US_B", Type: domain.ConditionTypeBoolean},
+		"US_C": {ID: "US_C", Type: domain.ConditionTypeBoolean},
+	}
+
+	tests := []struct {
+		name           string
+		answers        map[domain.Code]*domain.Answer
+		node           func(t *testing.T) domain.RuleNode
+		wantPassed     bool
+		wantStatus     domain.EvaluationStatus
+		wantUnresolved []domain.Code
+		wantReason     string
+	}{
+		{
+			name:    "and with a failed child is determined",
+			answers: map[domain.Code]*domain.Answer{"US_A": {Value: false}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true)})
+			},
+			wantStatus: domain.EvaluationStatusEvaluated,
+		},
+		{
+			name:    "and with a passed child depends on the unanswered one",
+			answers: map[domain.Code]*domain.Answer{"US_A": {Value: true}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true)})
+			},
+			wantStatus:     domain.EvaluationStatusIndeterminate,
+			wantUnresolved: []domain.Code{"US_B"},
+		},
+		{
+			name:    "any with a passed child is determined",
+			answers: map[domain.Code]*domain.Answer{"US_A": {Value: true}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAny, []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true)})
+			},
+			wantPassed: true,
+			wantStatus: domain.EvaluationStatusEvaluated,
+		},
+		{
+			name:    "not propagates unknown",
+			answers: map[domain.Code]*domain.Answer{},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeNot, domain.NotNode{Node: conditionNode(t, "US_C", true)})
+			},
+			wantStatus:     domain.EvaluationStatusIndeterminate,
+			wantUnresolved: []domain.Code{"US_C"},
+		},
+		{
+			name:    "only conditions that can change the outcome are listed",
+			answers: map[domain.Code]*domain.Answer{"US_A": {Value: true}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAtLeast, domain.ThresholdNode{
+					K: 2,
+					Nodes: []domain.RuleNode{
+						conditionNode(t, "US_A", true),
+						conditionNode(t, "US_B", true),
+						compositeNode(t, domain.NodeTypeCompositeAny, []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_C", true)}),
+					},
+				})
+			},
+			wantPassed: true,
+			wantStatus: domain.EvaluationStatusEvaluated,
+		},
+		{
+			name:    "errored child is an error, not a question to answer",
+			answers: map[domain.Code]*domain.Answer{"US_A": {Value: "yes"}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true)})
+			},
+			wantStatus: domain.EvaluationStatusError,
+			wantReason: "error, US_TEST could not be evaluated",
+		},
+		{
+			name:    "not propagates an error",
+			answers: map[domain.Code]*domain.Answer{"US_C": {Value: 1}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeNot, domain.NotNode{Node: conditionNode(t, "US_C", true)})
+			},
+			wantStatus: domain.EvaluationStatusError,
+			wantReason: "error, US_TEST could not be evaluated",
+		},
+		{
+			name:    "errored child that cannot change the outcome is ignored",
+			answers: map[domain.Code]*domain.Answer{"US_A": {Value: false}, "US_B": {Value: "yes"}},
+			node: func(t *testing.T) domain.RuleNode {
+				return compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{conditionNode(t, "US_A", true), conditionNode(t, "US_B", true)})
+			},
+			wantStatus: domain.EvaluationStatusEvaluated,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &domain.EvaluationContext{
+				At:         testAt,
+				Region:     testRegion("US"),
+				Rules:      []*domain.Rule{{ID: "US_TEST", RegionID: "US", Node: tc.node(t)}},
+				Conditions: conditions,
+				Answers:    tc.answers,
+			}
+
+			evaluation, err := NewEngine().EvaluateRegion(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPassed, evaluation.Passed)
+			require.Equal(t, tc.wantStatus, evaluation.Status)
+			require.Equal(t, tc.wantUnresolved, evaluation.Unresolved)
+			if tc.wantReason != "" {
+				require.Equal(t, tc.wantReason, evaluation.Reason)
+			}
+		})
+	}
+}
+
+func ruleRefNode(t *testing.T, ruleID domain.Code) domain.RuleNode {
+	t.Helper()
+
+	return compositeNode(t, domain.NodeTypeRule, domain.RuleRefNode{RuleID: ruleID})
+}
+
+func TestEvaluateRegionRuleReferences(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+
+	// GB_RESIDENT passes on 183 days in GB, JE_RESIDENT on 90 days in JE
+	gb := &domain.EvaluationContext{
+		At:        testAt,
+		Region:    testRegion("GB"),
+		Presences: testPresences("GB", testAt.AddDate(0, -6, 0), 100),
+		Rules: []*domain.Rule{
+			{ID: "GB_RESIDENT", RegionID: "GB", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":182}`)},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		rules      []*domain.Rule
+		wantPassed bool
+		wantErr    string
+	}{
+		{
+			name: "references a rule in another region",
+			rules: []*domain.Rule{
+				{ID: "JE_RESIDENT", RegionID: "JE", Node: compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{
+					compositeNode(t, domain.NodeTypeCompositeNot, domain.NotNode{Node: ruleRefNode(t, "GB_RESIDENT")}),
+					strategyNode(t, "aggregate", yearPeriod, `{"threshold":89}`),
+				})},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "references a rule in the same region",
+			rules: []*domain.Rule{
+				{ID: "JE_DAYS", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":89}`)},
+				{ID: "JE_RESIDENT", RegionID: "JE", Node: ruleRefNode(t, "JE_DAYS")},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "unknown reference is an error component",
+			rules: []*domain.Rule{
+				{ID: "JE_RESIDENT", RegionID: "JE", Node: ruleRefNode(t, "FR_RESIDENT")},
+			},
+		},
+		{
+			name: "cycle",
+			rules: []*domain.Rule{
+				{ID: "JE_A", RegionID: "JE", Node: ruleRefNode(t, "JE_B")},
+				{ID: "JE_B", RegionID: "JE", Node: ruleRefNode(t, "JE_A")},
+			},
+			wantErr: "evaluate rule JE_A: evaluate rule JE_B: rule reference cycle at JE_A",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &domain.EvaluationContext{
+				At:           testAt,
+				Region:       testRegion("JE"),
+				Presences:    testPresences("JE", testAt.AddDate(0, -3, 0), 90),
+				Rules:        tc.rules,
+				Dependencies: map[domain.RegionID]*domain.EvaluationContext{"GB": gb},
+			}
+
+			evaluation, err := NewEngine().EvaluateRegion(ctx)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPassed, evaluation.Passed)
+		})
+	}
+}
+
+func TestEvaluateRegionRuleReferencesMemoised(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+
+	ctx := &domain.EvaluationContext{
+		At:        testAt,
+		Region:    testRegion("JE"),
+		Presences: testPresences("JE", testAt.AddDate(0, -3, 0), 90),
+		Rules: []*domain.Rule{
+			{ID: "JE_DAYS", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":89}`)},
+			{ID: "JE_RESIDENT", RegionID: "JE", Node: ruleRefNode(t, "JE_DAYS")},
+		},
+	}
+
+	evaluation, err := NewEngine().EvaluateRegion(ctx)
+	require.NoError(t, err)
+	require.Len(t, evaluation.Nodes, 2)
+
+	ref, ok := evaluation.Nodes[1].(*domain.ReferenceEvaluation)
+	require.True(t, ok)
+	require.Same(t, evaluation.Nodes[0], ref.Component)
+}
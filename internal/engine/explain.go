@@ -0,0 +1,337 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// defaultTemplates are the message templates for the built-in strategies. Templates are rendered
+// with a strategyMessage.
+var defaultTemplates = map[string]string{
+	"aggregate": `You were present {{days .Count}} between {{date .Start}} and {{date .End}}; the threshold is {{.Threshold}}; ` +
+		`{{if .Passed}}passed by {{days .Margin}}{{else}}{{days .Remaining}} remaining{{end}}`,
+	"average": `You were present {{days .Count}} between {{date .Start}} and {{date .End}}; the average threshold is {{.Threshold}}; ` +
+		`{{if .Passed}}passed by {{days .Margin}}{{else}}{{days .Remaining}} remaining{{end}}`,
+	"weighted": `Your weighted presence between {{date .Start}} and {{date .End}} is {{days .Count}}; the threshold is {{.Threshold}}; ` +
+		`{{if .Passed}}passed by {{days .Margin}}{{else}}{{days .Remaining}} remaining{{end}}`,
+	"consecutive": `Your longest consecutive stay between {{date .Start}} and {{date .End}} was {{days .Count}}; the threshold is {{.Threshold}}; ` +
+		`{{if .Passed}}passed by {{days .Margin}}{{else}}{{days .Remaining}} remaining{{end}}`,
+	"sliding": `You were present at most {{days .Count}} in any {{index .Metadata "windowDays"}} day window ending between {{date .Start}} and {{date .End}}; ` +
+		`the limit is {{.Threshold}}; {{if .Passed}}exceeded by {{days .Margin}}{{else}}{{days .Remaining}} remaining{{end}}`,
+	"expression": `You were present {{days .Count}} between {{date .Start}} and {{date .End}}; ` +
+		`{{index .Metadata "expression"}} {{if .Passed}}holds{{else}}does not hold{{end}}`,
+}
+
+// fallbackTemplate is used for strategies without a registered template.
+const fallbackTemplate = `Strategy {{.Strategy}} counted {{days .Count}} between {{date .Start}} and {{date .End}} and {{if .Passed}}passed{{else}}failed{{end}}`
+
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format(time.DateOnly)
+	},
+	"days": func(n int) string {
+		if n == 1 || n == -1 {
+			return fmt.Sprintf("%d day", n)
+		}
+		return fmt.Sprintf("%d days", n)
+	},
+}
+
+// strategyMessage is the data a strategy template is rendered with.
+type strategyMessage struct {
+	Strategy  string
+	Passed    bool
+	Count     int
+	Threshold int
+	Remaining int
+	// Margin is how far the count is beyond the threshold.
+	Margin   int
+	Start    time.Time
+	End      time.Time
+	Metadata map[string]any
+}
+
+// RegisterTemplate sets the message template used to explain evaluations of the strategy, replacing
+// any existing template.
+func (e *Engine) RegisterTemplate(strategy, text string) error {
+	tmpl, err := template.New(strategy).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parse %s template: %w", strategy, err)
+	}
+
+	e.templates[strategy] = tmpl
+
+	return nil
+}
+
+func (e *Engine) registerDefaultTemplates() {
+	for strategy, text := range defaultTemplates {
+		if err := e.RegisterTemplate(strategy, text); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := e.RegisterTemplate("", fallbackTemplate); err != nil {
+		panic(err)
+	}
+}
+
+// Explain renders an explanation for every rule of an evaluation made with the context. Rules
+// referenced from other regions are explained using the dependencies of the context.
+func (e *Engine) Explain(ctx *domain.EvaluationContext, evaluation *domain.RegionEvaluation) ([]*domain.Explanation, error) {
+	x := &explainer{
+		Engine:     e,
+		rules:      make(map[domain.Code]*domain.Rule),
+		conditions: make(map[domain.Code]*domain.Condition),
+	}
+
+	for _, c := range append([]*domain.EvaluationContext{ctx}, dependencies(ctx)...) {
+		for _, rule := range c.Rules {
+			x.rules[rule.ID] = rule
+		}
+		for id, condition := range c.Conditions {
+			x.conditions[id] = condition
+		}
+	}
+
+	if len(evaluation.Nodes) != len(ctx.Rules) {
+		return nil, fmt.Errorf("evaluation has %d nodes for %d rules", len(evaluation.Nodes), len(ctx.Rules))
+	}
+
+	explanations := make([]*domain.Explanation, len(ctx.Rules))
+	for i, rule := range ctx.Rules {
+		explanation, err := x.explainRule(rule, evaluation.Nodes[i])
+		if err != nil {
+			return nil, fmt.Errorf("explain rule %s: %w", rule.ID, err)
+		}
+		explanations[i] = explanation
+	}
+
+	return explanations, nil
+}
+
+func dependencies(ctx *domain.EvaluationContext) []*domain.EvaluationContext {
+	deps := make([]*domain.EvaluationContext, 0, len(ctx.Dependencies))
+	for _, dep := range ctx.Dependencies {
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+type explainer struct {
+	*Engine
+	rules      map[domain.Code]*domain.Rule
+	conditions map[domain.Code]*domain.Condition
+}
+
+// explainRule explains the root node of a rule, labelled with the rule name and description.
+func (x *explainer) explainRule(rule *domain.Rule, c domain.EvaluationComponent) (*domain.Explanation, error) {
+	explanation, err := x.explainComponent(c)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation.RuleID = rule.ID
+	explanation.Name = rule.Name
+	explanation.Description = rule.Description
+
+	return explanation, nil
+}
+
+func (x *explainer) explainComponent(c domain.EvaluationComponent) (*domain.Explanation, error) {
+	switch v := c.(type) {
+	case *domain.CompositeEvaluation:
+		return x.explainComposite(v)
+	case *domain.StrategyEvaluation:
+		return x.explainStrategy(v)
+	case *domain.ConditionEvaluation:
+		return x.explainCondition(v), nil
+	case *domain.ReferenceEvaluation:
+		return x.explainReference(v)
+	default:
+		return nil, fmt.Errorf("unsupported evaluation component: %T", c)
+	}
+}
+
+func (x *explainer) explainComposite(c *domain.CompositeEvaluation) (*domain.Explanation, error) {
+	children := make([]*domain.Explanation, len(c.Components))
+	for i, component := range c.Components {
+		child, err := x.explainComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = child
+	}
+
+	var message string
+	switch c.NodeType {
+	case domain.NodeTypeCompositeAnd:
+		message = fmt.Sprintf("All of %d requirements must be met; %d met", len(c.Components), c.PassedCount)
+	case domain.NodeTypeCompositeAny:
+		message = fmt.Sprintf("Any of %d requirements must be met; %d met", len(c.Components), c.PassedCount)
+	case domain.NodeTypeCompositeAtLeast:
+		message = fmt.Sprintf("At least %d of %d requirements must be met; %d met", c.Required, len(c.Components), c.PassedCount)
+	case domain.NodeTypeCompositeNot:
+		message = "The requirement must not be met"
+	default:
+		message = fmt.Sprintf("%s of %d requirements", c.NodeType, len(c.Components))
+	}
+
+	switch {
+	case c.IsErrored():
+		message += "; the outcome could not be determined"
+	case c.IsIndeterminate():
+		message += "; the outcome depends on unanswered questions"
+	}
+
+	return &domain.Explanation{
+		Outcome:  domain.OutcomeOf(c),
+		Message:  message,
+		Children: children,
+	}, nil
+}
+
+func (x *explainer) explainStrategy(c *domain.StrategyEvaluation) (*domain.Explanation, error) {
+	switch c.Status {
+	case domain.EvaluationStatusUnanswered:
+		return &domain.Explanation{
+			Outcome: domain.OutcomeOf(c),
+			Message: fmt.Sprintf("The period could not be determined: %s", c.Reason),
+		}, nil
+	case domain.EvaluationStatusError:
+		return &domain.Explanation{
+			Outcome: domain.OutcomeOf(c),
+			Message: fmt.Sprintf("The test could not be checked: %s", c.Reason),
+		}, nil
+	}
+
+	tmpl, ok := x.templates[c.Strategy]
+	if !ok {
+		tmpl = x.templates[""]
+	}
+
+	data := strategyMessage{
+		Strategy:  c.Strategy,
+		Passed:    c.Passed,
+		Count:     c.Count,
+		Threshold: c.Threshold,
+		Remaining: c.Remaining,
+		Margin:    c.Count - c.Threshold,
+		Start:     c.Start,
+		End:       c.End,
+		Metadata:  c.Metadata,
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		return nil, fmt.Errorf("render %s template: %w", c.Strategy, err)
+	}
+
+	if c.Split != nil {
+		fmt.Fprintf(&message, "; the tax year is split, from %s to %s",
+			boundaryPhrase(c.Split.StartBoundary, "start"), boundaryPhrase(c.Split.EndBoundary, "end"))
+	}
+
+	return &domain.Explanation{
+		Outcome: domain.OutcomeOf(c),
+		Message: message.String(),
+	}, nil
+}
+
+func (x *explainer) explainCondition(c *domain.ConditionEvaluation) *domain.Explanation {
+	prompt := string(c.ConditionID)
+	if condition, ok := x.conditions[c.ConditionID]; ok && condition.Prompt != "" {
+		prompt = condition.Prompt
+	}
+
+	var message string
+	switch c.Status {
+	case domain.EvaluationStatusUnanswered:
+		message = fmt.Sprintf("%s Not answered yet; the answer %s %v", prompt, domain.ComparatorPhrase(c.Comparator), formatValue(c.Expected))
+	case domain.EvaluationStatusError:
+		message = fmt.Sprintf("%s The answer could not be checked: %s", prompt, c.Reason)
+	default:
+		message = fmt.Sprintf("%s You answered %v; the answer %s %v", prompt, formatValue(c.Actual), domain.ComparatorPhrase(c.Comparator), formatValue(c.Expected))
+	}
+
+	return &domain.Explanation{
+		Outcome: domain.OutcomeOf(c),
+		Message: message,
+	}
+}
+
+func (x *explainer) explainReference(c *domain.ReferenceEvaluation) (*domain.Explanation, error) {
+	name := string(c.RuleID)
+	if rule, ok := x.rules[c.RuleID]; ok && rule.Name != "" {
+		name = rule.Name
+	}
+
+	explanation := &domain.Explanation{
+		Outcome: domain.OutcomeOf(c),
+	}
+
+	if c.Component == nil {
+		explanation.Message = fmt.Sprintf("%s could not be checked: %s", name, c.Reason)
+		return explanation, nil
+	}
+
+	child, err := x.explainComponent(c.Component)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation.Message = fmt.Sprintf("%s must be met; it is %s", name, outcomePhrase(explanation.Outcome))
+	explanation.Children = []*domain.Explanation{child}
+
+	return explanation, nil
+}
+
+// boundaryPhrase describes a split-year boundary, where edge is the start or end of the tax year.
+func boundaryPhrase(b domain.PeriodBoundary, edge string) string {
+	switch b {
+	case domain.PeriodBoundaryArrival:
+		return "your arrival"
+	case domain.PeriodBoundaryDeparture:
+		return "your departure"
+	case domain.PeriodBoundaryCondition:
+		return "the date you gave"
+	default:
+		return "the " + edge + " of the tax year"
+	}
+}
+
+func outcomePhrase(o domain.Outcome) string {
+	switch o {
+	case domain.OutcomePassed:
+		return "met"
+	case domain.OutcomeFailed:
+		return "not met"
+	case domain.OutcomeError:
+		return "could not be checked"
+	default:
+		return "undecided until questions are answered"
+	}
+}
+
+// formatValue renders an answer or expected value, with booleans as yes or no.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case []any:
+		values := make([]string, len(v))
+		for i, value := range v {
+			values[i] = formatValue(value)
+		}
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
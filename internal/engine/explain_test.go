@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestExplain(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+	at := time.Date(2026, time.April, 5, 0, 0, 0, 0, time.UTC)
+
+	region := testRegion("GB")
+	region.YearStartMonth = time.April
+	region.YearStartDay = 6
+
+	ctx := &domain.EvaluationContext{
+		At:        at,
+		Region:    region,
+		Presences: testPresences("GB", time.Date(2025, time.April, 6, 0, 0, 0, 0, time.UTC), 201),
+		Rules: []*domain.Rule{
+			{
+				ID:          "GB_183_DAY",
+				RegionID:    "GB",
+				Name:        "183 day test",
+				Description: "Resident when present more than 183 days in the tax year",
+				Node:        strategyNode(t, "aggregate", yearPeriod, `{"threshold":183}`),
+			},
+			{
+				ID:       "GB_HOME",
+				RegionID: "GB",
+				Name:     "Home test",
+				Node: compositeNode(t, domain.NodeTypeCompositeAnd, []domain.RuleNode{
+					conditionNode(t, "GB_HAS_HOME", true),
+					conditionNode(t, "GB_LIVES_HOME", true),
+				}),
+			},
+			{
+				ID:       "GB_RESIDENT",
+				RegionID: "GB",
+				Name:     "Resident",
+				Node:     ruleRefNode(t, "GB_183_DAY"),
+			},
+		},
+		Conditions: map[domain.Code]*domain.Condition{
+			"GB_HAS_HOME":   {ID: "GB_HAS_HOME", Prompt: "Do you have a home in the UK?", Type: domain.ConditionTypeBoolean},
+			"GB_LIVES_HOME": {ID: "GB_LIVES_HOME", Prompt: "Do you live in your UK home?", Type: domain.ConditionTypeBoolean},
+		},
+		Answers: map[domain.Code]*domain.Answer{
+			"GB_HAS_HOME": {ConditionID: "GB_HAS_HOME", Value: false},
+		},
+	}
+
+	engine := NewEngine()
+
+	evaluation, err := engine.EvaluateRegion(ctx)
+	require.NoError(t, err)
+
+	explanations, err := engine.Explain(ctx, evaluation)
+	require.NoError(t, err)
+
+	presence := &domain.Explanation{
+		Outcome: domain.OutcomePassed,
+		Message: "You were present 201 days between 2025-04-06 and 2026-04-05; the threshold is 183; passed by 18 days",
+	}
+
+	require.Equal(t, []*domain.Explanation{
+		{
+			RuleID:      "GB_183_DAY",
+			Name:        "183 day test",
+			Description: "Resident when present more than 183 days in the tax year",
+			Outcome:     presence.Outcome,
+			Message:     presence.Message,
+		},
+		{
+			RuleID:  "GB_HOME",
+			Name:    "Home test",
+			Outcome: domain.OutcomeFailed,
+			Message: "All of 2 requirements must be met; 0 met",
+			Children: []*domain.Explanation{
+				{Outcome: domain.OutcomeFailed, Message: "Do you have a home in the UK? You answered no; the answer must be yes"},
+				{Outcome: domain.OutcomeIndeterminate, Message: "Do you live in your UK home? Not answered yet; the answer must be yes"},
+			},
+		},
+		{
+			RuleID:   "GB_RESIDENT",
+			Name:     "Resident",
+			Outcome:  domain.OutcomePassed,
+			Message:  "183 day test must be met; it is met",
+			Children: []*domain.Explanation{presence},
+		},
+	}, explanations)
+}
+
+func TestExplainRegisteredTemplate(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+
+	ctx := &domain.EvaluationContext{
+		At:        testAt,
+		Region:    testRegion("JE"),
+		Presences: testPresences("JE", time.Date(testAt.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), 10),
+		Rules: []*domain.Rule{
+			{ID: "JE_CUSTOM", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":20}`)},
+		},
+	}
+
+	engine := NewEngine()
+	require.NoError(t, engine.RegisterTemplate("aggregate", "{{days .Count}} of {{.Threshold}}"))
+	require.Error(t, engine.RegisterTemplate("aggregate", "{{.Count"))
+
+	evaluation, err := engine.EvaluateRegion(ctx)
+	require.NoError(t, err)
+
+	explanations, err := engine.Explain(ctx, evaluation)
+	require.NoError(t, err)
+	require.Equal(t, "10 days of 20", explanations[0].Message)
+}
+
+func TestExplainSplitYear(t *testing.T) {
+	splitPeriod := domain.Period{Type: domain.PeriodTypeSplitYear, SplitStart: domain.PeriodBoundaryArrival}
+
+	ctx := &domain.EvaluationContext{
+		At:        testAt,
+		Region:    testRegion("GB"),
+		Presences: testPresences("GB", time.Date(testAt.Year(), time.May, 1, 0, 0, 0, 0, time.UTC), 10),
+		Rules: []*domain.Rule{
+			{ID: "GB_SPLIT", RegionID: "GB", Node: strategyNode(t, "aggregate", splitPeriod, `{"threshold":20}`)},
+		},
+	}
+
+	engine := NewEngine()
+
+	evaluation, err := engine.EvaluateRegion(ctx)
+	require.NoError(t, err)
+
+	explanations, err := engine.Explain(ctx, evaluation)
+	require.NoError(t, err)
+	require.Equal(t, "You were present 10 days between 2025-05-01 and 2025-12-31; the threshold is 20; 10 days remaining; "+
+		"the tax year is split, from your arrival to the end of the tax year", explanations[0].Message)
+}
@@ -0,0 +1,326 @@
+// Package expr implements a small, sandboxed expression language used by rule authors to define
+// strategies in seed data. Expressions combine numbers and booleans with arithmetic, comparison and
+// logical operators, and can only read the variables and call the functions they are compiled with.
+// There are no loops, assignments or side effects, and expressions are type checked when compiled.
+package expr
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// maxLength caps the length of an expression source.
+	maxLength = 1024
+	// maxDepth caps how deeply an expression can nest.
+	maxDepth = 32
+)
+
+// Declarations are the variables and functions an expression may reference. Every variable and
+// function argument is a number.
+type Declarations struct {
+	Vars  []string
+	Funcs map[string]Func
+}
+
+func (d Declarations) hasVar(name string) bool {
+	for _, v := range d.Vars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Func declares a function that returns a number.
+type Func struct {
+	Args int
+	// Constant requires every argument to be a number literal, so the arguments can be inspected
+	// when the expression is compiled.
+	Constant bool
+}
+
+// Env resolves variables and function calls while an expression is evaluated.
+type Env interface {
+	Var(name string) float64
+	Call(name string, args []float64) (float64, error)
+}
+
+// Program is a compiled expression.
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses and type checks an expression, which must evaluate to a boolean.
+func Compile(src string, decl Declarations) (*Program, error) {
+	if src == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+
+	if len(src) > maxLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxLength)
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, decl: decl}
+
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+
+	if root.kind() != kindBool {
+		return nil, fmt.Errorf("expression must evaluate to a boolean")
+	}
+
+	return &Program{source: src, root: root}, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval evaluates the program against the environment.
+func (p *Program) Eval(env Env) (bool, error) {
+	v, err := p.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	return v.(bool), nil
+}
+
+// ConstArgs returns the arguments of every call to the function in the program. It is only
+// meaningful for functions declared with constant arguments.
+func (p *Program) ConstArgs(name string) [][]float64 {
+	var (
+		args [][]float64
+		walk func(n node)
+	)
+
+	walk = func(n node) {
+		switch v := n.(type) {
+		case *call:
+			if v.name == name {
+				values := make([]float64, len(v.args))
+				for i, a := range v.args {
+					if l, ok := a.(*literal); ok {
+						values[i], _ = l.value.(float64)
+					}
+				}
+				args = append(args, values)
+			}
+			for _, a := range v.args {
+				walk(a)
+			}
+		case *unary:
+			walk(v.operand)
+		case *binary:
+			walk(v.left)
+			walk(v.right)
+		}
+	}
+
+	walk(p.root)
+
+	return args
+}
+
+type kind int
+
+const (
+	kindNumber kind = iota
+	kindBool
+)
+
+type node interface {
+	kind() kind
+	eval(env Env) (any, error)
+}
+
+type literal struct {
+	value any
+	typ   kind
+}
+
+func (n *literal) kind() kind            { return n.typ }
+func (n *literal) eval(Env) (any, error) { return n.value, nil }
+
+type variable struct {
+	name string
+}
+
+func (n *variable) kind() kind                { return kindNumber }
+func (n *variable) eval(env Env) (any, error) { return env.Var(n.name), nil }
+
+type call struct {
+	name string
+	args []node
+}
+
+func (n *call) kind() kind { return kindNumber }
+
+func (n *call) eval(env Env) (any, error) {
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v.(float64)
+	}
+
+	v, err := env.Call(n.name, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+
+	return v, nil
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func newUnary(op token, operand node) (node, error) {
+	switch op.text {
+	case "!":
+		if operand.kind() != kindBool {
+			return nil, fmt.Errorf("operator ! at position %d requires a boolean", op.pos)
+		}
+	case "-":
+		if operand.kind() != kindNumber {
+			return nil, fmt.Errorf("operator - at position %d requires a number", op.pos)
+		}
+	}
+
+	return &unary{op: op.text, operand: operand}, nil
+}
+
+func (n *unary) kind() kind {
+	return n.operand.kind()
+}
+
+func (n *unary) eval(env Env) (any, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !v.(bool), nil
+	}
+	return -v.(float64), nil
+}
+
+type binary struct {
+	op          string
+	left, right node
+	result      kind
+}
+
+func newBinary(op token, left, right node) (node, error) {
+	var operands, result kind
+
+	switch op.text {
+	case "&&", "||":
+		operands, result = kindBool, kindBool
+	case "==", "!=":
+		if left.kind() != right.kind() {
+			return nil, fmt.Errorf("operator %s at position %d compares different types", op.text, op.pos)
+		}
+		operands, result = left.kind(), kindBool
+	case "<", "<=", ">", ">=":
+		operands, result = kindNumber, kindBool
+	default:
+		operands, result = kindNumber, kindNumber
+	}
+
+	if left.kind() != operands || right.kind() != operands {
+		name := "numbers"
+		if operands == kindBool {
+			name = "booleans"
+		}
+		return nil, fmt.Errorf("operator %s at position %d requires %s", op.text, op.pos, name)
+	}
+
+	return &binary{op: op.text, left: left, right: right, result: result}, nil
+}
+
+func (n *binary) kind() kind {
+	return n.result
+}
+
+func (n *binary) eval(env Env) (any, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short circuit
+	switch n.op {
+	case "&&":
+		if !l.(bool) {
+			return false, nil
+		}
+	case "||":
+		if l.(bool) {
+			return true, nil
+		}
+	}
+
+	r, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return r.(bool), nil
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	}
+
+	a, b := l.(float64), r.(float64)
+
+	switch n.op {
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	case ">=":
+		return a >= b, nil
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(a, b), nil
+	default:
+		return nil, fmt.Errorf("unknown operator %s", n.op)
+	}
+}
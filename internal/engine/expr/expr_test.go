@@ -0,0 +1,115 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testDecl = Declarations{
+	Vars: []string{"days", "streak"},
+	Funcs: map[string]Func{
+		"inYear": {Args: 1, Constant: true},
+		"max":    {Args: 2},
+	},
+}
+
+type testEnv map[string]float64
+
+func (e testEnv) Var(name string) float64 {
+	return e[name]
+}
+
+func (e testEnv) Call(name string, args []float64) (float64, error) {
+	switch name {
+	case "inYear":
+		return e[fmt.Sprintf("inYear%d", int(args[0]))], nil
+	case "max":
+		return max(args[0], args[1]), nil
+	default:
+		return 0, fmt.Errorf("unknown function")
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "comparison", src: "days >= 183"},
+		{name: "logical operators", src: "days > 90 && !(streak < 30) || false"},
+		{name: "function calls", src: "inYear(0) + inYear(1) / 3 >= max(days, 10)"},
+		{name: "empty", src: "", wantErr: "expression is empty"},
+		{name: "too long", src: strings.Repeat("days > 1 && ", 100) + "true", wantErr: "expression is longer than 1024 characters"},
+		{name: "too deep", src: strings.Repeat("(", 40) + "true" + strings.Repeat(")", 40), wantErr: "expression is nested deeper than 32 levels"},
+		{name: "not boolean", src: "days + 1", wantErr: "expression must evaluate to a boolean"},
+		{name: "unknown variable", src: "nights > 1", wantErr: "unknown variable nights at position 0"},
+		{name: "unknown function", src: "exec(1) > 1", wantErr: "unknown function exec at position 0"},
+		{name: "wrong argument count", src: "max(days) > 1", wantErr: "function max takes 2 arguments, got 1"},
+		{name: "constant argument", src: "inYear(days) > 1", wantErr: "function inYear takes constant arguments"},
+		{name: "type mismatch", src: "days && true", wantErr: "operator && at position 5 requires booleans"},
+		{name: "compare different types", src: "days == true", wantErr: "operator == at position 5 compares different types"},
+		{name: "unexpected character", src: "days > 1; true", wantErr: "unexpected character ';' at position 8"},
+		{name: "unbalanced parentheses", src: "(days > 1", wantErr: "expected ) at position 9"},
+		{name: "trailing tokens", src: "days > 1 2", wantErr: `unexpected "2" at position 9`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Compile(tc.src, testDecl)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	env := testEnv{"days": 120, "streak": 40, "inYear0": 120, "inYear1": 180, "inYear2": 90}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    bool
+		wantErr string
+	}{
+		{name: "comparison", src: "days >= 120", want: true},
+		{name: "precedence", src: "1 + 2 * 3 == 7", want: true},
+		{name: "unary minus", src: "-days < 0", want: true},
+		{name: "weighted years", src: "inYear(0) + inYear(1) / 3 + inYear(2) / 6 >= 183", want: true},
+		{name: "and", src: "days > 100 && streak > 50", want: false},
+		{name: "or", src: "days > 200 || streak >= 40", want: true},
+		{name: "short circuit", src: "false && 1 / 0 > 1", want: false},
+		{name: "modulo", src: "days % 7 == 1", want: true},
+		{name: "division by zero", src: "days / 0 > 1", wantErr: "division by zero"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := Compile(tc.src, testDecl)
+			require.NoError(t, err)
+
+			got, err := program.Eval(env)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConstArgs(t *testing.T) {
+	program, err := Compile("inYear(0) > 1 && max(inYear(2), 3) > 1", testDecl)
+	require.NoError(t, err)
+
+	require.Equal(t, [][]float64{{0}, {2}}, program.ConstArgs("inYear"))
+	require.Empty(t, program.ConstArgs("other"))
+}
@@ -0,0 +1,285 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits the source into tokens. Only numbers, identifiers, parentheses, commas and the
+// supported operators are accepted.
+func lex(src string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[start:i]), start})
+
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+
+		default:
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "&&", "||", "==", "!=", "<=", ">=":
+					tokens = append(tokens, token{tokenOperator, two, i})
+					i += 2
+					continue
+				}
+			}
+
+			switch r {
+			case '+', '-', '*', '/', '%', '<', '>', '!':
+				tokens = append(tokens, token{tokenOperator, string(r), i})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// parser is a recursive descent parser. Precedence from lowest to highest is
+// ||, &&, == !=, < <= > >=, + -, * / %, unary ! -.
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	decl   Declarations
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(ops ...string) (token, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return t, false
+	}
+
+	for _, op := range ops {
+		if t.text == op {
+			return p.next(), true
+		}
+	}
+
+	return t, false
+}
+
+func (p *parser) parseExpression() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested deeper than %d levels", maxDepth)
+	}
+
+	return p.parseBinary(0)
+}
+
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept(precedence[level]...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left, err = newBinary(op, left, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	op, ok := p.accept("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested deeper than %d levels", maxDepth)
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return newUnary(op, operand)
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return &literal{value: v, typ: kindNumber}, nil
+
+	case tokenLParen:
+		n, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", t.pos)
+		}
+		return n, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literal{value: true, typ: kindBool}, nil
+		case "false":
+			return &literal{value: false, typ: kindBool}, nil
+		}
+
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(t)
+		}
+
+		if !p.decl.hasVar(t.text) {
+			return nil, fmt.Errorf("unknown variable %s at position %d", t.text, t.pos)
+		}
+		return &variable{name: t.text}, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := p.decl.Funcs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", name.text, name.pos)
+	}
+
+	p.next() // (
+
+	var args []node
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			if arg.kind() != kindNumber {
+				return nil, fmt.Errorf("function %s takes number arguments", name.text)
+			}
+
+			if fn.Constant {
+				if _, ok := arg.(*literal); !ok {
+					return nil, fmt.Errorf("function %s takes constant arguments", name.text)
+				}
+			}
+
+			args = append(args, arg)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if t := p.next(); t.kind != tokenRParen {
+		return nil, fmt.Errorf("expected ) at position %d", t.pos)
+	}
+
+	if len(args) != fn.Args {
+		return nil, fmt.Errorf("function %s takes %d arguments, got %d", name.text, fn.Args, len(args))
+	}
+
+	return &call{name: name.text, args: args}, nil
+}
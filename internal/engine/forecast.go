@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// Forecast evaluates the region on every day from `from` to `until`, counting only presences up to
+// the day being evaluated, and reports the first day the region and each of its rules change outcome.
+func (e *Engine) Forecast(ctx *domain.EvaluationContext, from, until time.Time) (*domain.Forecast, error) {
+	forecast := &domain.Forecast{
+		RegionID: ctx.Region.ID,
+		From:     from,
+		Until:    until,
+		Rules:    make([]*domain.RuleForecast, len(ctx.Rules)),
+	}
+
+	for i, rule := range ctx.Rules {
+		forecast.Rules[i] = &domain.RuleForecast{RuleID: rule.ID}
+	}
+
+	view := newAsOfView(ctx)
+
+	for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+		evaluation, err := e.EvaluateRegion(view.on(day))
+		if err != nil {
+			return nil, fmt.Errorf("evaluate region on %s: %w", day.Format(time.DateOnly), err)
+		}
+
+		if day.Equal(from) {
+			forecast.Outcome = evaluation.Outcome()
+			for i, node := range evaluation.Nodes {
+				forecast.Rules[i].Outcome = domain.OutcomeOf(node)
+			}
+			continue
+		}
+
+		pending := false
+
+		if forecast.ChangesOn == nil {
+			if outcome := evaluation.Outcome(); outcome != forecast.Outcome {
+				forecast.ChangesOn = &day
+				forecast.ChangesTo = outcome
+			} else {
+				pending = true
+			}
+		}
+
+		for i, node := range evaluation.Nodes {
+			rf := forecast.Rules[i]
+			if rf.ChangesOn != nil {
+				continue
+			}
+
+			if outcome := domain.OutcomeOf(node); outcome != rf.Outcome {
+				rf.ChangesOn = &day
+				rf.ChangesTo = outcome
+			} else {
+				pending = true
+			}
+		}
+
+		// Stop once the region and every rule have changed, later days cannot add anything
+		if !pending {
+			break
+		}
+	}
+
+	return forecast, nil
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestForecast(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	// 150 days logged this year, and the user stays from the first day of the forecast
+	presences := testPresences("JE", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 150)
+	presences = append(presences, testPresences("JE", from, 60)...)
+
+	ctx := &domain.EvaluationContext{
+		At:        from,
+		Region:    testRegion("JE"),
+		Presences: presences,
+		Rules: []*domain.Rule{
+			{ID: "JE_183_DAY", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":182}`)},
+			{ID: "JE_160_DAY", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":159}`)},
+		},
+	}
+
+	forecast, err := NewEngine().Forecast(ctx, from, from.AddDate(0, 0, 59))
+	require.NoError(t, err)
+
+	date := func(days int) *time.Time {
+		d := from.AddDate(0, 0, days)
+		return &d
+	}
+
+	// The first day of the forecast is the 151st day present, the region passes once every rule does
+	require.Equal(t, domain.OutcomeFailed, forecast.Outcome)
+	require.Equal(t, date(32), forecast.ChangesOn)
+	require.Equal(t, domain.OutcomePassed, forecast.ChangesTo)
+
+	require.Equal(t, []*domain.RuleForecast{
+		{RuleID: "JE_183_DAY", Outcome: domain.OutcomeFailed, ChangesOn: date(32), ChangesTo: domain.OutcomePassed},
+		{RuleID: "JE_160_DAY", Outcome: domain.OutcomeFailed, ChangesOn: date(9), ChangesTo: domain.OutcomePassed},
+	}, forecast.Rules)
+}
+
+func TestForecastIgnoresLaterPresences(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := &domain.EvaluationContext{
+		At:        from,
+		Region:    testRegion("JE"),
+		Presences: testPresences("JE", from.AddDate(0, 0, 10), 5),
+		Rules: []*domain.Rule{
+			{ID: "JE_3_DAY", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":2}`)},
+		},
+	}
+
+	forecast, err := NewEngine().Forecast(ctx, from, from.AddDate(0, 0, 30))
+	require.NoError(t, err)
+
+	changesOn := from.AddDate(0, 0, 12)
+	require.Equal(t, domain.OutcomeFailed, forecast.Outcome)
+	require.Equal(t, &changesOn, forecast.ChangesOn)
+}
@@ -1,32 +1,40 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/pumpkinlog/backend/internal/domain"
 )
 
+// ComputePeriod returns the first and last moment of the period containing at. Periods follow the
+// region's calendar, so at is read as a wall clock time in the region (see Region.LocalTime) and the
+// period is returned in the same UTC representation as presence dates.
 func ComputePeriod(at time.Time, region *domain.Region, period domain.Period) (time.Time, time.Time, error) {
-	switch period.Type {
-	case "year":
-		year := at.Year()
-		boundary := time.Date(year, region.YearStartMonth, region.YearStartDay, 0, 0, 0, 0, at.Location())
-
-		if at.Before(boundary) {
-			year--
-		}
+	at = wallClock(at)
 
+	switch period.Type {
+	case "year", "split_year":
+		// A split-year period is narrowed within a single tax year once presences are known
 		years := period.Years
-		if years <= 0 {
+		if years <= 0 || period.Type == domain.PeriodTypeSplitYear {
 			years = 1
 		}
 
-		finalYear := year - period.OffsetYears
-		startYear := finalYear - (years - 1)
+		start := taxYearStart(region, at)
+		for i := 0; i < period.OffsetYears; i++ {
+			start = taxYearStart(region, start.AddDate(0, 0, -1))
+		}
+		for i := 0; i > period.OffsetYears; i-- {
+			start = nextTaxYearStart(region, start)
+		}
 
-		start := time.Date(startYear, region.YearStartMonth, region.YearStartDay, 0, 0, 0, 0, at.Location())
-		end := time.Date(finalYear, region.YearStartMonth, region.YearStartDay, 0, 0, 0, 0, at.Location()).AddDate(1, 0, 0).Add(-time.Second)
+		end := nextTaxYearStart(region, start).Add(-time.Second)
+
+		for i := 1; i < years; i++ {
+			start = taxYearStart(region, start.AddDate(0, 0, -1))
+		}
 
 		return start, end, nil
 
@@ -35,37 +43,211 @@ func ComputePeriod(at time.Time, region *domain.Region, period domain.Period) (t
 		end := at.Truncate(24*time.Hour).AddDate(0, 0, 1).Add(-time.Second)
 		return start, end, nil
 
+	case "month":
+		start := time.Date(at.Year(), at.Month()-time.Month(period.Offset), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0).Add(-time.Second), nil
+
+	case "quarter":
+		quarter := (int(at.Month()) - 1) / 3
+		start := time.Date(at.Year(), time.Month(3*(quarter-period.Offset)+1), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, 0).Add(-time.Second), nil
+
+	case "full_months":
+		end := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(0, -period.Months, 0), end.Add(-time.Second), nil
+
 	default:
 		return time.Time{}, time.Time{}, fmt.Errorf("unsupported period type: %s", period.Type)
 	}
 }
 
-func ComputeMaxPeriod(at time.Time, region *domain.Region, rules []*domain.Rule) (time.Time, time.Time, error) {
+// wallClock returns the wall clock time of t expressed in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// taxYearStart returns the start of the region's tax year containing t, following any changes to
+// the year start in the region's fiscal calendar.
+func taxYearStart(region *domain.Region, t time.Time) time.Time {
+	month, day, from := region.YearStartAt(t)
+
+	start := time.Date(t.Year(), month, day, 0, 0, 0, 0, time.UTC)
+	if t.Before(start) {
+		start = time.Date(t.Year()-1, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	// The first tax year under a new year start begins on the day it took effect
+	if start.Before(from) {
+		start = from.UTC()
+	}
+
+	return start
+}
+
+// nextTaxYearStart returns the start of the tax year following the one starting at start.
+func nextTaxYearStart(region *domain.Region, start time.Time) time.Time {
+	month, day, _ := region.YearStartAt(start)
+
+	next := time.Date(start.Year(), month, day, 0, 0, 0, 0, time.UTC)
+	if !next.After(start) {
+		next = time.Date(start.Year()+1, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	// A change to the year start cuts the tax year short
+	if change, ok := region.NextFiscalChange(start); ok && change.Before(next) {
+		next = change.UTC()
+	}
+
+	return next
+}
+
+// ComputeMaxPeriod returns the widest period covered by the strategies of the given rules,
+// including any days before a period start that a strategy needs to look back over. Strategies
+// nested anywhere in a rule's node tree are included.
+func (e *Engine) ComputeMaxPeriod(at time.Time, region *domain.Region, rules []*domain.Rule) (time.Time, time.Time, error) {
 	var minStart, maxEnd time.Time
 
 	for _, rule := range rules {
-		if rule.Node.Type != domain.NodeTypeStrategy {
-			continue
+		err := rule.Node.Walk(func(node domain.RuleNode) error {
+			if node.Type != domain.NodeTypeStrategy {
+				return nil
+			}
+
+			var sn domain.EvaluatorNode
+			if err := node.Unmarshal(&sn); err != nil {
+				return fmt.Errorf("cannot unmarshal strategy node: %w", err)
+			}
+
+			start, end, err := ComputePeriod(at, region, sn.Period)
+			if err != nil {
+				return fmt.Errorf("compute period: %w", err)
+			}
+
+			lookback, err := e.strategies.Lookback(sn.Type, sn.Props)
+			if err != nil {
+				return fmt.Errorf("strategy lookback: %w", err)
+			}
+
+			start = start.AddDate(0, 0, -lookback)
+
+			if minStart.IsZero() || start.Before(minStart) {
+				minStart = start
+			}
+
+			if end.After(maxEnd) {
+				maxEnd = end
+			}
+
+			return nil
+		})
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("rule %s: %w", rule.ID, err)
 		}
+	}
 
-		var sn domain.EvaluatorNode
-		if err := rule.Node.Unmarshal(&sn); err != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("cannot unmarshal strategy node for rule %s: %w", rule.ID, err)
+	return minStart, maxEnd, nil
+}
+
+// splitPeriod narrows the tax year of a split-year period to the boundaries derived from the days
+// counted in the region or from date answers. The returned split always reports the tax year, even
+// when the boundaries could not be derived.
+func splitPeriod(ctx *domain.EvaluationContext, sn domain.EvaluatorNode, yearStart, yearEnd time.Time) (time.Time, time.Time, *domain.PeriodSplit, error) {
+	split := &domain.PeriodSplit{
+		YearStart:     yearStart,
+		YearEnd:       yearEnd,
+		StartBoundary: sn.Period.StartBoundary(),
+		EndBoundary:   sn.Period.EndBoundary(),
+	}
+
+	var first, last time.Time
+	for _, p := range ctx.Presences {
+		if !sn.DayCount.Counts(p) || p.Date.Before(yearStart) || p.Date.After(yearEnd) {
+			continue
 		}
 
-		start, end, err := ComputePeriod(at, region, sn.Period)
-		if err != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("compute period for rule %s: %w", rule.ID, err)
+		if first.IsZero() || p.Date.Before(first) {
+			first = p.Date
 		}
+		if p.Date.After(last) {
+			last = p.Date
+		}
+	}
 
-		if minStart.IsZero() || start.Before(minStart) {
-			minStart = start
+	start, end := yearStart, yearEnd
+
+	switch split.StartBoundary {
+	case domain.PeriodBoundaryArrival:
+		// Without a day counted in the year there is no arrival, and the period stays whole
+		if !first.IsZero() {
+			start = first
+		}
+	case domain.PeriodBoundaryCondition:
+		date, err := boundaryDate(ctx, sn.Period.StartConditionID)
+		switch {
+		case errors.Is(err, errBoundaryUnanswered):
+			split.Unresolved = append(split.Unresolved, sn.Period.StartConditionID)
+		case err != nil:
+			return yearStart, yearEnd, split, err
+		default:
+			start = date
 		}
+	}
 
-		if end.After(maxEnd) {
-			maxEnd = end
+	switch split.EndBoundary {
+	case domain.PeriodBoundaryDeparture:
+		if !last.IsZero() {
+			end = endOfDay(last)
+		}
+	case domain.PeriodBoundaryCondition:
+		date, err := boundaryDate(ctx, sn.Period.EndConditionID)
+		switch {
+		case errors.Is(err, errBoundaryUnanswered):
+			split.Unresolved = append(split.Unresolved, sn.Period.EndConditionID)
+		case err != nil:
+			return yearStart, yearEnd, split, err
+		default:
+			end = endOfDay(date)
 		}
 	}
 
-	return minStart, maxEnd, nil
+	if start.Before(yearStart) || start.After(yearEnd) {
+		return yearStart, yearEnd, split, fmt.Errorf("start %s is outside the tax year", start.Format(time.DateOnly))
+	}
+
+	if end.Before(yearStart) || end.After(yearEnd) {
+		return yearStart, yearEnd, split, fmt.Errorf("end %s is outside the tax year", end.Format(time.DateOnly))
+	}
+
+	if end.Before(start) {
+		return yearStart, yearEnd, split, fmt.Errorf("end %s is before start %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+
+	return start, end, split, nil
+}
+
+// errBoundaryUnanswered is returned when a split-year boundary depends on an unanswered condition.
+var errBoundaryUnanswered = errors.New("boundary condition not answered")
+
+// boundaryDate returns the date answered to a date condition.
+func boundaryDate(ctx *domain.EvaluationContext, conditionID domain.Code) (time.Time, error) {
+	answer, ok := ctx.Answers[conditionID]
+	if !ok || answer.Value == nil {
+		return time.Time{}, errBoundaryUnanswered
+	}
+
+	value, ok := answer.Value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("condition %s answer %v is not a date", conditionID, answer.Value)
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("condition %s answer %q is not a date", conditionID, value)
+	}
+
+	return date, nil
+}
+
+func endOfDay(t time.Time) time.Time {
+	return t.AddDate(0, 0, 1).Add(-time.Second)
 }
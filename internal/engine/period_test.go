@@ -0,0 +1,490 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestComputePeriod(t *testing.T) {
+	gb := testRegion("GB")
+	gb.YearStartMonth = time.April
+	gb.YearStartDay = 6
+
+	// A region that moved its tax year from 1 April to 1 January in 2020, leaving a short 2019 year
+	moved := testRegion("XX")
+	moved.YearStartMonth = time.April
+	moved.YearStartDay = 1
+	moved.FiscalCalendar = []domain.FiscalYearStart{
+		{From: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), Month: time.January, Day: 1},
+	}
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	endOf := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 23, 59, 59, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		region    *domain.Region
+		period    domain.Period
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "tax year",
+			at:        testAt,
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 1},
+			wantStart: date(2025, time.April, 6),
+			wantEnd:   endOf(2026, time.April, 5),
+		},
+		{
+			name:      "previous tax years",
+			at:        testAt,
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 2, OffsetYears: 1},
+			wantStart: date(2023, time.April, 6),
+			wantEnd:   endOf(2025, time.April, 5),
+		},
+		{
+			name:      "short year before a fiscal calendar change",
+			at:        date(2019, time.June, 1),
+			region:    moved,
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 1},
+			wantStart: date(2019, time.April, 1),
+			wantEnd:   endOf(2019, time.December, 31),
+		},
+		{
+			name:      "first year after a fiscal calendar change",
+			at:        date(2020, time.June, 1),
+			region:    moved,
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 1},
+			wantStart: date(2020, time.January, 1),
+			wantEnd:   endOf(2020, time.December, 31),
+		},
+		{
+			name:      "years spanning a fiscal calendar change",
+			at:        date(2020, time.June, 1),
+			region:    moved,
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 3},
+			wantStart: date(2018, time.April, 1),
+			wantEnd:   endOf(2020, time.December, 31),
+		},
+		{
+			name:      "month",
+			at:        testAt,
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeMonth},
+			wantStart: date(2025, time.June, 1),
+			wantEnd:   endOf(2025, time.June, 30),
+		},
+		{
+			name:      "previous month across a year",
+			at:        date(2025, time.January, 15),
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeMonth, Offset: 1},
+			wantStart: date(2024, time.December, 1),
+			wantEnd:   endOf(2024, time.December, 31),
+		},
+		{
+			name:      "quarter",
+			at:        testAt,
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeQuarter},
+			wantStart: date(2025, time.April, 1),
+			wantEnd:   endOf(2025, time.June, 30),
+		},
+		{
+			name:      "previous quarter across a year",
+			at:        date(2025, time.February, 1),
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeQuarter, Offset: 1},
+			wantStart: date(2024, time.October, 1),
+			wantEnd:   endOf(2024, time.December, 31),
+		},
+		{
+			name:      "full months exclude the current month",
+			at:        testAt,
+			region:    gb,
+			period:    domain.Period{Type: domain.PeriodTypeFullMonths, Months: 12},
+			wantStart: date(2024, time.June, 1),
+			wantEnd:   endOf(2025, time.May, 31),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, err := ComputePeriod(tc.at, tc.region, tc.period)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStart, start)
+			require.Equal(t, tc.wantEnd, end)
+		})
+	}
+}
+
+func TestComputePeriodSplitYear(t *testing.T) {
+	region := testRegion("GB")
+	region.YearStartMonth = time.April
+	region.YearStartDay = 6
+
+	start, end, err := ComputePeriod(testAt, region, domain.Period{
+		Type:       domain.PeriodTypeSplitYear,
+		SplitStart: domain.PeriodBoundaryArrival,
+	})
+	require.NoError(t, err)
+
+	// The whole tax year is covered, so presences are loaded for any boundary within it
+	require.Equal(t, time.Date(2025, time.April, 6, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2026, time.April, 5, 23, 59, 59, 0, time.UTC), end)
+}
+
+func TestEvaluateSplitYear(t *testing.T) {
+	region := testRegion("GB")
+	region.YearStartMonth = time.April
+	region.YearStartDay = 6
+
+	yearStart := time.Date(2025, time.April, 6, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(2026, time.April, 5, 23, 59, 59, 0, time.UTC)
+	arrival := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	conditions := map[domain.Code]*domain.Condition{
+		"GB_ARRIVAL_DATE":   {ID: "GB_ARRIVAL_DATE", Type: domain.ConditionTypeDate},
+		"GB_DEPARTURE_DATE": {ID: "GB_DEPARTURE_DATE", Type: domain.ConditionTypeDate},
+	}
+
+	tests := []struct {
+		name       string
+		period     domain.Period
+		presences  []*domain.Presence
+		answers    map[domain.Code]*domain.Answer
+		wantStatus domain.EvaluationStatus
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantCount  int
+		wantSplit  *domain.PeriodSplit
+	}{
+		{
+			name:       "starts on arrival",
+			period:     domain.Period{Type: domain.PeriodTypeSplitYear, SplitStart: domain.PeriodBoundaryArrival},
+			presences:  testPresences("GB", arrival, 10),
+			wantStatus: domain.EvaluationStatusEvaluated,
+			wantStart:  arrival,
+			wantEnd:    yearEnd,
+			wantCount:  10,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryArrival,
+				EndBoundary:   domain.PeriodBoundaryYear,
+			},
+		},
+		{
+			name:       "ends on departure",
+			period:     domain.Period{Type: domain.PeriodTypeSplitYear, SplitEnd: domain.PeriodBoundaryDeparture},
+			presences:  testPresences("GB", arrival, 10),
+			wantStatus: domain.EvaluationStatusEvaluated,
+			wantStart:  yearStart,
+			wantEnd:    time.Date(2025, time.May, 10, 23, 59, 59, 0, time.UTC),
+			wantCount:  10,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryYear,
+				EndBoundary:   domain.PeriodBoundaryDeparture,
+			},
+		},
+		{
+			name:       "presences outside the tax year are ignored",
+			period:     domain.Period{Type: domain.PeriodTypeSplitYear, SplitStart: domain.PeriodBoundaryArrival},
+			presences:  append(testPresences("GB", yearStart.AddDate(0, 0, -3), 1), testPresences("GB", arrival, 2)...),
+			wantStatus: domain.EvaluationStatusEvaluated,
+			wantStart:  arrival,
+			wantEnd:    yearEnd,
+			wantCount:  2,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryArrival,
+				EndBoundary:   domain.PeriodBoundaryYear,
+			},
+		},
+		{
+			name:       "no arrival keeps the whole tax year",
+			period:     domain.Period{Type: domain.PeriodTypeSplitYear, SplitStart: domain.PeriodBoundaryArrival},
+			wantStatus: domain.EvaluationStatusEvaluated,
+			wantStart:  yearStart,
+			wantEnd:    yearEnd,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryArrival,
+				EndBoundary:   domain.PeriodBoundaryYear,
+			},
+		},
+		{
+			name: "bounded by answered dates",
+			period: domain.Period{
+				Type:             domain.PeriodTypeSplitYear,
+				SplitStart:       domain.PeriodBoundaryCondition,
+				StartConditionID: "GB_ARRIVAL_DATE",
+				SplitEnd:         domain.PeriodBoundaryCondition,
+				EndConditionID:   "GB_DEPARTURE_DATE",
+			},
+			presences: testPresences("GB", arrival, 10),
+			answers: map[domain.Code]*domain.Answer{
+				"GB_ARRIVAL_DATE":   {ConditionID: "GB_ARRIVAL_DATE", Value: "2025-05-03"},
+				"GB_DEPARTURE_DATE": {ConditionID: "GB_DEPARTURE_DATE", Value: "2025-05-06"},
+			},
+			wantStatus: domain.EvaluationStatusEvaluated,
+			wantStart:  time.Date(2025, time.May, 3, 0, 0, 0, 0, time.UTC),
+			wantEnd:    time.Date(2025, time.May, 6, 23, 59, 59, 0, time.UTC),
+			wantCount:  4,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryCondition,
+				EndBoundary:   domain.PeriodBoundaryCondition,
+			},
+		},
+		{
+			name: "unanswered date is indeterminate",
+			period: domain.Period{
+				Type:             domain.PeriodTypeSplitYear,
+				SplitStart:       domain.PeriodBoundaryCondition,
+				StartConditionID: "GB_ARRIVAL_DATE",
+			},
+			presences:  testPresences("GB", arrival, 10),
+			wantStatus: domain.EvaluationStatusUnanswered,
+			wantStart:  yearStart,
+			wantEnd:    yearEnd,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryCondition,
+				EndBoundary:   domain.PeriodBoundaryYear,
+				Unresolved:    []domain.Code{"GB_ARRIVAL_DATE"},
+			},
+		},
+		{
+			name: "answered date outside the tax year is an error",
+			period: domain.Period{
+				Type:             domain.PeriodTypeSplitYear,
+				SplitStart:       domain.PeriodBoundaryCondition,
+				StartConditionID: "GB_ARRIVAL_DATE",
+			},
+			answers: map[domain.Code]*domain.Answer{
+				"GB_ARRIVAL_DATE": {ConditionID: "GB_ARRIVAL_DATE", Value: "2024-05-03"},
+			},
+			wantStatus: domain.EvaluationStatusError,
+			wantStart:  yearStart,
+			wantEnd:    yearEnd,
+			wantSplit: &domain.PeriodSplit{
+				YearStart:     yearStart,
+				YearEnd:       yearEnd,
+				StartBoundary: domain.PeriodBoundaryCondition,
+				EndBoundary:   domain.PeriodBoundaryYear,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &domain.EvaluationContext{
+				At:         testAt,
+				Region:     region,
+				Presences:  tc.presences,
+				Rules:      []*domain.Rule{{ID: "GB_SPLIT", RegionID: "GB", Node: strategyNode(t, "aggregate", tc.period, `{"threshold":183}`)}},
+				Conditions: conditions,
+				Answers:    tc.answers,
+			}
+
+			evaluation, err := NewEngine().EvaluateRegion(ctx)
+			require.NoError(t, err)
+			require.Len(t, evaluation.Nodes, 1)
+
+			se, ok := evaluation.Nodes[0].(*domain.StrategyEvaluation)
+			require.True(t, ok)
+			require.Equal(t, tc.wantStatus, se.Status)
+			require.Equal(t, tc.wantStart, se.Start)
+			require.Equal(t, tc.wantEnd, se.End)
+			require.Equal(t, tc.wantCount, se.Count)
+			require.Equal(t, tc.wantSplit, se.Split)
+
+			switch tc.wantStatus {
+			case domain.EvaluationStatusUnanswered:
+				require.Equal(t, domain.EvaluationStatusIndeterminate, evaluation.Status)
+				require.Equal(t, tc.wantSplit.Unresolved, evaluation.Unresolved)
+			case domain.EvaluationStatusError:
+				require.Equal(t, domain.EvaluationStatusError, evaluation.Status)
+				require.Empty(t, evaluation.Unresolved)
+			}
+		})
+	}
+}
+
+func TestComputeMaxPeriod(t *testing.T) {
+	region := testRegion("JE")
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	yearEnd := time.Date(2025, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+	// Node trees are taken from the rules in seed.json
+	tests := []struct {
+		name      string
+		nodes     []string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "top-level strategy",
+			nodes:     []string{`{"type":"strategy","props":{"type":"aggregate","period":{"type":"year"},"props":{"threshold":183}}}`},
+			wantStart: date(2025, time.January, 1),
+			wantEnd:   yearEnd,
+		},
+		{
+			name: "strategy nested in an and node",
+			nodes: []string{`{"type":"and","props":[` +
+				`{"type":"condition","props":{"conditionId":"JE_MAINTAIN_ABODE","equals":false,"comparator":"eq"}},` +
+				`{"type":"strategy","props":{"type":"average","period":{"type":"year","years":4},"props":{"threshold":90}}}]}`},
+			wantStart: date(2022, time.January, 1),
+			wantEnd:   yearEnd,
+		},
+		{
+			name: "widest of several nested strategies",
+			nodes: []string{
+				`{"type":"and","props":[` +
+					`{"type":"condition","props":{"conditionId":"JE_MAINTAIN_ABODE","equals":true,"comparator":"eq"}},` +
+					`{"type":"strategy","props":{"type":"aggregate","period":{"type":"year"},"props":{"threshold":1}}}]}`,
+				`{"type":"and","props":[` +
+					`{"type":"strategy","props":{"type":"aggregate","period":{"type":"year"},"props":{"threshold":31}}},` +
+					`{"type":"strategy","props":{"type":"weighted","period":{"type":"year","years":3},"props":{"threshold":183,"weights":[1.0,0.3333333333,0.1666666667]}}}]}`,
+			},
+			wantStart: date(2023, time.January, 1),
+			wantEnd:   yearEnd,
+		},
+		{
+			name: "lookback of a deeply nested strategy",
+			nodes: []string{`{"type":"any","props":[` +
+				`{"type":"condition","props":{"conditionId":"JE_MAINTAIN_ABODE","equals":true,"comparator":"eq"}},` +
+				`{"type":"not","props":{"node":{"type":"atLeast","props":{"k":1,"nodes":[` +
+				`{"type":"strategy","props":{"type":"sliding","period":{"type":"year","years":1},"props":{"threshold":90,"windowDays":180}}}]}}}}]}`},
+			wantStart: date(2025, time.January, 1).AddDate(0, 0, -179),
+			wantEnd:   yearEnd,
+		},
+		{
+			name:  "conditions only",
+			nodes: []string{`{"type":"condition","props":{"conditionId":"JE_MAINTAIN_ABODE","equals":true,"comparator":"eq"}}`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := make([]*domain.Rule, len(tc.nodes))
+			for i, node := range tc.nodes {
+				rules[i] = &domain.Rule{ID: "JE_TEST", RegionID: "JE"}
+				require.NoError(t, json.Unmarshal([]byte(node), &rules[i].Node))
+			}
+
+			start, end, err := NewEngine().ComputeMaxPeriod(testAt, region, rules)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStart, start)
+			require.Equal(t, tc.wantEnd, end)
+		})
+	}
+}
+
+func TestComputePeriodRegionLocalTime(t *testing.T) {
+	london := testRegion("GB")
+	london.YearStartMonth = time.April
+	london.YearStartDay = 6
+	london.TimeZone = "Europe/London"
+
+	newYork := testRegion("US")
+	newYork.TimeZone = "America/New_York"
+
+	tests := []struct {
+		name      string
+		region    *domain.Region
+		instant   time.Time
+		period    domain.Period
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "new tax year starts at local midnight in summer time",
+			region:    london,
+			instant:   time.Date(2025, time.April, 5, 23, 30, 0, 0, time.UTC),
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 1},
+			wantStart: time.Date(2025, time.April, 6, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, time.April, 5, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:      "tax year continues until local midnight behind UTC",
+			region:    newYork,
+			instant:   time.Date(2026, time.January, 1, 3, 0, 0, 0, time.UTC),
+			period:    domain.Period{Type: domain.PeriodTypeYear, Years: 1},
+			wantStart: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, time.December, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:      "month after the clocks go back",
+			region:    newYork,
+			instant:   time.Date(2025, time.December, 1, 3, 0, 0, 0, time.UTC),
+			period:    domain.Period{Type: domain.PeriodTypeMonth},
+			wantStart: time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, time.November, 30, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:      "rolling days end on the local day after the clocks go forward",
+			region:    london,
+			instant:   time.Date(2025, time.March, 30, 23, 30, 0, 0, time.UTC),
+			period:    domain.Period{Type: domain.PeriodTypeRolling, RollingDays: 1},
+			wantStart: time.Date(2025, time.March, 30, 0, 30, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, time.March, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, err := ComputePeriod(tc.region.LocalTime(tc.instant), tc.region, tc.period)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStart, start)
+			require.Equal(t, tc.wantEnd, end)
+		})
+	}
+}
+
+func TestEvaluateRegionLocalDays(t *testing.T) {
+	region := testRegion("US")
+	region.TimeZone = "America/New_York"
+
+	// 22:00 on 30 November in New York is already 1 December in UTC
+	instant := time.Date(2025, time.December, 1, 3, 0, 0, 0, time.UTC)
+
+	ctx := &domain.EvaluationContext{
+		At:        region.LocalTime(instant),
+		Region:    region,
+		Presences: testPresences("US", time.Date(2025, time.November, 28, 0, 0, 0, 0, time.UTC), 3),
+		Rules: []*domain.Rule{
+			{ID: "US_MONTH", RegionID: "US", Node: strategyNode(t, "aggregate", domain.Period{Type: domain.PeriodTypeMonth}, `{"threshold":2}`)},
+		},
+	}
+
+	evaluation, err := NewEngine().EvaluateRegion(ctx)
+	require.NoError(t, err)
+
+	se, ok := evaluation.Nodes[0].(*domain.StrategyEvaluation)
+	require.True(t, ok)
+	require.Equal(t, 3, se.Count)
+	require.True(t, se.Passed)
+}
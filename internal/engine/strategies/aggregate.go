@@ -7,20 +7,23 @@ import (
 )
 
 // AggregateStrategy is a simple day-count threshold within a fixed period.
-type AggregateStrategy struct {
+type AggregateStrategy struct{}
+
+type aggregateConfig struct {
 	Threshold int `json:"threshold"`
 }
 
-func (s *AggregateStrategy) Evaluate(data []byte, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
-	if err := json.Unmarshal(data, &s); err != nil {
+func (s *AggregateStrategy) Evaluate(data []byte, _ Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+	var cfg aggregateConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return StrategyEvaluation{}, fmt.Errorf("invalid aggregate strategy config: %w", err)
 	}
 
 	count := len(presences)
-	remaining := s.Threshold - count
+	remaining := cfg.Threshold - count
 
 	return StrategyEvaluation{
-		Passed:    count > s.Threshold,
+		Passed:    count > cfg.Threshold,
 		Count:     count,
 		Remaining: max(remaining, 0),
 	}, nil
@@ -6,22 +6,25 @@ import (
 	"time"
 )
 
-type AverageStrategy struct {
+type AverageStrategy struct{}
+
+type averageConfig struct {
 	Threshold int `json:"threshold"`
 }
 
-func (s *AverageStrategy) Evaluate(data []byte, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
-	if err := json.Unmarshal(data, &s); err != nil {
+func (s *AverageStrategy) Evaluate(data []byte, _ Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+	var cfg averageConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return StrategyEvaluation{}, fmt.Errorf("invalid average strategy config: %w", err)
 	}
 
 	count := len(presences)
-	ratio := float64(count) / float64(s.Threshold)
+	ratio := float64(count) / float64(cfg.Threshold)
 
 	return StrategyEvaluation{
-		Passed:    count >= s.Threshold,
+		Passed:    count >= cfg.Threshold,
 		Count:     count,
-		Remaining: max(s.Threshold-count, 0),
+		Remaining: max(cfg.Threshold-count, 0),
 		Metadata: map[string]any{
 			"ratio": ratio,
 		},
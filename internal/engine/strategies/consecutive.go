@@ -1,12 +1,90 @@
 package strategies
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
 
-// ConsecutiveStrategy counts consecutive presences within a fixed period.
-type ConsecutiveStrategy struct {
+// ConsecutiveStrategy counts consecutive presences within a fixed period. The rule passes when the
+// longest run is more than threshold days, as with AggregateStrategy, so a threshold of 183 models
+// "more than 183 consecutive days". Remaining is the days still needed to pass, so it only reaches 0
+// once the rule passes.
+type ConsecutiveStrategy struct{}
+
+type consecutiveConfig struct {
 	Threshold int `json:"threshold"`
 }
 
-func (s *ConsecutiveStrategy) Evaluate(config []byte, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
-	return StrategyEvaluation{}, nil
+func (s *ConsecutiveStrategy) Evaluate(data []byte, period Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+	var cfg consecutiveConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return StrategyEvaluation{}, fmt.Errorf("invalid consecutive strategy config: %w", err)
+	}
+
+	dates := make([]time.Time, 0, len(presences))
+	for date := range presences {
+		dates = append(dates, date)
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+
+	// Walk the sorted dates tracking the run we are in and the longest seen so far
+	var (
+		longest, run             int
+		longestStart, longestEnd time.Time
+		runStart                 time.Time
+	)
+
+	for i, date := range dates {
+		if i == 0 || !dates[i-1].AddDate(0, 0, 1).Equal(date) {
+			run = 0
+			runStart = date
+		}
+
+		run++
+
+		if run > longest {
+			longest = run
+			longestStart = runStart
+			longestEnd = date
+		}
+	}
+
+	// The current streak is the run still ongoing at the point in time, which ends on that day
+	at := truncateDay(period.At)
+
+	var (
+		current      int
+		currentStart time.Time
+	)
+	for day := at; ; day = day.AddDate(0, 0, -1) {
+		if _, ok := presences[day]; !ok {
+			break
+		}
+		current++
+		currentStart = day
+	}
+
+	metadata := map[string]any{
+		"longestStreak": longest,
+		"currentStreak": current,
+	}
+
+	if longest > 0 {
+		metadata["longestStart"] = longestStart
+		metadata["longestEnd"] = longestEnd
+	}
+
+	if current > 0 {
+		metadata["currentStart"] = currentStart
+		metadata["currentEnd"] = at
+	}
+
+	return StrategyEvaluation{
+		Passed:    longest > cfg.Threshold,
+		Count:     longest,
+		Remaining: max(cfg.Threshold-longest+1, 0),
+		Metadata:  metadata,
+	}, nil
 }
@@ -0,0 +1,141 @@
+package strategies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func dateRange(start time.Time, days int) []time.Time {
+	dates := make([]time.Time, days)
+	for i := range days {
+		dates[i] = start.AddDate(0, 0, i)
+	}
+	return dates
+}
+
+func presenceSet(ranges ...[]time.Time) map[time.Time]struct{} {
+	presences := make(map[time.Time]struct{})
+	for _, r := range ranges {
+		for _, d := range r {
+			presences[d] = struct{}{}
+		}
+	}
+	return presences
+}
+
+func TestConsecutiveStrategy(t *testing.T) {
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		config        string
+		at            time.Time
+		presences     map[time.Time]struct{}
+		wantPassed    bool
+		wantCount     int
+		wantRemaining int
+		wantLongest   [2]time.Time
+		wantCurrent   int
+	}{
+		{
+			name:          "no presences",
+			config:        `{"threshold":10}`,
+			presences:     presenceSet(),
+			wantRemaining: 11,
+		},
+		{
+			name:        "single unbroken run exceeds threshold",
+			config:      `{"threshold":9}`,
+			at:          jan.AddDate(0, 0, 9),
+			presences:   presenceSet(dateRange(jan, 10)),
+			wantPassed:  true,
+			wantCount:   10,
+			wantLongest: [2]time.Time{jan, jan.AddDate(0, 0, 9)},
+			wantCurrent: 10,
+		},
+		{
+			name:          "run of exactly the threshold does not pass",
+			config:        `{"threshold":10}`,
+			at:            jan.AddDate(0, 0, 9),
+			presences:     presenceSet(dateRange(jan, 10)),
+			wantCount:     10,
+			wantRemaining: 1,
+			wantLongest:   [2]time.Time{jan, jan.AddDate(0, 0, 9)},
+			wantCurrent:   10,
+		},
+		{
+			name:          "longest run is not the most recent",
+			config:        `{"threshold":30}`,
+			at:            mar.AddDate(0, 0, 4),
+			presences:     presenceSet(dateRange(jan, 20), dateRange(mar, 5)),
+			wantCount:     20,
+			wantRemaining: 11,
+			wantLongest:   [2]time.Time{jan, jan.AddDate(0, 0, 19)},
+			wantCurrent:   5,
+		},
+		{
+			name:          "run that ended before the point in time is not current",
+			config:        `{"threshold":30}`,
+			at:            mar.AddDate(0, 1, 0),
+			presences:     presenceSet(dateRange(jan, 20), dateRange(mar, 5)),
+			wantCount:     20,
+			wantRemaining: 11,
+			wantLongest:   [2]time.Time{jan, jan.AddDate(0, 0, 19)},
+		},
+		{
+			name:          "current run is counted up to the point in time",
+			config:        `{"threshold":30}`,
+			at:            jan.AddDate(0, 0, 4),
+			presences:     presenceSet(dateRange(jan, 20)),
+			wantCount:     20,
+			wantRemaining: 11,
+			wantLongest:   [2]time.Time{jan, jan.AddDate(0, 0, 19)},
+			wantCurrent:   5,
+		},
+		{
+			name:          "gap of a single day breaks the run",
+			config:        `{"threshold":10}`,
+			at:            jan.AddDate(0, 0, 12),
+			presences:     presenceSet(dateRange(jan, 6), dateRange(jan.AddDate(0, 0, 7), 6)),
+			wantCount:     6,
+			wantRemaining: 5,
+			wantLongest:   [2]time.Time{jan, jan.AddDate(0, 0, 5)},
+			wantCurrent:   6,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &ConsecutiveStrategy{}
+			got, err := s.Evaluate([]byte(tc.config), Period{At: tc.at}, tc.presences)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantPassed, got.Passed)
+			require.Equal(t, tc.wantCount, got.Count)
+			require.Equal(t, tc.wantRemaining, got.Remaining)
+			require.Equal(t, tc.wantCount, got.Metadata["longestStreak"])
+			require.Equal(t, tc.wantCurrent, got.Metadata["currentStreak"])
+
+			if tc.wantCount > 0 {
+				require.Equal(t, tc.wantLongest[0], got.Metadata["longestStart"])
+				require.Equal(t, tc.wantLongest[1], got.Metadata["longestEnd"])
+			}
+
+			if tc.wantCurrent > 0 {
+				require.Equal(t, truncateDay(tc.at), got.Metadata["currentEnd"])
+				require.Equal(t, truncateDay(tc.at).AddDate(0, 0, 1-tc.wantCurrent), got.Metadata["currentStart"])
+			} else {
+				require.NotContains(t, got.Metadata, "currentStart")
+			}
+		})
+	}
+}
+
+func TestConsecutiveStrategyInvalidConfig(t *testing.T) {
+	s := &ConsecutiveStrategy{}
+	_, err := s.Evaluate([]byte(`{"threshold":"x"}`), Period{}, presenceSet())
+	require.Error(t, err)
+}
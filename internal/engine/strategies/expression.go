@@ -0,0 +1,239 @@
+package strategies
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/engine/expr"
+)
+
+const (
+	// maxExpressionLastDays caps the window of daysInLast so the lookback stays bounded.
+	maxExpressionLastDays = 3660
+	// maxExpressionYears caps how many years back daysInYear can look.
+	maxExpressionYears = 10
+)
+
+// expressionDeclarations are the presence facts an expression can read:
+//
+//   - days is the number of days present in the period.
+//   - periodDays is the length of the period in days.
+//   - longestStreak is the longest run of consecutive days present in the period.
+//   - currentStreak is the run of consecutive days present ending at the point in time.
+//   - daysInYear(n) is the number of days present in the nth year before the period end, where
+//     daysInYear(0) is the final year of the period.
+//   - daysInLast(n) is the number of days present in the n days ending at the point in time.
+//   - min(a, b) and max(a, b).
+var expressionDeclarations = expr.Declarations{
+	Vars: []string{"days", "periodDays", "longestStreak", "currentStreak"},
+	Funcs: map[string]expr.Func{
+		"daysInYear": {Args: 1, Constant: true},
+		"daysInLast": {Args: 1, Constant: true},
+		"min":        {Args: 2},
+		"max":        {Args: 2},
+	},
+}
+
+// programs caches compiled expressions by source, so an expression is compiled once when the rule
+// is validated rather than on every evaluation.
+var programs sync.Map
+
+// ExpressionStrategy evaluates a boolean expression over facts derived from presences, so rule
+// authors can add jurisdiction tests through seed data alone, e.g.
+// "daysInYear(0) + daysInYear(1) / 3 + daysInYear(2) / 6 >= 183".
+type ExpressionStrategy struct{}
+
+type expressionConfig struct {
+	Expression string `json:"expression"`
+	// Threshold is optional, and only used to report the days remaining until it is reached.
+	Threshold int `json:"threshold"`
+}
+
+func (s *ExpressionStrategy) Validate(data []byte) error {
+	_, _, err := s.compile(data)
+	return err
+}
+
+func (s *ExpressionStrategy) Lookback(data []byte) (int, error) {
+	_, program, err := s.compile(data)
+	if err != nil {
+		return 0, err
+	}
+
+	lookback := 0
+	for _, args := range program.ConstArgs("daysInLast") {
+		lookback = max(lookback, int(args[0])-1)
+	}
+
+	// daysInYear(n) reaches back n + 1 years from the period end, which is at most that many leap
+	// years before the period start less a day, however short the period is
+	for _, args := range program.ConstArgs("daysInYear") {
+		lookback = max(lookback, (int(args[0])+1)*366-1)
+	}
+
+	return lookback, nil
+}
+
+func (s *ExpressionStrategy) Evaluate(data []byte, period Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+	cfg, program, err := s.compile(data)
+	if err != nil {
+		return StrategyEvaluation{}, err
+	}
+
+	env := newExpressionEnv(period, presences)
+
+	passed, err := program.Eval(env)
+	if err != nil {
+		return StrategyEvaluation{}, fmt.Errorf("evaluate expression: %w", err)
+	}
+
+	remaining := 0
+	if cfg.Threshold > 0 {
+		remaining = max(cfg.Threshold-env.days, 0)
+	}
+
+	return StrategyEvaluation{
+		Passed:    passed,
+		Count:     env.days,
+		Remaining: remaining,
+		Metadata: map[string]any{
+			"expression":    cfg.Expression,
+			"days":          env.days,
+			"periodDays":    env.periodDays,
+			"longestStreak": env.longestStreak,
+			"currentStreak": env.currentStreak,
+		},
+	}, nil
+}
+
+func (s *ExpressionStrategy) compile(data []byte) (expressionConfig, *expr.Program, error) {
+	var cfg expressionConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, nil, fmt.Errorf("invalid expression strategy config: %w", err)
+	}
+
+	if cached, ok := programs.Load(cfg.Expression); ok {
+		return cfg, cached.(*expr.Program), nil
+	}
+
+	program, err := expr.Compile(cfg.Expression, expressionDeclarations)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("invalid expression strategy config: %w", err)
+	}
+
+	for _, args := range program.ConstArgs("daysInLast") {
+		if n := args[0]; n < 1 || n > maxExpressionLastDays || n != float64(int(n)) {
+			return cfg, nil, fmt.Errorf("invalid expression strategy config: daysInLast takes a whole number of days between 1 and %d", maxExpressionLastDays)
+		}
+	}
+
+	for _, args := range program.ConstArgs("daysInYear") {
+		if n := args[0]; n < 0 || n >= maxExpressionYears || n != float64(int(n)) {
+			return cfg, nil, fmt.Errorf("invalid expression strategy config: daysInYear takes a whole number of years between 0 and %d", maxExpressionYears-1)
+		}
+	}
+
+	programs.Store(cfg.Expression, program)
+
+	return cfg, program, nil
+}
+
+// expressionEnv exposes presence facts to an expression. Presences before the period start are
+// only used by daysInLast, and by daysInYear for years before the period.
+type expressionEnv struct {
+	at, start, end time.Time
+	dates          []time.Time
+
+	days          int
+	periodDays    int
+	longestStreak int
+	currentStreak int
+}
+
+func newExpressionEnv(period Period, presences map[time.Time]struct{}) *expressionEnv {
+	env := &expressionEnv{
+		at:    truncateDay(period.At),
+		start: truncateDay(period.Start),
+		end:   truncateDay(period.End),
+	}
+
+	env.periodDays = daysBetween(env.start, env.end) + 1
+
+	for date := range presences {
+		env.dates = append(env.dates, truncateDay(date))
+	}
+	slices.SortFunc(env.dates, func(a, b time.Time) int { return a.Compare(b) })
+
+	var current int
+	for i, date := range env.dates {
+		if date.Before(env.start) || date.After(env.end) {
+			continue
+		}
+
+		env.days++
+
+		if i == 0 || !env.dates[i-1].AddDate(0, 0, 1).Equal(date) || env.dates[i-1].Before(env.start) {
+			current = 0
+		}
+		current++
+		env.longestStreak = max(env.longestStreak, current)
+	}
+
+	// Walk back from the point in time while every day is present
+	for day := env.at; ; day = day.AddDate(0, 0, -1) {
+		if _, ok := slices.BinarySearchFunc(env.dates, day, func(a, b time.Time) int { return a.Compare(b) }); !ok {
+			break
+		}
+		env.currentStreak++
+	}
+
+	return env
+}
+
+// count returns the number of days present in the inclusive range [from, to].
+func (e *expressionEnv) count(from, to time.Time) int {
+	var n int
+	for _, date := range e.dates {
+		if !date.Before(from) && !date.After(to) {
+			n++
+		}
+	}
+	return n
+}
+
+func (e *expressionEnv) Var(name string) float64 {
+	switch name {
+	case "days":
+		return float64(e.days)
+	case "periodDays":
+		return float64(e.periodDays)
+	case "longestStreak":
+		return float64(e.longestStreak)
+	case "currentStreak":
+		return float64(e.currentStreak)
+	default:
+		return 0
+	}
+}
+
+func (e *expressionEnv) Call(name string, args []float64) (float64, error) {
+	switch name {
+	case "daysInYear":
+		n := int(args[0])
+		to := e.end.AddDate(-n, 0, 0)
+		from := to.AddDate(-1, 0, 1)
+		return float64(e.count(from, to)), nil
+	case "daysInLast":
+		n := int(args[0])
+		return float64(e.count(e.at.AddDate(0, 0, -(n-1)), e.at)), nil
+	case "min":
+		return min(args[0], args[1]), nil
+	case "max":
+		return max(args[0], args[1]), nil
+	default:
+		return 0, fmt.Errorf("unknown function")
+	}
+}
@@ -0,0 +1,131 @@
+package strategies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionStrategy(t *testing.T) {
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.December, 31, 23, 59, 59, 0, time.UTC)
+	at := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+	period := Period{At: at, Start: start, End: end}
+
+	// 60 days in 2023, 120 days in 2024 and the 100 days up to and including the point in time
+	presences := presenceSet(
+		dateRange(time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC), 60),
+		dateRange(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), 120),
+		dateRange(at.AddDate(0, 0, -99), 100),
+	)
+
+	tests := []struct {
+		name          string
+		config        string
+		wantPassed    bool
+		wantCount     int
+		wantRemaining int
+	}{
+		{
+			name:       "days in period",
+			config:     `{"expression":"days == 280"}`,
+			wantPassed: true,
+			wantCount:  280,
+		},
+		{
+			name:       "per year counts",
+			config:     `{"expression":"daysInYear(0) == 100 && daysInYear(1) == 120 && daysInYear(2) == 60"}`,
+			wantPassed: true,
+			wantCount:  280,
+		},
+		{
+			name:          "weighted years below threshold",
+			config:        `{"expression":"daysInYear(0) + daysInYear(1) / 3 + daysInYear(2) / 6 >= 183","threshold":300}`,
+			wantCount:     280,
+			wantRemaining: 20,
+		},
+		{
+			name:       "streaks",
+			config:     `{"expression":"longestStreak == 120 && currentStreak == 100"}`,
+			wantPassed: true,
+			wantCount:  280,
+		},
+		{
+			name:       "days in last n",
+			config:     `{"expression":"daysInLast(30) == 30 && daysInLast(365) == 100"}`,
+			wantPassed: true,
+			wantCount:  280,
+		},
+		{
+			name:       "period length",
+			config:     `{"expression":"periodDays == 1096"}`,
+			wantPassed: true,
+			wantCount:  280,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &ExpressionStrategy{}
+
+			se, err := s.Evaluate([]byte(tc.config), period, presences)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPassed, se.Passed)
+			require.Equal(t, tc.wantCount, se.Count)
+			require.Equal(t, tc.wantRemaining, se.Remaining)
+		})
+	}
+}
+
+func TestExpressionStrategyValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		wantErr      string
+		wantLookback int
+	}{
+		{
+			name:         "valid expression",
+			config:       `{"expression":"daysInLast(180) <= 90"}`,
+			wantLookback: 179,
+		},
+		{
+			name:         "days in year reaches back whole years",
+			config:       `{"expression":"daysInYear(0) + daysInYear(2) / 6 >= 183 && daysInLast(30) > 1"}`,
+			wantLookback: 3*366 - 1,
+		},
+		{
+			name:    "syntax error",
+			config:  `{"expression":"days >="}`,
+			wantErr: "invalid expression strategy config: unexpected end of expression",
+		},
+		{
+			name:    "days in last out of range",
+			config:  `{"expression":"daysInLast(0) > 1"}`,
+			wantErr: "invalid expression strategy config: daysInLast takes a whole number of days between 1 and 3660",
+		},
+		{
+			name:    "days in year out of range",
+			config:  `{"expression":"daysInYear(10) > 1"}`,
+			wantErr: "invalid expression strategy config: daysInYear takes a whole number of years between 0 and 9",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &ExpressionStrategy{}
+
+			err := s.Validate([]byte(tc.config))
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			lookback, err := s.Lookback([]byte(tc.config))
+			require.NoError(t, err)
+			require.Equal(t, tc.wantLookback, lookback)
+		})
+	}
+}
@@ -9,6 +9,8 @@ func (s *Strategies) RegisterDefaultStrategies() {
 	s.RegisterStrategy("average", &AverageStrategy{})
 	s.RegisterStrategy("weighted", &WeightedStrategy{})
 	s.RegisterStrategy("consecutive", &ConsecutiveStrategy{})
+	s.RegisterStrategy("sliding", &SlidingWindowStrategy{})
+	s.RegisterStrategy("expression", &ExpressionStrategy{})
 }
 
 func (s *Strategies) Strategy(rt string) (Strategy, error) {
@@ -0,0 +1,152 @@
+package strategies
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SlidingWindowStrategy caps the number of days present in any window of a fixed length,
+// such as the Schengen "90 days in any 180 day period" rule. Every window that ends inside
+// the evaluation period is checked, not only the window ending at the point in time.
+type SlidingWindowStrategy struct{}
+
+type slidingConfig struct {
+	Threshold  int `json:"threshold"`
+	WindowDays int `json:"windowDays"`
+}
+
+func (s *SlidingWindowStrategy) Validate(data []byte) error {
+	var cfg slidingConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("invalid sliding strategy config: %w", err)
+	}
+
+	if cfg.WindowDays <= 0 {
+		return fmt.Errorf("invalid sliding strategy config: window days must be greater than 0")
+	}
+
+	if cfg.Threshold < 0 {
+		return fmt.Errorf("invalid sliding strategy config: threshold cannot be negative")
+	}
+
+	return nil
+}
+
+func (s *SlidingWindowStrategy) Lookback(data []byte) (int, error) {
+	var cfg slidingConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return 0, fmt.Errorf("invalid sliding strategy config: %w", err)
+	}
+
+	return max(cfg.WindowDays-1, 0), nil
+}
+
+func (s *SlidingWindowStrategy) Evaluate(data []byte, period Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+	var cfg slidingConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return StrategyEvaluation{}, fmt.Errorf("invalid sliding strategy config: %w", err)
+	}
+
+	if cfg.WindowDays <= 0 {
+		return StrategyEvaluation{}, fmt.Errorf("invalid sliding strategy config: window days must be greater than 0")
+	}
+
+	at := truncateDay(period.At)
+	start := truncateDay(period.Start)
+	end := truncateDay(period.End)
+
+	// Index every day from the earliest window start to the latest day we may need to look at,
+	// so that window counts can be read from a prefix sum in constant time.
+	origin := start.AddDate(0, 0, -(cfg.WindowDays - 1))
+	last := end
+	if horizon := at.AddDate(0, 0, cfg.WindowDays+cfg.Threshold); horizon.After(last) {
+		last = horizon
+	}
+
+	days := daysBetween(origin, last) + 1
+	present := make([]bool, days)
+	for date := range presences {
+		if i := daysBetween(origin, date); i >= 0 && i < days {
+			present[i] = true
+		}
+	}
+
+	prefix := make([]int, days+1)
+	for i := range days {
+		prefix[i+1] = prefix[i]
+		if present[i] {
+			prefix[i+1]++
+		}
+	}
+
+	// count returns the number of presence days in the inclusive index range [from, to]
+	count := func(from, to int) int {
+		from = max(from, 0)
+		to = min(to, days-1)
+		if from > to {
+			return 0
+		}
+		return prefix[to+1] - prefix[from]
+	}
+
+	var (
+		worst                int
+		worstStart, worstEnd time.Time
+	)
+
+	for e := daysBetween(origin, start); e <= daysBetween(origin, end); e++ {
+		c := count(e-cfg.WindowDays+1, e)
+		if c > worst || worstEnd.IsZero() {
+			worst = c
+			worstEnd = origin.AddDate(0, 0, e)
+			worstStart = worstEnd.AddDate(0, 0, -(cfg.WindowDays - 1))
+		}
+	}
+
+	atIndex := daysBetween(origin, at)
+	used := count(atIndex-cfg.WindowDays+1, atIndex)
+	remaining := max(cfg.Threshold-used, 0)
+
+	// The earliest re-entry date for a full stay is the first day on which a continuous stay of
+	// threshold days would not exceed the cap in any window it touches. Presences on or after the
+	// candidate day are ignored as they would be replaced by the stay itself.
+	var reentry time.Time
+	for d := atIndex; d <= atIndex+cfg.WindowDays; d++ {
+		ok := true
+		for e := d; e < d+cfg.Threshold; e++ {
+			if count(e-cfg.WindowDays+1, d-1)+(e-d+1) > cfg.Threshold {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			reentry = origin.AddDate(0, 0, d)
+			break
+		}
+	}
+
+	return StrategyEvaluation{
+		Passed:    worst > cfg.Threshold,
+		Count:     worst,
+		Remaining: remaining,
+		Metadata: map[string]any{
+			"windowDays":       cfg.WindowDays,
+			"worstWindowStart": worstStart,
+			"worstWindowEnd":   worstEnd,
+			"worstWindowCount": worst,
+			"daysUsed":         used,
+			"daysRemaining":    remaining,
+			"reentryDate":      reentry,
+		},
+	}, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(truncateDay(to).Sub(truncateDay(from)).Hours() / 24)
+}
@@ -0,0 +1,165 @@
+package strategies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlidingWindowStrategy(t *testing.T) {
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	apr := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		config        string
+		period        Period
+		presences     map[time.Time]struct{}
+		wantPassed    bool
+		wantCount     int
+		wantRemaining int
+		wantWorstEnd  time.Time
+		wantReentry   time.Time
+	}{
+		{
+			name:   "no presences leaves full allowance",
+			config: `{"threshold":90,"windowDays":180}`,
+			period: Period{
+				At:    apr,
+				Start: apr.AddDate(0, 0, -180),
+				End:   apr,
+			},
+			presences:     presenceSet(),
+			wantRemaining: 90,
+			wantWorstEnd:  apr.AddDate(0, 0, -180),
+			wantReentry:   apr,
+		},
+		{
+			name:   "allowance used up exactly",
+			config: `{"threshold":90,"windowDays":180}`,
+			period: Period{
+				At:    apr,
+				Start: apr.AddDate(0, 0, -180),
+				End:   apr,
+			},
+			presences:    presenceSet(dateRange(jan, 90)),
+			wantCount:    90,
+			wantWorstEnd: jan.AddDate(0, 0, 89),
+			wantReentry:  jan.AddDate(0, 0, 180),
+		},
+		{
+			name:   "overstay in an earlier window",
+			config: `{"threshold":90,"windowDays":180}`,
+			period: Period{
+				At:    jan.AddDate(1, 0, 0),
+				Start: jan,
+				End:   jan.AddDate(1, 0, 0),
+			},
+			presences:     presenceSet(dateRange(jan, 100)),
+			wantPassed:    true,
+			wantCount:     100,
+			wantRemaining: 90,
+			wantWorstEnd:  jan.AddDate(0, 0, 99),
+			wantReentry:   jan.AddDate(1, 0, 0),
+		},
+		{
+			name:   "window starting before the period is counted",
+			config: `{"threshold":10,"windowDays":20}`,
+			period: Period{
+				At:    apr,
+				Start: apr,
+				End:   apr,
+			},
+			presences:    presenceSet(dateRange(apr.AddDate(0, 0, -9), 10)),
+			wantCount:    10,
+			wantWorstEnd: apr,
+			wantReentry:  apr.AddDate(0, 0, 11),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SlidingWindowStrategy{}
+			got, err := s.Evaluate([]byte(tc.config), tc.period, tc.presences)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantPassed, got.Passed)
+			require.Equal(t, tc.wantCount, got.Count)
+			require.Equal(t, tc.wantRemaining, got.Remaining)
+			require.Equal(t, tc.wantWorstEnd, got.Metadata["worstWindowEnd"])
+			require.Equal(t, tc.wantReentry, got.Metadata["reentryDate"])
+		})
+	}
+}
+
+func TestSlidingWindowStrategyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "valid config",
+			config: `{"threshold":90,"windowDays":180}`,
+		},
+		{
+			name:    "missing window days",
+			config:  `{"threshold":90}`,
+			wantErr: "invalid sliding strategy config: window days must be greater than 0",
+		},
+		{
+			name:    "negative window days",
+			config:  `{"threshold":90,"windowDays":-1}`,
+			wantErr: "invalid sliding strategy config: window days must be greater than 0",
+		},
+		{
+			name:    "negative threshold",
+			config:  `{"threshold":-1,"windowDays":180}`,
+			wantErr: "invalid sliding strategy config: threshold cannot be negative",
+		},
+		{
+			name:    "malformed config",
+			config:  `{"windowDays":"180"}`,
+			wantErr: "invalid sliding strategy config: json: cannot unmarshal string into Go struct field slidingConfig.windowDays of type int",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SlidingWindowStrategy{}
+
+			err := s.Validate([]byte(tc.config))
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSlidingWindowStrategyLookback(t *testing.T) {
+	s := &SlidingWindowStrategy{}
+	lookback, err := s.Lookback([]byte(`{"threshold":90,"windowDays":180}`))
+	require.NoError(t, err)
+	require.Equal(t, 179, lookback)
+}
+
+func TestSlidingWindowStrategyConfigNotShared(t *testing.T) {
+	apr := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	period := Period{At: apr, Start: apr, End: apr}
+
+	// Strategies are registered once and shared by every rule, so a config must not carry over
+	s := &SlidingWindowStrategy{}
+
+	_, err := s.Evaluate([]byte(`{"threshold":90,"windowDays":180}`), period, presenceSet())
+	require.NoError(t, err)
+
+	_, err = s.Evaluate([]byte(`{"threshold":90}`), period, presenceSet())
+	require.EqualError(t, err, "invalid sliding strategy config: window days must be greater than 0")
+
+	lookback, err := s.Lookback([]byte(`{"threshold":90}`))
+	require.NoError(t, err)
+	require.Equal(t, 0, lookback)
+}
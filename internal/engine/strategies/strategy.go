@@ -4,8 +4,30 @@ import (
 	"time"
 )
 
+// Strategy evaluates presences against a rule's strategy config. A strategy is registered once and
+// shared by every rule and concurrent evaluation, so it must decode its config per call rather than
+// into its own fields.
 type Strategy interface {
-	Evaluate(config []byte, presences map[time.Time]struct{}) (StrategyEvaluation, error)
+	Evaluate(config []byte, period Period, presences map[time.Time]struct{}) (StrategyEvaluation, error)
+}
+
+// LookbackStrategy is implemented by strategies that need presences from before the start of the
+// evaluation period, such as sliding windows that end inside the period but begin before it.
+type LookbackStrategy interface {
+	Lookback(config []byte) (int, error)
+}
+
+// ValidatingStrategy is implemented by strategies that can check their config ahead of evaluation,
+// so invalid rules are rejected when they are created or seeded.
+type ValidatingStrategy interface {
+	Validate(config []byte) error
+}
+
+// Period is the evaluation period a strategy is run against.
+type Period struct {
+	At    time.Time
+	Start time.Time
+	End   time.Time
 }
 
 type StrategyEvaluation struct {
@@ -27,13 +49,13 @@ func NewStrategies() *Strategies {
 	return s
 }
 
-func (s *Strategies) Evaluate(rt string, cfg []byte, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+func (s *Strategies) Evaluate(rt string, cfg []byte, period Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
 	strategy, err := s.Strategy(rt)
 	if err != nil {
 		return StrategyEvaluation{}, err
 	}
 
-	evaluation, err := strategy.Evaluate(cfg, presences)
+	evaluation, err := strategy.Evaluate(cfg, period, presences)
 	if err != nil {
 		return StrategyEvaluation{}, err
 	}
@@ -44,3 +66,33 @@ func (s *Strategies) Evaluate(rt string, cfg []byte, presences map[time.Time]str
 
 	return evaluation, nil
 }
+
+// Lookback returns the number of days before the period start the strategy needs presences for.
+func (s *Strategies) Lookback(rt string, cfg []byte) (int, error) {
+	strategy, err := s.Strategy(rt)
+	if err != nil {
+		return 0, err
+	}
+
+	ls, ok := strategy.(LookbackStrategy)
+	if !ok {
+		return 0, nil
+	}
+
+	return ls.Lookback(cfg)
+}
+
+// Validate checks that a strategy is registered for the rule type and that its config is valid.
+func (s *Strategies) Validate(rt string, cfg []byte) error {
+	strategy, err := s.Strategy(rt)
+	if err != nil {
+		return err
+	}
+
+	vs, ok := strategy.(ValidatingStrategy)
+	if !ok {
+		return nil
+	}
+
+	return vs.Validate(cfg)
+}
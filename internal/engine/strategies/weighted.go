@@ -7,13 +7,16 @@ import (
 )
 
 // WeightedStrategy is weighted count across a range of years.
-type WeightedStrategy struct {
+type WeightedStrategy struct{}
+
+type weightedConfig struct {
 	Threshold int       `json:"threshold"`
 	Weights   []float32 `json:"weights"` // 0 = current year, 1 = previous year etc
 }
 
-func (s *WeightedStrategy) Evaluate(data []byte, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
-	if err := json.Unmarshal(data, &s); err != nil {
+func (s *WeightedStrategy) Evaluate(data []byte, _ Period, presences map[time.Time]struct{}) (StrategyEvaluation, error) {
+	var cfg weightedConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return StrategyEvaluation{}, fmt.Errorf("invalid weighted strategy config: %w", err)
 	}
 
@@ -27,7 +30,7 @@ func (s *WeightedStrategy) Evaluate(data []byte, presences map[time.Time]struct{
 
 	// Initialize daysByOffset with zeros for all offsets from weights
 	daysByOffset := make(map[int]int)
-	for i := range s.Weights {
+	for i := range cfg.Weights {
 		daysByOffset[i] = 0
 	}
 
@@ -41,7 +44,7 @@ func (s *WeightedStrategy) Evaluate(data []byte, presences map[time.Time]struct{
 
 	// Calculate weighted total
 	var weightedTotal float32
-	for i, w := range s.Weights {
+	for i, w := range cfg.Weights {
 		count := daysByOffset[i]
 		weightedTotal += float32(count) * w
 	}
@@ -53,9 +56,9 @@ func (s *WeightedStrategy) Evaluate(data []byte, presences map[time.Time]struct{
 		daysByYear[year] = daysByOffset[offset]
 	}
 	return StrategyEvaluation{
-		Passed:    weightedTotal >= float32(s.Threshold),
+		Passed:    weightedTotal >= float32(cfg.Threshold),
 		Count:     int(weightedTotal),
-		Remaining: int(float32(s.Threshold) - weightedTotal),
+		Remaining: int(float32(cfg.Threshold) - weightedTotal),
 		Metadata: map[string]any{
 			"baseYear":      baseYear,
 			"daysByYear":    daysByYear,
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// Timeline evaluates the region across the range and merges the results into intervals of equal
+// outcome. Presences are loaded once and each day only counts presences up to and including it.
+func (e *Engine) Timeline(ctx *domain.EvaluationContext, from, until time.Time, step domain.TimelineStep) (*domain.Timeline, error) {
+	timeline := &domain.Timeline{
+		RegionID:  ctx.Region.ID,
+		From:      from,
+		Until:     until,
+		Step:      step,
+		Intervals: make([]*domain.TimelineInterval, 0),
+	}
+
+	view := newAsOfView(ctx)
+
+	var (
+		previous *domain.RegionEvaluation
+		current  *domain.TimelineInterval
+		start    = from
+	)
+
+	for _, day := range timelineDays(ctx.Region, from, until, step) {
+		evaluation, err := e.EvaluateRegion(view.on(day))
+		if err != nil {
+			return nil, fmt.Errorf("evaluate region on %s: %w", day.Format(time.DateOnly), err)
+		}
+
+		if outcome := evaluation.Outcome(); current == nil || outcome != current.Outcome {
+			current = &domain.TimelineInterval{
+				Start:   start,
+				End:     day,
+				Outcome: outcome,
+			}
+
+			if previous != nil {
+				current.Triggers = domain.NewRegionDiff(previous, evaluation, ctx.Rules).Rules
+			}
+
+			timeline.Intervals = append(timeline.Intervals, current)
+		} else {
+			current.End = day
+		}
+
+		previous = evaluation
+		start = day.AddDate(0, 0, 1)
+	}
+
+	return timeline, nil
+}
+
+// timelineDays returns the days a timeline evaluates the region on.
+func timelineDays(region *domain.Region, from, until time.Time, step domain.TimelineStep) []time.Time {
+	var days []time.Time
+
+	if step != domain.TimelineStepPeriod {
+		for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+			days = append(days, day)
+		}
+		return days
+	}
+
+	// The last day of each region year, e.g. 5 April for a year starting on 6 April
+	for start := nextTaxYearStart(region, taxYearStart(region, from)); ; start = nextTaxYearStart(region, start) {
+		day := start.AddDate(0, 0, -1)
+		if !day.Before(until) {
+			break
+		}
+		days = append(days, day)
+	}
+
+	return append(days, until)
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestTimeline(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	ctx := &domain.EvaluationContext{
+		At:        until,
+		Region:    testRegion("JE"),
+		Presences: testPresences("JE", from.AddDate(0, 0, 10), 5),
+		Rules: []*domain.Rule{
+			{ID: "JE_3_DAY", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":2}`)},
+		},
+	}
+
+	timeline, err := NewEngine().Timeline(ctx, from, until, domain.TimelineStepDay)
+	require.NoError(t, err)
+
+	// The third day present is 13 June
+	changesOn := time.Date(2025, time.June, 13, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, []*domain.TimelineInterval{
+		{Start: from, End: changesOn.AddDate(0, 0, -1), Outcome: domain.OutcomeFailed},
+		{
+			Start:   changesOn,
+			End:     until,
+			Outcome: domain.OutcomePassed,
+			Triggers: []*domain.RuleDiff{
+				{RuleID: "JE_3_DAY", Before: domain.OutcomeFailed, After: domain.OutcomePassed},
+			},
+		},
+	}, timeline.Intervals)
+}
+
+func TestTimelinePeriodStep(t *testing.T) {
+	yearPeriod := domain.Period{Type: domain.PeriodTypeYear, Years: 1}
+	from := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	ctx := &domain.EvaluationContext{
+		At:        until,
+		Region:    testRegion("JE"),
+		Presences: testPresences("JE", from.AddDate(0, 0, 10), 5),
+		Rules: []*domain.Rule{
+			{ID: "JE_3_DAY", RegionID: "JE", Node: strategyNode(t, "aggregate", yearPeriod, `{"threshold":2}`)},
+		},
+	}
+
+	timeline, err := NewEngine().Timeline(ctx, from, until, domain.TimelineStepPeriod)
+	require.NoError(t, err)
+
+	// Only the end of 2024 and the last day of the range are evaluated
+	require.Equal(t, []*domain.TimelineInterval{
+		{Start: from, End: yearEnd, Outcome: domain.OutcomePassed},
+		{
+			Start:   yearEnd.AddDate(0, 0, 1),
+			End:     until,
+			Outcome: domain.OutcomeFailed,
+			Triggers: []*domain.RuleDiff{
+				{RuleID: "JE_3_DAY", Before: domain.OutcomePassed, After: domain.OutcomeFailed},
+			},
+		},
+	}, timeline.Intervals)
+}
@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// EvaluateTreaty resolves the treaty residence of a user from the evaluations of both treaty
+// regions. A user resident in both regions is tie-broken by applying the treaty tests in order
+// until one favours a single region. A test that depends on unanswered conditions stops the
+// evaluation, as later tests only apply once earlier tests are undecided. A region or test that
+// cannot be evaluated at all, such as a test answered with the wrong type, errors the treaty.
+func (e *Engine) EvaluateTreaty(ctx *domain.TreatyContext) (*domain.TreatyEvaluation, error) {
+	treaty := ctx.Treaty
+
+	evaluation := &domain.TreatyEvaluation{
+		TreatyID:    treaty.ID,
+		Regions:     make(map[domain.RegionID]domain.Outcome, len(treaty.RegionIDs)),
+		Tests:       make([]*domain.TieBreakerResult, 0),
+		PointInTime: ctx.At,
+	}
+
+	var resident, indeterminate, errored []domain.RegionID
+
+	for _, regionID := range treaty.RegionIDs {
+		regionEvaluation, ok := ctx.Evaluations[regionID]
+		if !ok {
+			return nil, fmt.Errorf("missing evaluation for region %s", regionID)
+		}
+
+		outcome := regionEvaluation.Outcome()
+		evaluation.Regions[regionID] = outcome
+
+		switch outcome {
+		case domain.OutcomePassed:
+			resident = append(resident, regionID)
+		case domain.OutcomeIndeterminate:
+			indeterminate = append(indeterminate, regionID)
+			evaluation.Unresolved = append(evaluation.Unresolved, regionEvaluation.Unresolved...)
+		case domain.OutcomeError:
+			errored = append(errored, regionID)
+		}
+	}
+
+	switch {
+	case len(errored) > 0:
+		evaluation.Status = domain.TreatyStatusError
+		evaluation.Unresolved = nil
+		evaluation.Reason = fmt.Sprintf("residence in %s could not be evaluated", joinRegionIDs(errored))
+		return evaluation, nil
+	case len(indeterminate) > 0:
+		evaluation.Status = domain.TreatyStatusIndeterminate
+		evaluation.Reason = fmt.Sprintf("residence in %s depends on unanswered conditions", joinRegionIDs(indeterminate))
+		return evaluation, nil
+	case len(resident) == 0:
+		evaluation.Status = domain.TreatyStatusNone
+		evaluation.Reason = "not resident in either region"
+		return evaluation, nil
+	case len(resident) == 1:
+		evaluation.Status = domain.TreatyStatusSingle
+		evaluation.Residence = &resident[0]
+		evaluation.Reason = fmt.Sprintf("only resident in %s", resident[0])
+		return evaluation, nil
+	}
+
+	for _, test := range treaty.Tests {
+		result, unanswered, err := e.applyTieBreaker(ctx, test)
+		if err != nil {
+			return nil, fmt.Errorf("apply tie-breaker %s: %w", test.Name, err)
+		}
+
+		evaluation.Tests = append(evaluation.Tests, result)
+
+		switch {
+		case result.Status == domain.EvaluationStatusError:
+			evaluation.Status = domain.TreatyStatusError
+			evaluation.Reason = fmt.Sprintf("%s: %s", test.Name, result.Reason)
+			return evaluation, nil
+		case result.Status == domain.EvaluationStatusUnanswered:
+			evaluation.Status = domain.TreatyStatusIndeterminate
+			evaluation.Unresolved = unanswered
+			evaluation.Reason = fmt.Sprintf("%s: %s", test.Name, result.Reason)
+			return evaluation, nil
+		case result.Favours != nil:
+			evaluation.Status = domain.TreatyStatusResolved
+			evaluation.Residence = result.Favours
+			evaluation.DecidedBy = test.Name
+			evaluation.Reason = fmt.Sprintf("resident in both regions, %s decided by %s", *result.Favours, test.Name)
+			return evaluation, nil
+		}
+	}
+
+	evaluation.Status = domain.TreatyStatusUnresolved
+	evaluation.Reason = "resident in both regions and no tie-breaker decided, residence is settled by mutual agreement"
+
+	return evaluation, nil
+}
+
+// applyTieBreaker applies a single test, returning the unanswered conditions when the test could
+// not be applied.
+func (e *Engine) applyTieBreaker(ctx *domain.TreatyContext, test domain.TieBreaker) (*domain.TieBreakerResult, []domain.Code, error) {
+	switch test.Type {
+	case domain.TieBreakerTypeCondition:
+		result, unanswered := conditionTieBreaker(ctx, test)
+		return result, unanswered, nil
+	case domain.TieBreakerTypePresence:
+		return presenceTieBreaker(ctx, test), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported tie-breaker type: %s", test.Type)
+	}
+}
+
+func conditionTieBreaker(ctx *domain.TreatyContext, test domain.TieBreaker) (*domain.TieBreakerResult, []domain.Code) {
+	result := &domain.TieBreakerResult{
+		Name: test.Name,
+		Type: test.Type,
+	}
+
+	var (
+		unanswered []domain.Code
+		favoured   []domain.RegionID
+	)
+
+	for _, regionID := range ctx.Treaty.RegionIDs {
+		conditionID := test.Conditions[regionID]
+
+		answer, ok := ctx.Answers[conditionID]
+		if !ok || answer.Value == nil {
+			unanswered = append(unanswered, conditionID)
+			continue
+		}
+
+		// An answer of the wrong type is reported against the test, as no other answer resolves it
+		value, ok := answer.Value.(bool)
+		if !ok {
+			result.Status = domain.EvaluationStatusError
+			result.Reason = fmt.Sprintf("condition %s must be answered yes or no", conditionID)
+			return result, nil
+		}
+
+		if value {
+			favoured = append(favoured, regionID)
+		}
+	}
+
+	if len(unanswered) > 0 {
+		result.Status = domain.EvaluationStatusUnanswered
+		result.Reason = fmt.Sprintf("answer %s to apply", joinCodes(unanswered))
+		return result, unanswered
+	}
+
+	result.Status = domain.EvaluationStatusEvaluated
+
+	switch len(favoured) {
+	case 1:
+		result.Favours = &favoured[0]
+		result.Reason = fmt.Sprintf("applies only to %s", favoured[0])
+	case 0:
+		result.Reason = "applies to neither region"
+	default:
+		result.Reason = "applies to both regions"
+	}
+
+	return result, nil
+}
+
+func presenceTieBreaker(ctx *domain.TreatyContext, test domain.TieBreaker) *domain.TieBreakerResult {
+	at := time.Date(ctx.At.Year(), ctx.At.Month(), ctx.At.Day(), 0, 0, 0, 0, time.UTC)
+	from := at.AddDate(0, 0, -(test.Days - 1))
+
+	a, b := ctx.Treaty.RegionIDs[0], ctx.Treaty.RegionIDs[1]
+	days := map[domain.RegionID]int{
+		a: countDaysPresent(ctx, a, from, at),
+		b: countDaysPresent(ctx, b, from, at),
+	}
+
+	result := &domain.TieBreakerResult{
+		Name:   test.Name,
+		Type:   test.Type,
+		Status: domain.EvaluationStatusEvaluated,
+		Reason: fmt.Sprintf("%d days present in %s and %d in %s in the last %d days", days[a], a, days[b], b, test.Days),
+	}
+
+	switch {
+	case days[a] > days[b]:
+		result.Favours = &a
+	case days[b] > days[a]:
+		result.Favours = &b
+	}
+
+	return result
+}
+
+// countDaysPresent counts the distinct days present in the region, or any of its member regions,
+// within the inclusive range.
+func countDaysPresent(ctx *domain.TreatyContext, regionID domain.RegionID, from, to time.Time) int {
+	scope := map[domain.RegionID]struct{}{regionID: {}}
+	if region, ok := ctx.Regions[regionID]; ok {
+		for _, id := range region.RegionIDs() {
+			scope[id] = struct{}{}
+		}
+	}
+
+	dates := make(map[time.Time]struct{})
+	for _, p := range ctx.Presences {
+		if _, ok := scope[p.RegionID]; !ok {
+			continue
+		}
+
+		if !p.Date.Before(from) && !p.Date.After(to) {
+			dates[p.Date] = struct{}{}
+		}
+	}
+
+	return len(dates)
+}
+
+func joinRegionIDs(ids []domain.RegionID) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = string(id)
+	}
+	return strings.Join(s, " and ")
+}
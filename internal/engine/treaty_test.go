@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func testTreaty() *domain.Treaty {
+	return &domain.Treaty{
+		ID:        "JE_GG",
+		Name:      "Jersey and Guernsey arrangement",
+		RegionIDs: [2]domain.RegionID{"JE", "GG"},
+		Tests: []domain.TieBreaker{
+			{
+				Name:       "Permanent home",
+				Type:       domain.TieBreakerTypeCondition,
+				Conditions: map[domain.RegionID]domain.Code{"JE": "JE_PERMANENT_HOME", "GG": "GG_PERMANENT_HOME"},
+			},
+			{
+				Name: "Habitual abode",
+				Type: domain.TieBreakerTypePresence,
+				Days: 365,
+			},
+		},
+	}
+}
+
+func TestEvaluateTreaty(t *testing.T) {
+	at := time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)
+	je, gg := domain.RegionID("JE"), domain.RegionID("GG")
+
+	passed := &domain.RegionEvaluation{Passed: true, Status: domain.EvaluationStatusEvaluated}
+	failed := &domain.RegionEvaluation{Status: domain.EvaluationStatusEvaluated}
+	errored := &domain.RegionEvaluation{
+		Status: domain.EvaluationStatusError,
+		Reason: "error, JE_RESIDENT could not be evaluated",
+	}
+	indeterminate := &domain.RegionEvaluation{
+		Status:     domain.EvaluationStatusIndeterminate,
+		Unresolved: []domain.Code{"GG_LIVES"},
+	}
+
+	answer := func(value any) *domain.Answer {
+		return &domain.Answer{Value: value}
+	}
+
+	// 200 days in Jersey and 100 in Guernsey over the year
+	presences := append(
+		testPresences("JE", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 200),
+		testPresences("GG", time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC), 100)...,
+	)
+
+	tests := []struct {
+		name          string
+		evaluations   map[domain.RegionID]*domain.RegionEvaluation
+		answers       map[domain.Code]*domain.Answer
+		wantStatus    domain.TreatyStatus
+		wantResidence *domain.RegionID
+		wantDecidedBy string
+		wantTests     int
+		wantUnresolve []domain.Code
+		wantReason    string
+	}{
+		{
+			name:        "resident in neither region",
+			evaluations: map[domain.RegionID]*domain.RegionEvaluation{je: failed, gg: failed},
+			wantStatus:  domain.TreatyStatusNone,
+		},
+		{
+			name:          "resident in one region",
+			evaluations:   map[domain.RegionID]*domain.RegionEvaluation{je: failed, gg: passed},
+			wantStatus:    domain.TreatyStatusSingle,
+			wantResidence: &gg,
+		},
+		{
+			name:          "region residence indeterminate",
+			evaluations:   map[domain.RegionID]*domain.RegionEvaluation{je: passed, gg: indeterminate},
+			wantStatus:    domain.TreatyStatusIndeterminate,
+			wantUnresolve: []domain.Code{"GG_LIVES"},
+		},
+		{
+			name:        "decided by permanent home",
+			evaluations: map[domain.RegionID]*domain.RegionEvaluation{je: passed, gg: passed},
+			answers: map[domain.Code]*domain.Answer{
+				"JE_PERMANENT_HOME": answer(false),
+				"GG_PERMANENT_HOME": answer(true),
+			},
+			wantStatus:    domain.TreatyStatusResolved,
+			wantResidence: &gg,
+			wantDecidedBy: "Permanent home",
+			wantTests:     1,
+		},
+		{
+			name:        "permanent home in both, decided by habitual abode",
+			evaluations: map[domain.RegionID]*domain.RegionEvaluation{je: passed, gg: passed},
+			answers: map[domain.Code]*domain.Answer{
+				"JE_PERMANENT_HOME": answer(true),
+				"GG_PERMANENT_HOME": answer(true),
+			},
+			wantStatus:    domain.TreatyStatusResolved,
+			wantResidence: &je,
+			wantDecidedBy: "Habitual abode",
+			wantTests:     2,
+		},
+		{
+			name:        "permanent home unanswered",
+			evaluations: map[domain.RegionID]*domain.RegionEvaluation{je: passed, gg: passed},
+			answers: map[domain.Code]*domain.Answer{
+				"JE_PERMANENT_HOME": answer(true),
+			},
+			wantStatus:    domain.TreatyStatusIndeterminate,
+			wantTests:     1,
+			wantUnresolve: []domain.Code{"GG_PERMANENT_HOME"},
+		},
+		{
+			name:        "permanent home answered with wrong type",
+			evaluations: map[domain.RegionID]*domain.RegionEvaluation{je: passed, gg: passed},
+			answers: map[domain.Code]*domain.Answer{
+				"JE_PERMANENT_HOME": answer("yes"),
+				"GG_PERMANENT_HOME": answer(true),
+			},
+			wantStatus: domain.TreatyStatusError,
+			wantReason: "Permanent home: condition JE_PERMANENT_HOME must be answered yes or no",
+			wantTests:  1,
+		},
+		{
+			name:        "region residence errored",
+			evaluations: map[domain.RegionID]*domain.RegionEvaluation{je: errored, gg: indeterminate},
+			wantStatus:  domain.TreatyStatusError,
+			wantReason:  "residence in JE could not be evaluated",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &domain.TreatyContext{
+				At:          at,
+				Treaty:      testTreaty(),
+				Evaluations: tc.evaluations,
+				Answers:     tc.answers,
+				Presences:   presences,
+			}
+
+			evaluation, err := NewEngine().EvaluateTreaty(ctx)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantStatus, evaluation.Status)
+			require.Equal(t, tc.wantResidence, evaluation.Residence)
+			require.Equal(t, tc.wantDecidedBy, evaluation.DecidedBy)
+			require.Len(t, evaluation.Tests, tc.wantTests)
+			require.Equal(t, tc.wantUnresolve, evaluation.Unresolved)
+			if tc.wantReason != "" {
+				require.Equal(t, tc.wantReason, evaluation.Reason)
+			}
+		})
+	}
+}
+
+func TestEvaluateTreatyUnresolved(t *testing.T) {
+	at := time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)
+	passed := &domain.RegionEvaluation{Passed: true, Status: domain.EvaluationStatusEvaluated}
+
+	// Equal days in both regions, and a permanent home in neither
+	ctx := &domain.TreatyContext{
+		At:     at,
+		Treaty: testTreaty(),
+		Evaluations: map[domain.RegionID]*domain.RegionEvaluation{
+			"JE": passed,
+			"GG": passed,
+		},
+		Answers: map[domain.Code]*domain.Answer{
+			"JE_PERMANENT_HOME": {Value: false},
+			"GG_PERMANENT_HOME": {Value: false},
+		},
+		Presences: append(
+			testPresences("JE", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 100),
+			testPresences("GG", time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), 100)...,
+		),
+	}
+
+	evaluation, err := NewEngine().EvaluateTreaty(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, domain.TreatyStatusUnresolved, evaluation.Status)
+	require.Nil(t, evaluation.Residence)
+	require.Equal(t, []*domain.TieBreakerResult{
+		{Name: "Permanent home", Type: domain.TieBreakerTypeCondition, Status: domain.EvaluationStatusEvaluated, Reason: "applies to neither region"},
+		{Name: "Habitual abode", Type: domain.TieBreakerTypePresence, Status: domain.EvaluationStatusEvaluated, Reason: "100 days present in JE and 100 in GG in the last 365 days"},
+	}, evaluation.Tests)
+}
+
+func TestEvaluateTreatyMissingEvaluation(t *testing.T) {
+	ctx := &domain.TreatyContext{
+		At:          testAt,
+		Treaty:      testTreaty(),
+		Evaluations: map[domain.RegionID]*domain.RegionEvaluation{},
+	}
+
+	_, err := NewEngine().EvaluateTreaty(ctx)
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// ValidateRule checks every node of a rule, including the props of each node type and the strategy
+// nodes against the registered strategies, compiling any strategy config such as expressions ahead
+// of evaluation.
+func (e *Engine) ValidateRule(rule *domain.Rule) error {
+	return e.validateNode(rule.Node)
+}
+
+func (e *Engine) validateNode(node domain.RuleNode) error {
+	if err := node.Validate(); err != nil {
+		return err
+	}
+
+	switch node.Type {
+	case domain.NodeTypeStrategy:
+		var sn domain.EvaluatorNode
+		if err := node.Unmarshal(&sn); err != nil {
+			return domain.ValidationError("invalid strategy node: %s", err)
+		}
+
+		if err := sn.Validate(); err != nil {
+			return err
+		}
+
+		if err := e.strategies.Validate(sn.Type, sn.Props); err != nil {
+			return domain.ValidationError("invalid strategy %s: %s", sn.Type, err)
+		}
+
+		return nil
+
+	case domain.NodeTypeCondition:
+		var cn domain.ConditionNode
+		if err := node.Unmarshal(&cn); err != nil {
+			return domain.ValidationError("invalid condition node: %s", err)
+		}
+
+		return cn.Validate()
+
+	case domain.NodeTypeRule:
+		var rn domain.RuleRefNode
+		if err := node.Unmarshal(&rn); err != nil {
+			return domain.ValidationError("invalid rule node: %s", err)
+		}
+
+		return rn.Validate()
+
+	case domain.NodeTypeCompositeNot:
+		var nn domain.NotNode
+		if err := node.Unmarshal(&nn); err != nil {
+			return domain.ValidationError("invalid not node: %s", err)
+		}
+
+		if err := nn.Validate(); err != nil {
+			return err
+		}
+
+	case domain.NodeTypeCompositeAtLeast:
+		var tn domain.ThresholdNode
+		if err := node.Unmarshal(&tn); err != nil {
+			return domain.ValidationError("invalid atLeast node: %s", err)
+		}
+
+		if err := tn.Validate(); err != nil {
+			return err
+		}
+	}
+
+	children, err := node.Children()
+	if err != nil {
+		return domain.ValidationError("invalid %s node: %s", node.Type, err)
+	}
+
+	for _, child := range children {
+		if err := e.validateNode(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
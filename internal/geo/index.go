@@ -0,0 +1,158 @@
+// Package geo locates points in regions offline, using the region boundaries.
+package geo
+
+import (
+	"math"
+	"slices"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// cellSize is the size in degrees of the grid cells polygons are indexed by.
+const cellSize = 1.0
+
+type cell struct {
+	lat, lng int
+}
+
+type indexedPolygon struct {
+	regionID domain.RegionID
+	polygon  domain.Polygon
+	// min and max are the bounding box of the outer ring.
+	min, max [2]float64
+}
+
+// Index is a point-in-polygon index over region boundaries. Each polygon is listed under every
+// grid cell its bounding box overlaps, so a lookup only tests the polygons near the point.
+type Index struct {
+	polygons []indexedPolygon
+	cells    map[cell][]int
+	regions  map[domain.RegionID]*domain.Region
+	parents  map[domain.RegionID]domain.RegionID
+}
+
+// NewIndex indexes the boundaries of the regions. Zones are skipped, as a day present in a member
+// region already counts toward the zone.
+func NewIndex(regions []*domain.Region) *Index {
+	idx := &Index{
+		cells:   make(map[cell][]int),
+		regions: make(map[domain.RegionID]*domain.Region, len(regions)),
+		parents: make(map[domain.RegionID]domain.RegionID),
+	}
+
+	for _, region := range regions {
+		idx.regions[region.ID] = region
+
+		if region.ParentRegionID != nil {
+			idx.parents[region.ID] = *region.ParentRegionID
+		}
+
+		if region.Type == domain.RegionTypeZone {
+			continue
+		}
+
+		for _, polygon := range region.Boundary {
+			if len(polygon) == 0 || len(polygon[0]) == 0 {
+				continue
+			}
+
+			p := indexedPolygon{
+				regionID: region.ID,
+				polygon:  polygon,
+				min:      polygon[0][0],
+				max:      polygon[0][0],
+			}
+
+			for _, point := range polygon[0] {
+				p.min = [2]float64{math.Min(p.min[0], point[0]), math.Min(p.min[1], point[1])}
+				p.max = [2]float64{math.Max(p.max[0], point[0]), math.Max(p.max[1], point[1])}
+			}
+
+			i := len(idx.polygons)
+			idx.polygons = append(idx.polygons, p)
+
+			lo, hi := cellOf(p.min), cellOf(p.max)
+			for lat := lo.lat; lat <= hi.lat; lat++ {
+				for lng := lo.lng; lng <= hi.lng; lng++ {
+					c := cell{lat, lng}
+					idx.cells[c] = append(idx.cells[c], i)
+				}
+			}
+		}
+	}
+
+	return idx
+}
+
+// Locate returns the most specific regions containing the point, in ID order. A region is left out
+// when one of its descendants also contains the point, as presences roll up to ancestor regions.
+// Points on a shared border may be located in both regions.
+func (idx *Index) Locate(point [2]float64) []domain.RegionID {
+	found := make(map[domain.RegionID]struct{})
+
+	for _, i := range idx.cells[cellOf(point)] {
+		p := idx.polygons[i]
+
+		if point[0] < p.min[0] || point[0] > p.max[0] || point[1] < p.min[1] || point[1] > p.max[1] {
+			continue
+		}
+
+		if contains(p.polygon, point) {
+			found[p.regionID] = struct{}{}
+		}
+	}
+
+	for id := range found {
+		for parent, ok := idx.parents[id]; ok; parent, ok = idx.parents[parent] {
+			delete(found, parent)
+		}
+	}
+
+	regionIDs := make([]domain.RegionID, 0, len(found))
+	for id := range found {
+		regionIDs = append(regionIDs, id)
+	}
+	slices.Sort(regionIDs)
+
+	return regionIDs
+}
+
+func cellOf(point [2]float64) cell {
+	return cell{
+		lat: int(math.Floor(point[0] / cellSize)),
+		lng: int(math.Floor(point[1] / cellSize)),
+	}
+}
+
+// contains reports whether the point is inside the outer ring of the polygon and outside its holes.
+func contains(polygon domain.Polygon, point [2]float64) bool {
+	if !ringContains(polygon[0], point) {
+		return false
+	}
+
+	for _, hole := range polygon[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ringContains casts a ray from the point along its latitude and counts the ring edges it crosses.
+func ringContains(ring [][2]float64, point [2]float64) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+
+		if (a[0] > point[0]) != (b[0] > point[0]) {
+			lng := a[1] + (point[0]-a[0])*(b[1]-a[1])/(b[0]-a[0])
+			if point[1] < lng {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
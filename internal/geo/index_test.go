@@ -0,0 +1,117 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// square returns a polygon ring spanning the bounding box.
+func square(minLat, minLng, maxLat, maxLng float64) [][2]float64 {
+	return [][2]float64{{minLat, minLng}, {maxLat, minLng}, {maxLat, maxLng}, {minLat, maxLng}}
+}
+
+func TestIndexLocate(t *testing.T) {
+	us := domain.RegionID("US")
+
+	regions := []*domain.Region{
+		{
+			ID:       "JE",
+			Type:     domain.RegionTypeCountry,
+			Boundary: []domain.Polygon{{square(49.16, -2.26, 49.27, -2.00)}},
+		},
+		{
+			ID:   "GG",
+			Type: domain.RegionTypeCountry,
+			// Guernsey and Sark, with a hole for a lake
+			Boundary: []domain.Polygon{
+				{square(49.40, -2.68, 49.52, -2.50), square(49.45, -2.60, 49.46, -2.59)},
+				{square(49.41, -2.38, 49.45, -2.34)},
+			},
+		},
+		{
+			ID:       "US",
+			Type:     domain.RegionTypeCountry,
+			Boundary: []domain.Polygon{{square(25, -125, 49, -67)}},
+		},
+		{
+			ID:             "US-NY",
+			ParentRegionID: &us,
+			Type:           domain.RegionTypeProvince,
+			// A triangle, to test the ray crossings of a sloped edge
+			Boundary: []domain.Polygon{{{{40, -80}, {45, -80}, {40, -72}}}},
+		},
+		{
+			ID:              "CI",
+			Type:            domain.RegionTypeZone,
+			MemberRegionIDs: []domain.RegionID{"JE", "GG"},
+			Boundary:        []domain.Polygon{{square(49, -3, 50, -2)}},
+		},
+		{
+			ID:   "AQ",
+			Type: domain.RegionTypeCountry,
+		},
+	}
+
+	idx := NewIndex(regions)
+
+	tests := []struct {
+		name  string
+		point [2]float64
+		want  []domain.RegionID
+	}{
+		{
+			name:  "inside a region",
+			point: [2]float64{49.21, -2.13},
+			want:  []domain.RegionID{"JE"},
+		},
+		{
+			name:  "second polygon of a region",
+			point: [2]float64{49.43, -2.36},
+			want:  []domain.RegionID{"GG"},
+		},
+		{
+			name:  "inside a hole",
+			point: [2]float64{49.455, -2.595},
+			want:  []domain.RegionID{},
+		},
+		{
+			name:  "zones are not located",
+			point: [2]float64{49.9, -2.9},
+			want:  []domain.RegionID{},
+		},
+		{
+			name:  "most specific region",
+			point: [2]float64{41, -75},
+			want:  []domain.RegionID{"US-NY"},
+		},
+		{
+			name:  "outside a sloped edge falls back to the parent",
+			point: [2]float64{44, -73},
+			want:  []domain.RegionID{"US"},
+		},
+		{
+			name:  "outside every region",
+			point: [2]float64{0, 0},
+			want:  []domain.RegionID{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, idx.Locate(tc.point))
+		})
+	}
+}
+
+func TestIndexLocateOverlapping(t *testing.T) {
+	idx := NewIndex([]*domain.Region{
+		{ID: "AA", Type: domain.RegionTypeCountry, Boundary: []domain.Polygon{{square(0, 0, 2, 2)}}},
+		{ID: "BB", Type: domain.RegionTypeCountry, Boundary: []domain.Polygon{{square(1, 1, 3, 3)}}},
+	})
+
+	require.Equal(t, []domain.RegionID{"AA", "BB"}, idx.Locate([2]float64{1.5, 1.5}))
+	require.Equal(t, []domain.RegionID{"BB"}, idx.Locate([2]float64{2.5, 2.5}))
+}
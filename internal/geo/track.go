@@ -0,0 +1,157 @@
+package geo
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// sighting is the regions a device or track was located in at a time.
+type sighting struct {
+	at        time.Time
+	regionIDs []domain.RegionID
+	// evidence references the source of the sighting, if it has one.
+	evidence string
+}
+
+// TrackRows locates each point of a GPS track and returns an import row for each run of
+// consecutive days in a region, counting days in the region's time zone. A day located in several
+// regions is ambiguous, and is imported in each of them. Points without a time, or outside every
+// region, are skipped.
+func (idx *Index) TrackRows(points []domain.TrackPoint) ([]*domain.ImportRow, int) {
+	sightings := make([]sighting, 0, len(points))
+
+	var skipped int
+
+	for _, point := range points {
+		if point.At.IsZero() {
+			skipped++
+			continue
+		}
+
+		regionIDs := idx.Locate(point.LatLng)
+		if len(regionIDs) == 0 {
+			skipped++
+			continue
+		}
+
+		sightings = append(sightings, sighting{at: point.At, regionIDs: regionIDs})
+	}
+
+	return idx.rows(sightings), skipped
+}
+
+// PingRows returns the import rows of device pings as with TrackRows. Pings carry either a position,
+// which is located in the regions containing it, or a region code. Pings outside every region, or
+// with an unknown region or a zone, are skipped. Each row references the first ping of its run.
+func (idx *Index) PingRows(pings []*domain.DevicePing) ([]*domain.ImportRow, int) {
+	sightings := make([]sighting, 0, len(pings))
+
+	var skipped int
+
+	for _, ping := range pings {
+		var regionIDs []domain.RegionID
+
+		switch {
+		case ping.LatLng != nil:
+			regionIDs = idx.Locate(*ping.LatLng)
+		case ping.RegionID != nil:
+			if region, ok := idx.regions[*ping.RegionID]; ok && region.Type != domain.RegionTypeZone {
+				regionIDs = []domain.RegionID{region.ID}
+			}
+		}
+
+		if len(regionIDs) == 0 {
+			skipped++
+			continue
+		}
+
+		sightings = append(sightings, sighting{at: ping.At, regionIDs: regionIDs, evidence: fmt.Sprintf("ping:%d", ping.ID)})
+	}
+
+	return idx.rows(sightings), skipped
+}
+
+// rows groups the sightings by region-local day into runs of consecutive days in each region. A
+// day in a region is ambiguous when another region was located during that day, taken in the
+// region's own time zone, as regions either side of a border can be on different calendar days.
+func (idx *Index) rows(sightings []sighting) []*domain.ImportRow {
+	// days holds the first sighting of each day in each region, whose evidence the day is recorded with
+	days := make(map[domain.RegionID]map[time.Time]sighting)
+
+	for _, s := range sightings {
+		for _, regionID := range s.regionIDs {
+			date := domain.LocalDate(s.at, idx.regions[regionID].Location())
+
+			if days[regionID] == nil {
+				days[regionID] = make(map[time.Time]sighting)
+			}
+			if first, ok := days[regionID][date]; !ok || s.at.Before(first.at) {
+				days[regionID][date] = s
+			}
+		}
+	}
+
+	// regionsDuring holds the regions located during each day of each region, to find the
+	// ambiguous days
+	regionsDuring := make(map[domain.RegionID]map[time.Time]map[domain.RegionID]struct{})
+
+	for regionID, regionDays := range days {
+		loc := idx.regions[regionID].Location()
+		regionsDuring[regionID] = make(map[time.Time]map[domain.RegionID]struct{})
+
+		for _, s := range sightings {
+			date := domain.LocalDate(s.at, loc)
+			if _, ok := regionDays[date]; !ok {
+				continue
+			}
+
+			if regionsDuring[regionID][date] == nil {
+				regionsDuring[regionID][date] = make(map[domain.RegionID]struct{})
+			}
+			for _, id := range s.regionIDs {
+				regionsDuring[regionID][date][id] = struct{}{}
+			}
+		}
+	}
+
+	rows := make([]*domain.ImportRow, 0)
+
+	for regionID, regionDays := range days {
+		dates := make([]time.Time, 0, len(regionDays))
+		for date := range regionDays {
+			dates = append(dates, date)
+		}
+		slices.SortFunc(dates, time.Time.Compare)
+
+		var row *domain.ImportRow
+
+		for _, date := range dates {
+			ambiguous := len(regionsDuring[regionID][date]) > 1
+
+			if row != nil && row.End.AddDate(0, 0, 1).Equal(date) && row.Ambiguous == ambiguous {
+				row.End = date
+				continue
+			}
+
+			row = &domain.ImportRow{
+				RegionID:   regionID,
+				Start:      date,
+				End:        date,
+				Provenance: domain.PresenceProvenanceGPS,
+				Evidence:   regionDays[date].evidence,
+				Ambiguous:  ambiguous,
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b *domain.ImportRow) int {
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.RegionID, b.RegionID))
+	})
+
+	return rows
+}
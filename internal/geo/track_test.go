@@ -0,0 +1,139 @@
+package geo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestIndexTrackRows(t *testing.T) {
+	idx := NewIndex([]*domain.Region{
+		{ID: "JE", Type: domain.RegionTypeCountry, TimeZone: "Europe/Jersey", Boundary: []domain.Polygon{{square(49.16, -2.26, 49.27, -2.00)}}},
+		{ID: "GG", Type: domain.RegionTypeCountry, TimeZone: "Europe/Guernsey", Boundary: []domain.Polygon{{square(49.40, -2.68, 49.52, -2.50)}}},
+		{ID: "US", Type: domain.RegionTypeCountry, TimeZone: "America/New_York", Boundary: []domain.Polygon{{square(25, -125, 49, -67)}}},
+	})
+
+	jersey := [2]float64{49.21, -2.13}
+	guernsey := [2]float64{49.45, -2.55}
+	newYork := [2]float64{40.71, -74.01}
+
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	points := []domain.TrackPoint{
+		// Out of order points are grouped by day
+		{At: at(time.June, 3, 12), LatLng: jersey},
+		{At: at(time.June, 1, 9), LatLng: jersey},
+		{At: at(time.June, 2, 9), LatLng: jersey},
+		// A day trip to Guernsey is ambiguous, and splits the Jersey days
+		{At: at(time.June, 4, 9), LatLng: jersey},
+		{At: at(time.June, 4, 12), LatLng: guernsey},
+		{At: at(time.June, 5, 9), LatLng: jersey},
+		// Early on the 10th in UTC is still the 9th in New York
+		{At: at(time.June, 10, 2), LatLng: newYork},
+		// Skipped points, without a time or over the sea
+		{LatLng: jersey},
+		{At: at(time.June, 11, 9), LatLng: [2]float64{49.8, -3.5}},
+	}
+
+	rows, skipped := idx.TrackRows(points)
+
+	require.Equal(t, 2, skipped)
+	require.Equal(t, []*domain.ImportRow{
+		{RegionID: "JE", Start: date(time.June, 1), End: date(time.June, 3), Provenance: domain.PresenceProvenanceGPS},
+		{RegionID: "GG", Start: date(time.June, 4), End: date(time.June, 4), Provenance: domain.PresenceProvenanceGPS, Ambiguous: true},
+		{RegionID: "JE", Start: date(time.June, 4), End: date(time.June, 4), Provenance: domain.PresenceProvenanceGPS, Ambiguous: true},
+		{RegionID: "JE", Start: date(time.June, 5), End: date(time.June, 5), Provenance: domain.PresenceProvenanceGPS},
+		{RegionID: "US", Start: date(time.June, 9), End: date(time.June, 9), Provenance: domain.PresenceProvenanceGPS},
+	}, rows)
+}
+
+func TestIndexTrackRowsAcrossTimeZones(t *testing.T) {
+	// Portugal is an hour behind Spain, so the border is crossed on different calendar days
+	idx := NewIndex([]*domain.Region{
+		{ID: "PT", Type: domain.RegionTypeCountry, TimeZone: "Europe/Lisbon", Boundary: []domain.Polygon{{square(37, -9.5, 42, -7.1)}}},
+		{ID: "ES", Type: domain.RegionTypeCountry, TimeZone: "Europe/Madrid", Boundary: []domain.Polygon{{square(36, -7, 43.5, 3)}}},
+	})
+
+	portugal := [2]float64{38.72, -9.14}
+	spain := [2]float64{40.42, -3.70}
+
+	date := func(day int) time.Time {
+		return time.Date(2025, time.June, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	points := []domain.TrackPoint{
+		// Into Spain after midnight in Spain but before midnight in Portugal, so the 2nd in Spain
+		// began in Portugal while the 1st in Portugal ended before reaching Spain
+		{At: at(1, 12, 0), LatLng: portugal},
+		{At: at(1, 22, 30), LatLng: portugal},
+		{At: at(1, 23, 30), LatLng: spain},
+		{At: at(2, 12, 0), LatLng: spain},
+		// Into Portugal after midnight in both, so the 6th in Spain ended in Portugal while the
+		// 6th in Portugal began after leaving Spain
+		{At: at(5, 22, 30), LatLng: spain},
+		{At: at(5, 23, 30), LatLng: portugal},
+	}
+
+	rows, skipped := idx.TrackRows(points)
+
+	require.Zero(t, skipped)
+	require.Equal(t, []*domain.ImportRow{
+		{RegionID: "PT", Start: date(1), End: date(1), Provenance: domain.PresenceProvenanceGPS},
+		{RegionID: "ES", Start: date(2), End: date(2), Provenance: domain.PresenceProvenanceGPS, Ambiguous: true},
+		{RegionID: "ES", Start: date(6), End: date(6), Provenance: domain.PresenceProvenanceGPS, Ambiguous: true},
+		{RegionID: "PT", Start: date(6), End: date(6), Provenance: domain.PresenceProvenanceGPS},
+	}, rows)
+}
+
+func TestIndexPingRows(t *testing.T) {
+	idx := NewIndex([]*domain.Region{
+		{ID: "JE", Type: domain.RegionTypeCountry, TimeZone: "Europe/Jersey", Boundary: []domain.Polygon{{square(49.16, -2.26, 49.27, -2.00)}}},
+		{ID: "GG", Type: domain.RegionTypeCountry, TimeZone: "Europe/Guernsey"},
+		{ID: "EU", Type: domain.RegionTypeZone},
+	})
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.June, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	date := func(day int) time.Time {
+		return time.Date(2025, time.June, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	regionID := func(id domain.RegionID) *domain.RegionID {
+		return &id
+	}
+
+	pings := []*domain.DevicePing{
+		{ID: 1, At: at(2, 9), RegionID: regionID("GG")},
+		{ID: 2, At: at(1, 9), LatLng: &[2]float64{49.21, -2.13}},
+		{ID: 3, At: at(2, 10), LatLng: &[2]float64{49.21, -2.13}},
+		{ID: 4, At: at(2, 8), LatLng: &[2]float64{49.21, -2.13}},
+		// Skipped pings, over the sea, in an unknown region or in a zone
+		{ID: 5, At: at(3, 9), LatLng: &[2]float64{49.8, -3.5}},
+		{ID: 6, At: at(3, 9), RegionID: regionID("XX")},
+		{ID: 7, At: at(3, 9), RegionID: regionID("EU")},
+	}
+
+	rows, skipped := idx.PingRows(pings)
+
+	require.Equal(t, 3, skipped)
+	require.Equal(t, []*domain.ImportRow{
+		{RegionID: "JE", Start: date(1), End: date(1), Provenance: domain.PresenceProvenanceGPS, Evidence: "ping:2"},
+		{RegionID: "GG", Start: date(2), End: date(2), Provenance: domain.PresenceProvenanceGPS, Evidence: "ping:1", Ambiguous: true},
+		{RegionID: "JE", Start: date(2), End: date(2), Provenance: domain.PresenceProvenanceGPS, Evidence: "ping:4", Ambiguous: true},
+	}, rows)
+}
@@ -0,0 +1,205 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestParse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []*domain.CalendarEvent
+		wantErr error
+	}{
+		{
+			name: "all-day, timed, floating and geo events",
+			body: strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"BEGIN:VEVENT",
+				"UID:trip-1",
+				"SUMMARY:Holiday\\, Jersey",
+				"DTSTART;VALUE=DATE:20250601",
+				"DTEND;VALUE=DATE:20250605",
+				"X-PUMPKINLOG-REGION:je",
+				"BEGIN:VALARM",
+				"DESCRIPTION:Ignored",
+				"END:VALARM",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:trip-2",
+				"LOCATION:New York",
+				"DTSTART;TZID=\"America/New_York\":20250610T090000",
+				"DTEND;TZID=America/New_York:20250612T180000",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:trip-3",
+				"DESCRIPTION:A long description that is folded",
+				"  onto a second line",
+				"DTSTART:20250615T080000",
+				"DURATION:P1DT2H",
+				"GEO:49.21;-2.13",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:trip-4",
+				"STATUS:CANCELLED",
+				"DTSTART:20250620T080000Z",
+				"END:VEVENT",
+				"END:VCALENDAR",
+			}, "\r\n"),
+			want: []*domain.CalendarEvent{
+				{
+					Line:     3,
+					UID:      "trip-1",
+					Summary:  "Holiday, Jersey",
+					RegionID: "JE",
+					Start:    time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+					End:      time.Date(2025, time.June, 5, 0, 0, 0, 0, time.UTC),
+					AllDay:   true,
+				},
+				{
+					Line:     13,
+					UID:      "trip-2",
+					Location: "New York",
+					Start:    time.Date(2025, time.June, 10, 9, 0, 0, 0, newYork),
+					End:      time.Date(2025, time.June, 12, 18, 0, 0, 0, newYork),
+				},
+				{
+					Line:     19,
+					UID:      "trip-3",
+					Geo:      &[2]float64{49.21, -2.13},
+					Start:    time.Date(2025, time.June, 15, 8, 0, 0, 0, time.UTC),
+					Duration: 26 * time.Hour,
+					Floating: true,
+				},
+			},
+		},
+		{
+			name: "unreadable events are returned with an error",
+			body: strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"BEGIN:VEVENT",
+				"DTSTART:yesterday",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"SUMMARY:No start",
+				"END:VEVENT",
+				"END:VCALENDAR",
+			}, "\n"),
+			want: []*domain.CalendarEvent{
+				{Line: 2, Err: domain.ValidationError("invalid dtstart time: yesterday")},
+				{Line: 5, Summary: "No start", Err: domain.ValidationError("event has no start")},
+			},
+		},
+		{
+			name:    "not a calendar",
+			body:    "date,region\n2025-06-01,JE\n",
+			wantErr: domain.ValidationError("calendar must begin with BEGIN:VCALENDAR"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := Parse(strings.NewReader(tc.body))
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, events, len(tc.want))
+			for i, want := range tc.want {
+				got := events[i]
+				require.True(t, want.Start.Equal(got.Start), "event %d start %s, want %s", i, got.Start, want.Start)
+				require.True(t, want.End.Equal(got.End), "event %d end %s, want %s", i, got.End, want.End)
+				got.Start, got.End = want.Start, want.End
+				require.Equal(t, want, got, "event %d", i)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "P2W", want: 14 * 24 * time.Hour},
+		{value: "P1DT12H30M", want: 36*time.Hour + 30*time.Minute},
+		{value: "+PT45S", want: 45 * time.Second},
+		{value: "P1H", wantErr: true},
+		{value: "-P1D", wantErr: true},
+		{value: "P1", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseDuration(tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	feed := &domain.CalendarFeed{
+		Name: "Pumpkinlog presences",
+		Events: []*domain.CalendarFeedEvent{
+			{
+				UID:      "presence-JE-20250601@pumpkinlog",
+				Summary:  "In Jersey",
+				RegionID: "JE",
+				Start:    time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+				End:      time.Date(2025, time.June, 3, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				UID:         "threshold-JE-count-183-20251201@pumpkinlog",
+				Summary:     "Jersey: 183 day threshold",
+				Description: strings.Repeat("Staying in Jersey; ", 5),
+				RegionID:    "JE",
+				Start:       time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC),
+				Reminder:    true,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, feed, time.Date(2025, time.June, 4, 12, 0, 0, 0, time.UTC)))
+
+	out := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), maxLineOctets, "line %q", line)
+	}
+
+	require.Contains(t, out, "DTSTART;VALUE=DATE:20250601\r\nDTEND;VALUE=DATE:20250604\r\n")
+	require.Contains(t, out, "BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Jersey: 183 day threshold\r\nTRIGGER:-P7D\r\nEND:VALARM\r\n")
+	require.Equal(t, 1, strings.Count(out, "BEGIN:VALARM"))
+
+	// The feed reads back as the same days and regions
+	events, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	start, end := events[0].Days(time.UTC)
+	require.Equal(t, domain.RegionID("JE"), events[0].RegionID)
+	require.Equal(t, feed.Events[0].Start, start)
+	require.Equal(t, feed.Events[0].End, end)
+	require.Equal(t, feed.Events[1].UID, events[1].UID)
+}
@@ -0,0 +1,358 @@
+// Package ical reads and writes the iCalendar (RFC 5545) events presences are imported from and
+// exported to.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// RegionProperty carries the region code of an event, so exported presences import back into the
+// same region.
+const RegionProperty = "X-PUMPKINLOG-REGION"
+
+// maxLineLength caps the length of an unfolded content line.
+const maxLineLength = 1 << 16
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events of a calendar. Cancelled events are skipped, and recurring events only
+// import their first occurrence. An event that cannot be read is returned with its error set, so
+// every problem is reported at once.
+func Parse(r io.Reader) ([]*domain.CalendarEvent, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+
+	var (
+		events = make([]*domain.CalendarEvent, 0)
+		event  *domain.CalendarEvent
+		// depth counts the components nested in an event, such as alarms, whose properties are ignored
+		depth     int
+		calendar  bool
+		cancelled bool
+	)
+
+	err := unfold(scanner, func(line int, content string) error {
+		prop, err := parseProperty(content)
+		if err != nil {
+			if event != nil && depth == 0 && event.Err == nil {
+				event.Err = domain.ValidationError("invalid content line %d: %s", line, err)
+			}
+			return nil
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCALENDAR"):
+			calendar = true
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && event == nil:
+			event = &domain.CalendarEvent{Line: line}
+			cancelled = false
+		case prop.name == "BEGIN" && event != nil:
+			depth++
+		case prop.name == "END" && event != nil && depth > 0:
+			depth--
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && event != nil:
+			if !cancelled {
+				events = append(events, event)
+			}
+			event = nil
+		case event != nil && depth == 0:
+			if strings.EqualFold(prop.value, "CANCELLED") && prop.name == "STATUS" {
+				cancelled = true
+			}
+			if event.Err == nil {
+				event.Err = setEventProperty(event, prop)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, domain.ValidationError("cannot read calendar: %s", err)
+	}
+
+	if !calendar {
+		return nil, domain.ValidationError("calendar must begin with BEGIN:VCALENDAR")
+	}
+
+	for _, event := range events {
+		if event.Err == nil && event.Start.IsZero() {
+			event.Err = domain.ValidationError("event has no start")
+		}
+	}
+
+	return events, nil
+}
+
+// unfold joins folded lines, which continue on the next line after a space or tab, and calls fn
+// with the line number each content line started on.
+func unfold(scanner *bufio.Scanner, fn func(line int, content string) error) error {
+	var (
+		content strings.Builder
+		start   int
+		number  int
+	)
+
+	flush := func() error {
+		if content.Len() == 0 {
+			return nil
+		}
+		defer content.Reset()
+		return fn(start, content.String())
+	}
+
+	for scanner.Scan() {
+		number++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if content.Len()+len(line) > maxLineLength {
+				return bufio.ErrTooLong
+			}
+			content.WriteString(line[1:])
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		if line == "" {
+			continue
+		}
+
+		start = number
+		content.WriteString(strings.TrimPrefix(line, "\ufeff"))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// parseProperty splits a content line into its name, parameters and value. Parameter values may be
+// quoted to hold colons and semicolons.
+func parseProperty(content string) (property, error) {
+	prop := property{params: make(map[string]string)}
+
+	var (
+		i      int
+		quoted bool
+	)
+
+	for i = 0; i < len(content); i++ {
+		c := content[i]
+		if c == '"' {
+			quoted = !quoted
+		}
+		if c == ':' && !quoted {
+			break
+		}
+	}
+
+	if i == len(content) {
+		return prop, domain.ValidationError("missing value")
+	}
+
+	prop.value = content[i+1:]
+
+	parts := splitUnquoted(content[:i], ';')
+	prop.name = strings.ToUpper(parts[0])
+
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return prop, domain.ValidationError("invalid parameter: %s", param)
+		}
+		prop.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+
+	if prop.name == "" {
+		return prop, domain.ValidationError("missing name")
+	}
+
+	return prop, nil
+}
+
+func splitUnquoted(s string, sep byte) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+func setEventProperty(event *domain.CalendarEvent, prop property) error {
+	switch prop.name {
+	case "UID":
+		event.UID = unescapeText(prop.value)
+	case "SUMMARY":
+		event.Summary = unescapeText(prop.value)
+	case "LOCATION":
+		event.Location = unescapeText(prop.value)
+	case RegionProperty:
+		event.RegionID = domain.RegionID(strings.ToUpper(strings.TrimSpace(prop.value)))
+	case "GEO":
+		lat, lng, ok := strings.Cut(prop.value, ";")
+		if !ok {
+			return domain.ValidationError("invalid geo: %s", prop.value)
+		}
+
+		latitude, err := strconv.ParseFloat(lat, 64)
+		if err != nil {
+			return domain.ValidationError("invalid geo latitude: %s", lat)
+		}
+
+		longitude, err := strconv.ParseFloat(lng, 64)
+		if err != nil {
+			return domain.ValidationError("invalid geo longitude: %s", lng)
+		}
+
+		event.Geo = &[2]float64{latitude, longitude}
+	case "DTSTART":
+		start, allDay, floating, err := parseDateTime(prop)
+		if err != nil {
+			return err
+		}
+		event.Start, event.AllDay, event.Floating = start, allDay, floating
+	case "DTEND":
+		end, _, _, err := parseDateTime(prop)
+		if err != nil {
+			return err
+		}
+		event.End = end
+	case "DURATION":
+		duration, err := parseDuration(prop.value)
+		if err != nil {
+			return err
+		}
+		event.Duration = duration
+	}
+
+	return nil
+}
+
+// parseDateTime reads a date, a UTC time, a time in a TZID time zone or a floating time, which is
+// returned as a wall clock time in UTC.
+func parseDateTime(prop property) (time.Time, bool, bool, error) {
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(prop.value) == len("20060102") {
+		t, err := time.Parse("20060102", prop.value)
+		if err != nil {
+			return time.Time{}, false, false, domain.ValidationError("invalid %s date: %s", strings.ToLower(prop.name), prop.value)
+		}
+		return t, true, false, nil
+	}
+
+	if strings.HasSuffix(prop.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", prop.value)
+		if err != nil {
+			return time.Time{}, false, false, domain.ValidationError("invalid %s time: %s", strings.ToLower(prop.name), prop.value)
+		}
+		return t, false, false, nil
+	}
+
+	t, err := time.Parse("20060102T150405", prop.value)
+	if err != nil {
+		return time.Time{}, false, false, domain.ValidationError("invalid %s time: %s", strings.ToLower(prop.name), prop.value)
+	}
+
+	// Calendar apps name their own zones, so a zone that is not an IANA zone is taken as floating
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil && loc != time.Local {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), false, false, nil
+		}
+	}
+
+	return t, false, true, nil
+}
+
+// parseDuration reads a positive duration of weeks, days, hours, minutes and seconds.
+func parseDuration(s string) (time.Duration, error) {
+	value := strings.TrimPrefix(s, "+")
+	if !strings.HasPrefix(value, "P") {
+		return 0, domain.ValidationError("invalid duration: %s", s)
+	}
+
+	var (
+		duration time.Duration
+		number   int
+		digits   bool
+		inTime   bool
+	)
+
+	units := map[bool]map[byte]time.Duration{
+		false: {'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour},
+		true:  {'H': time.Hour, 'M': time.Minute, 'S': time.Second},
+	}
+
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c >= '0' && c <= '9':
+			number = number*10 + int(c-'0')
+			digits = true
+		case c == 'T' && !inTime && !digits:
+			inTime = true
+		default:
+			unit, ok := units[inTime][c]
+			if !ok || !digits {
+				return 0, domain.ValidationError("invalid duration: %s", s)
+			}
+			duration += time.Duration(number) * unit
+			number, digits = 0, false
+		}
+	}
+
+	if digits {
+		return 0, domain.ValidationError("invalid duration: %s", s)
+	}
+
+	return duration, nil
+}
+
+func unescapeText(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
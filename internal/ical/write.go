@@ -0,0 +1,96 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// ContentType is the media type of an iCalendar file.
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLineOctets is the longest a content line may be before it is folded.
+const maxLineOctets = 75
+
+// ReminderTrigger is how long before a reminder its alarm goes off.
+const ReminderTrigger = "-P7D"
+
+// Write writes the feed as a calendar of all-day events, stamped with the time it was written.
+func Write(w io.Writer, feed *domain.CalendarFeed, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Pumpkinlog//Presences//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeText(feed.Name))
+
+	for _, event := range feed.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(event.UID))
+		line("DTSTAMP", dtstamp)
+		line("DTSTART;VALUE=DATE", event.Start.Format("20060102"))
+		// All-day events end on the day after their last day
+		line("DTEND;VALUE=DATE", event.End.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		line(RegionProperty, string(event.RegionID))
+		line("TRANSP", "TRANSPARENT")
+
+		if event.Reminder {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", escapeText(event.Summary))
+			line("TRIGGER", ReminderTrigger)
+			line("END", "VALARM")
+		}
+
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// writeLine writes a content line, folding it onto continuation lines without splitting a UTF-8
+// character.
+func writeLine(w *bufio.Writer, content string) {
+	limit := maxLineOctets
+
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+
+		w.WriteString(content[:cut])
+		w.WriteString("\r\n ")
+		content = content[cut:]
+
+		// Continuation lines begin with a space, which counts toward their length
+		limit = maxLineOctets - 1
+	}
+
+	w.WriteString(content)
+	w.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
@@ -0,0 +1,143 @@
+// Package importer reads travel histories exported from other tools into presence import rows.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+// csvColumns maps the accepted header names to the import row field they fill.
+var csvColumns = map[string]string{
+	"region":    "region",
+	"regionid":  "region",
+	"region_id": "region",
+	"start":     "start",
+	"end":       "end",
+	"arrival":   "arrival",
+	"departure": "departure",
+	"transit":   "transit",
+}
+
+// ParseCSV reads a CSV travel history with a header row. The region, start and end columns are
+// required, and the arrival, departure and transit columns are optional. Dates are YYYY-MM-DD.
+// A row that cannot be read is returned with its error set, so every problem is reported at once.
+func ParseCSV(r io.Reader) ([]*domain.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, domain.ValidationError("csv is empty")
+	}
+	if err != nil {
+		return nil, domain.ValidationError("cannot read csv header: %s", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+
+		field, ok := csvColumns[name]
+		if !ok {
+			return nil, domain.ValidationError("unknown csv column: %s", name)
+		}
+
+		if _, ok := columns[field]; ok {
+			return nil, domain.ValidationError("duplicate csv column: %s", field)
+		}
+		columns[field] = i
+	}
+
+	for _, field := range []string{"region", "start", "end"} {
+		if _, ok := columns[field]; !ok {
+			return nil, domain.ValidationError("csv column %s is required", field)
+		}
+	}
+
+	rows := make([]*domain.ImportRow, 0)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, domain.ValidationError("cannot read csv: %s", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseCSVRecord(line, record, columns))
+	}
+
+	return rows, nil
+}
+
+func parseCSVRecord(line int, record []string, columns map[string]int) *domain.ImportRow {
+	row := &domain.ImportRow{Line: line}
+
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.RegionID = domain.RegionID(strings.ToUpper(value("region")))
+
+	var err error
+
+	if row.Start, err = parseCSVDate(value("start")); err != nil {
+		row.Err = domain.ValidationError("invalid start date: %s", value("start"))
+		return row
+	}
+
+	if row.End, err = parseCSVDate(value("end")); err != nil {
+		row.Err = domain.ValidationError("invalid end date: %s", value("end"))
+		return row
+	}
+
+	flags := []struct {
+		field string
+		dst   *bool
+	}{
+		{"arrival", &row.Arrival},
+		{"departure", &row.Departure},
+		{"transit", &row.Transit},
+	}
+
+	for _, flag := range flags {
+		if *flag.dst, err = parseCSVBool(value(flag.field)); err != nil {
+			row.Err = domain.ValidationError("invalid %s: %s", flag.field, value(flag.field))
+			return row
+		}
+	}
+
+	return row
+}
+
+// parseCSVDate leaves an empty date zero, so the row reports the date as required.
+func parseCSVDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+func parseCSVBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "no", "n":
+		return false, nil
+	case "yes", "y":
+		return true, nil
+	default:
+		return strconv.ParseBool(s)
+	}
+}
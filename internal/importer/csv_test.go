@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestParseCSV(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		require.NoError(t, err)
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		csv     string
+		want    []*domain.ImportRow
+		wantErr error
+	}{
+		{
+			name: "required columns",
+			csv:  "region,start,end\nJE,2025-01-01,2025-01-10\nGG,2025-01-10,2025-01-12\n",
+			want: []*domain.ImportRow{
+				{Line: 2, RegionID: "JE", Start: date("2025-01-01"), End: date("2025-01-10")},
+				{Line: 3, RegionID: "GG", Start: date("2025-01-10"), End: date("2025-01-12")},
+			},
+		},
+		{
+			name: "optional columns in any order",
+			csv:  "\ufeffStart, End, RegionId, Arrival, Transit\n2025-01-01, 2025-01-10, je, yes, \n",
+			want: []*domain.ImportRow{
+				{Line: 2, RegionID: "JE", Start: date("2025-01-01"), End: date("2025-01-10"), Arrival: true},
+			},
+		},
+		{
+			name: "blank lines skipped",
+			csv:  "region,start,end\n\nJE,2025-01-01,2025-01-01\n",
+			want: []*domain.ImportRow{
+				{Line: 3, RegionID: "JE", Start: date("2025-01-01"), End: date("2025-01-01")},
+			},
+		},
+		{
+			name: "row errors are kept",
+			csv:  "region,start,end,transit\nJE,01/02/2025,2025-01-10,\nJE,2025-01-01,2025-01-10,sometimes\nJE,2025-01-01\n",
+			want: []*domain.ImportRow{
+				{Line: 2, RegionID: "JE", Err: domain.ValidationError("invalid start date: 01/02/2025")},
+				{Line: 3, RegionID: "JE", Start: date("2025-01-01"), End: date("2025-01-10"), Err: domain.ValidationError("invalid transit: sometimes")},
+				{Line: 4, RegionID: "JE", Start: date("2025-01-01")},
+			},
+		},
+		{
+			name:    "empty",
+			csv:     "",
+			wantErr: domain.ValidationError("csv is empty"),
+		},
+		{
+			name:    "missing column",
+			csv:     "region,start\nJE,2025-01-01\n",
+			wantErr: domain.ValidationError("csv column end is required"),
+		},
+		{
+			name:    "unknown column",
+			csv:     "region,start,end,country\n",
+			wantErr: domain.ValidationError("unknown csv column: country"),
+		},
+		{
+			name:    "duplicate column",
+			csv:     "region,regionId,start,end\n",
+			wantErr: domain.ValidationError("duplicate csv column: region"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := ParseCSV(strings.NewReader(tc.csv))
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, rows)
+		})
+	}
+}
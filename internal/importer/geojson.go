@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type geoJSONObject struct {
+	Type        string            `json:"type"`
+	Features    []geoJSONObject   `json:"features"`
+	Geometry    *geoJSONObject    `json:"geometry"`
+	Geometries  []geoJSONObject   `json:"geometries"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Properties  geoJSONProperties `json:"properties"`
+}
+
+// geoJSONProperties are the feature properties that time its coordinates, following the
+// conventions of common exporters.
+type geoJSONProperties struct {
+	Time      string `json:"time"`
+	Timestamp string `json:"timestamp"`
+	// CoordTimes times each coordinate of a line string, or each line of a multi line string.
+	CoordTimes json.RawMessage `json:"coordTimes"`
+}
+
+// ParseGeoJSON reads the points, multi points, line strings and multi line strings of a GeoJSON
+// feature collection, feature or geometry. Coordinates are timed by the coordTimes property when
+// present, and otherwise by the feature's time or timestamp property. Other geometries are ignored.
+func ParseGeoJSON(r io.Reader) ([]domain.TrackPoint, error) {
+	var object geoJSONObject
+	if err := json.NewDecoder(r).Decode(&object); err != nil {
+		return nil, domain.ValidationError("cannot read geojson: %s", err)
+	}
+
+	points := make([]domain.TrackPoint, 0)
+	if err := object.appendPoints(&points, geoJSONProperties{}); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+func (o *geoJSONObject) appendPoints(points *[]domain.TrackPoint, props geoJSONProperties) error {
+	switch o.Type {
+	case "FeatureCollection":
+		for _, feature := range o.Features {
+			if err := feature.appendPoints(points, props); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Feature":
+		if o.Geometry == nil {
+			return nil
+		}
+		return o.Geometry.appendPoints(points, o.Properties)
+	case "GeometryCollection":
+		for _, geometry := range o.Geometries {
+			if err := geometry.appendPoints(points, props); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	when := props.Time
+	if when == "" {
+		when = props.Timestamp
+	}
+
+	at, err := parseTrackTime(when)
+	if err != nil {
+		return err
+	}
+
+	switch o.Type {
+	case "Point":
+		var coordinate []float64
+		if err := json.Unmarshal(o.Coordinates, &coordinate); err != nil {
+			return domain.ValidationError("invalid geojson point: %s", err)
+		}
+
+		latLng, err := geoJSONLatLng(coordinate)
+		if err != nil {
+			return err
+		}
+
+		*points = append(*points, domain.TrackPoint{At: at, LatLng: latLng})
+	case "MultiPoint", "LineString":
+		var coordinates [][]float64
+		if err := json.Unmarshal(o.Coordinates, &coordinates); err != nil {
+			return domain.ValidationError("invalid geojson %s: %s", o.Type, err)
+		}
+
+		var times []string
+		if len(props.CoordTimes) > 0 {
+			if err := json.Unmarshal(props.CoordTimes, &times); err != nil {
+				return domain.ValidationError("invalid geojson coordTimes: %s", err)
+			}
+		}
+
+		return appendGeoJSONLine(points, coordinates, times, at)
+	case "MultiLineString":
+		var lines [][][]float64
+		if err := json.Unmarshal(o.Coordinates, &lines); err != nil {
+			return domain.ValidationError("invalid geojson %s: %s", o.Type, err)
+		}
+
+		var times [][]string
+		if len(props.CoordTimes) > 0 {
+			if err := json.Unmarshal(props.CoordTimes, &times); err != nil {
+				return domain.ValidationError("invalid geojson coordTimes: %s", err)
+			}
+
+			if len(times) != len(lines) {
+				return domain.ValidationError("geojson coordTimes must have times for each line")
+			}
+		}
+
+		for i, line := range lines {
+			var lineTimes []string
+			if times != nil {
+				lineTimes = times[i]
+			}
+
+			if err := appendGeoJSONLine(points, line, lineTimes, at); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendGeoJSONLine appends the coordinates, timed by the times when given and otherwise at.
+func appendGeoJSONLine(points *[]domain.TrackPoint, coordinates [][]float64, times []string, at time.Time) error {
+	if times != nil && len(times) != len(coordinates) {
+		return domain.ValidationError("geojson coordTimes must have a time for each coordinate")
+	}
+
+	for i, coordinate := range coordinates {
+		latLng, err := geoJSONLatLng(coordinate)
+		if err != nil {
+			return err
+		}
+
+		pointAt := at
+		if times != nil {
+			if pointAt, err = parseTrackTime(times[i]); err != nil {
+				return err
+			}
+		}
+
+		*points = append(*points, domain.TrackPoint{At: pointAt, LatLng: latLng})
+	}
+
+	return nil
+}
+
+func geoJSONLatLng(coordinate []float64) ([2]float64, error) {
+	if len(coordinate) < 2 {
+		return [2]float64{}, domain.ValidationError("invalid geojson coordinate: %v", coordinate)
+	}
+
+	return [2]float64{coordinate[1], coordinate[0]}, nil
+}
@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time"`
+}
+
+// ParseGPX reads the track, route and way points of a GPX file.
+func ParseGPX(r io.Reader) ([]domain.TrackPoint, error) {
+	decoder := xml.NewDecoder(r)
+	points := make([]domain.TrackPoint, 0)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, domain.ValidationError("cannot read gpx: %s", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "trkpt", "rtept", "wpt":
+		default:
+			continue
+		}
+
+		var p gpxPoint
+		if err := decoder.DecodeElement(&p, &start); err != nil {
+			return nil, domain.ValidationError("cannot read gpx %s: %s", start.Name.Local, err)
+		}
+
+		at, err := parseTrackTime(p.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, domain.TrackPoint{At: at, LatLng: [2]float64{p.Lat, p.Lon}})
+	}
+
+	return points, nil
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type kmlPlacemark struct {
+	When  string `xml:"TimeStamp>when"`
+	Begin string `xml:"TimeSpan>begin"`
+	// Coordinates, Lines, MultiPoints and MultiLines are the coordinates of the point and line
+	// string geometries.
+	Coordinates []string   `xml:"Point>coordinates"`
+	Lines       []string   `xml:"LineString>coordinates"`
+	MultiPoints []string   `xml:"MultiGeometry>Point>coordinates"`
+	MultiLines  []string   `xml:"MultiGeometry>LineString>coordinates"`
+	Tracks      []kmlTrack `xml:"Track"`
+	MultiTracks []kmlTrack `xml:"MultiTrack>Track"`
+}
+
+// kmlTrack is a gx:Track, which times each coordinate.
+type kmlTrack struct {
+	When  []string `xml:"when"`
+	Coord []string `xml:"coord"`
+}
+
+// ParseKML reads the placemarks of a KML file. Points and line strings are timed by the
+// placemark's time stamp, or the beginning of its time span, and gx:Track coordinates by their own
+// times.
+func ParseKML(r io.Reader) ([]domain.TrackPoint, error) {
+	decoder := xml.NewDecoder(r)
+	points := make([]domain.TrackPoint, 0)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, domain.ValidationError("cannot read kml: %s", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		var placemark kmlPlacemark
+		if err := decoder.DecodeElement(&placemark, &start); err != nil {
+			return nil, domain.ValidationError("cannot read kml placemark: %s", err)
+		}
+
+		when := placemark.When
+		if when == "" {
+			when = placemark.Begin
+		}
+
+		at, err := parseTrackTime(when)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, coordinates := range slices.Concat(placemark.Coordinates, placemark.Lines, placemark.MultiPoints, placemark.MultiLines) {
+			for _, tuple := range strings.Fields(coordinates) {
+				latLng, err := parseKMLCoordinate(strings.Split(tuple, ","))
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, domain.TrackPoint{At: at, LatLng: latLng})
+			}
+		}
+
+		for _, track := range append(placemark.Tracks, placemark.MultiTracks...) {
+			trackPoints, err := parseKMLTrack(track)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, trackPoints...)
+		}
+	}
+
+	return points, nil
+}
+
+func parseKMLTrack(track kmlTrack) ([]domain.TrackPoint, error) {
+	if len(track.When) != len(track.Coord) {
+		return nil, domain.ValidationError("kml track must have a time for each coordinate")
+	}
+
+	points := make([]domain.TrackPoint, 0, len(track.Coord))
+
+	for i, coord := range track.Coord {
+		latLng, err := parseKMLCoordinate(strings.Fields(coord))
+		if err != nil {
+			return nil, err
+		}
+
+		at, err := parseTrackTime(track.When[i])
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, domain.TrackPoint{At: at, LatLng: latLng})
+	}
+
+	return points, nil
+}
+
+// parseKMLCoordinate reads a longitude, latitude and optional altitude.
+func parseKMLCoordinate(fields []string) ([2]float64, error) {
+	if len(fields) < 2 {
+		return [2]float64{}, domain.ValidationError("invalid kml coordinate: %s", strings.Join(fields, ","))
+	}
+
+	lng, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return [2]float64{}, domain.ValidationError("invalid kml longitude: %s", fields[0])
+	}
+
+	lat, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return [2]float64{}, domain.ValidationError("invalid kml latitude: %s", fields[1])
+	}
+
+	return [2]float64{lat, lng}, nil
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type TrackFormat string
+
+const (
+	TrackFormatGPX     TrackFormat = "gpx"
+	TrackFormatKML     TrackFormat = "kml"
+	TrackFormatGeoJSON TrackFormat = "geojson"
+)
+
+// TrackFormatFromContentType returns the track format of a media type, if it is one.
+func TrackFormatFromContentType(contentType string) (TrackFormat, bool) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "application/gpx+xml":
+		return TrackFormatGPX, true
+	case "application/vnd.google-earth.kml+xml":
+		return TrackFormatKML, true
+	case "application/geo+json":
+		return TrackFormatGeoJSON, true
+	default:
+		return "", false
+	}
+}
+
+// ParseTrack reads the points of a GPS track file. Points without a time are kept with a zero
+// time, so they can be counted as skipped.
+func ParseTrack(format TrackFormat, r io.Reader) ([]domain.TrackPoint, error) {
+	var (
+		points []domain.TrackPoint
+		err    error
+	)
+
+	switch format {
+	case TrackFormatGPX:
+		points, err = ParseGPX(r)
+	case TrackFormatKML:
+		points, err = ParseKML(r)
+	case TrackFormatGeoJSON:
+		points, err = ParseGeoJSON(r)
+	default:
+		return nil, domain.ValidationError("unknown track format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(points) == 0 {
+		return nil, domain.ValidationError("%s track has no points", format)
+	}
+
+	if len(points) > domain.MaxTrackPoints {
+		return nil, domain.ValidationError("track cannot have more than %d points", domain.MaxTrackPoints)
+	}
+
+	for _, point := range points {
+		if !domain.ValidLatLng(point.LatLng) {
+			return nil, domain.ValidationError("track point out of range: %v", point.LatLng)
+		}
+	}
+
+	return points, nil
+}
+
+// parseTrackTime reads an RFC 3339 time, leaving a missing time zero.
+func parseTrackTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, domain.ValidationError("invalid track time: %s", s)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+func TestParseTrack(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return v
+	}
+
+	tests := []struct {
+		name    string
+		format  TrackFormat
+		body    string
+		want    []domain.TrackPoint
+		wantErr error
+	}{
+		{
+			name:   "gpx track, route and way points",
+			format: TrackFormatGPX,
+			body: `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="49.21" lon="-2.13"><time>2025-06-01T09:00:00Z</time></wpt>
+  <rte><rtept lat="49.22" lon="-2.14"></rtept></rte>
+  <trk><trkseg>
+    <trkpt lat="49.45" lon="-2.55"><ele>20</ele><time>2025-06-02T09:00:00+01:00</time></trkpt>
+  </trkseg></trk>
+</gpx>`,
+			want: []domain.TrackPoint{
+				{At: at("2025-06-01T09:00:00Z"), LatLng: [2]float64{49.21, -2.13}},
+				{LatLng: [2]float64{49.22, -2.14}},
+				{At: at("2025-06-02T09:00:00+01:00"), LatLng: [2]float64{49.45, -2.55}},
+			},
+		},
+		{
+			name:   "kml placemarks and tracks",
+			format: TrackFormatKML,
+			body: `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+  <Document><Folder>
+    <Placemark>
+      <TimeStamp><when>2025-06-01T09:00:00Z</when></TimeStamp>
+      <Point><coordinates>-2.13,49.21,0</coordinates></Point>
+    </Placemark>
+    <Placemark>
+      <TimeSpan><begin>2025-06-02T09:00:00Z</begin><end>2025-06-02T10:00:00Z</end></TimeSpan>
+      <LineString><coordinates>-2.13,49.21 -2.55,49.45</coordinates></LineString>
+    </Placemark>
+    <Placemark>
+      <gx:Track>
+        <when>2025-06-03T09:00:00Z</when>
+        <gx:coord>-74.01 40.71 10</gx:coord>
+      </gx:Track>
+    </Placemark>
+  </Folder></Document>
+</kml>`,
+			want: []domain.TrackPoint{
+				{At: at("2025-06-01T09:00:00Z"), LatLng: [2]float64{49.21, -2.13}},
+				{At: at("2025-06-02T09:00:00Z"), LatLng: [2]float64{49.21, -2.13}},
+				{At: at("2025-06-02T09:00:00Z"), LatLng: [2]float64{49.45, -2.55}},
+				{At: at("2025-06-03T09:00:00Z"), LatLng: [2]float64{40.71, -74.01}},
+			},
+		},
+		{
+			name:   "geojson features",
+			format: TrackFormatGeoJSON,
+			body: `{
+  "type": "FeatureCollection",
+  "features": [
+    {"type": "Feature", "properties": {"time": "2025-06-01T09:00:00Z"}, "geometry": {"type": "Point", "coordinates": [-2.13, 49.21]}},
+    {"type": "Feature", "properties": {"coordTimes": ["2025-06-02T09:00:00Z", "2025-06-02T18:00:00Z"]}, "geometry": {"type": "LineString", "coordinates": [[-2.13, 49.21, 5], [-2.55, 49.45, 5]]}},
+    {"type": "Feature", "properties": {"timestamp": "2025-06-03T09:00:00Z"}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [0, 1], [1, 1], [0, 0]]]}}
+  ]
+}`,
+			want: []domain.TrackPoint{
+				{At: at("2025-06-01T09:00:00Z"), LatLng: [2]float64{49.21, -2.13}},
+				{At: at("2025-06-02T09:00:00Z"), LatLng: [2]float64{49.21, -2.13}},
+				{At: at("2025-06-02T18:00:00Z"), LatLng: [2]float64{49.45, -2.55}},
+			},
+		},
+		{
+			name:   "geojson geometry",
+			format: TrackFormatGeoJSON,
+			body:   `{"type": "MultiPoint", "coordinates": [[-2.13, 49.21]]}`,
+			want: []domain.TrackPoint{
+				{LatLng: [2]float64{49.21, -2.13}},
+			},
+		},
+		{
+			name:    "unknown format",
+			format:  "fit",
+			wantErr: domain.ValidationError("unknown track format: fit"),
+		},
+		{
+			name:    "no points",
+			format:  TrackFormatGPX,
+			body:    `<gpx></gpx>`,
+			wantErr: domain.ValidationError("gpx track has no points"),
+		},
+		{
+			name:    "point out of range",
+			format:  TrackFormatGPX,
+			body:    `<gpx><wpt lat="91" lon="0"/></gpx>`,
+			wantErr: domain.ValidationError("track point out of range: [91 0]"),
+		},
+		{
+			name:    "invalid time",
+			format:  TrackFormatGPX,
+			body:    `<gpx><wpt lat="49.21" lon="-2.13"><time>yesterday</time></wpt></gpx>`,
+			wantErr: domain.ValidationError("invalid track time: yesterday"),
+		},
+		{
+			name:    "kml track without a time for each coordinate",
+			format:  TrackFormatKML,
+			body:    `<kml><Placemark><Track><coord>-2.13 49.21 0</coord></Track></Placemark></kml>`,
+			wantErr: domain.ValidationError("kml track must have a time for each coordinate"),
+		},
+		{
+			name:    "geojson coordTimes mismatch",
+			format:  TrackFormatGeoJSON,
+			body:    `{"type": "Feature", "properties": {"coordTimes": ["2025-06-02T09:00:00Z"]}, "geometry": {"type": "LineString", "coordinates": [[-2.13, 49.21], [-2.55, 49.45]]}}`,
+			wantErr: domain.ValidationError("geojson coordTimes must have a time for each coordinate"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			points, err := ParseTrack(tc.format, strings.NewReader(tc.body))
+			if tc.wantErr != nil {
+				require.EqualError(t, err, tc.wantErr.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, points, len(tc.want))
+			for i := range tc.want {
+				require.True(t, tc.want[i].At.Equal(points[i].At), "point %d at %s, want %s", i, points[i].At, tc.want[i].At)
+				require.Equal(t, tc.want[i].LatLng, points[i].LatLng, "point %d", i)
+			}
+		})
+	}
+}
+
+func TestTrackFormatFromContentType(t *testing.T) {
+	format, ok := TrackFormatFromContentType("application/gpx+xml; charset=utf-8")
+	require.True(t, ok)
+	require.Equal(t, TrackFormatGPX, format)
+
+	format, ok = TrackFormatFromContentType("application/geo+json")
+	require.True(t, ok)
+	require.Equal(t, TrackFormatGeoJSON, format)
+
+	_, ok = TrackFormatFromContentType("application/json")
+	require.False(t, ok)
+}
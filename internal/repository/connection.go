@@ -11,4 +11,13 @@ type Connection interface {
 	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
 	Query(context.Context, string, ...any) (pgx.Rows, error)
 	QueryRow(context.Context, string, ...any) pgx.Row
+	Begin(context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs fn in a transaction, committing when fn succeeds and rolling back otherwise. Within
+// a transaction the nested transaction is a savepoint.
+func WithTx(ctx context.Context, conn Connection, fn func(tx Connection) error) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		return fn(tx)
+	})
 }
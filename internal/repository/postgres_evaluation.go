@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pumpkinlog/backend/internal/domain"
 )
@@ -25,17 +26,32 @@ func (r *postgresEvaluationRepository) fetch(ctx context.Context, query string,
 	evaluations := make([]*domain.RegionEvaluation, 0)
 
 	for rows.Next() {
-		var evaluation domain.RegionEvaluation
+		var (
+			evaluation domain.RegionEvaluation
+			details    []byte
+		)
+
 		if err := rows.Scan(
 			&evaluation.UserID,
 			&evaluation.RegionID,
 			&evaluation.Passed,
-			&evaluation.Nodes,
+			&evaluation.Status,
+			&evaluation.Reason,
+			&evaluation.Unresolved,
+			&details,
 			&evaluation.PointInTime,
 			&evaluation.EvaluatedAt,
 		); err != nil {
 			return nil, err
 		}
+
+		// Components are interfaces, so they are decoded by their type
+		nodes, err := domain.UnmarshalEvaluationComponents(details)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal evaluation details: %w", err)
+		}
+		evaluation.Nodes = nodes
+
 		evaluations = append(evaluations, &evaluation)
 	}
 
@@ -49,6 +65,9 @@ func (r *postgresEvaluationRepository) GetByID(ctx context.Context, userID int64
 			user_id,
 			region_id,
 			passed,
+			status,
+			reason,
+			unresolved,
 			details,
 			point_in_time,
 			evaluated_at
@@ -74,6 +93,9 @@ func (r *postgresEvaluationRepository) List(ctx context.Context, userID int64) (
 			user_id,
 			region_id,
 			passed,
+			status,
+			reason,
+			unresolved,
 			details,
 			point_in_time,
 			evaluated_at
@@ -91,10 +113,15 @@ func (r *postgresEvaluationRepository) List(ctx context.Context, userID int64) (
 func (r *postgresEvaluationRepository) CreateOrUpdate(ctx context.Context, evaluation *domain.RegionEvaluation) error {
 
 	query := `
-		INSERT INTO evaluations (user_id, region_id, passed, details, point_in_time, evaluated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO evaluations (user_id, region_id, passed, status, reason, unresolved, details, point_in_time, evaluated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (user_id, region_id) DO UPDATE
-		SET passed = $3, details = $4, point_in_time = $5, evaluated_at = $6`
+		SET passed = $3, status = $4, reason = $5, unresolved = $6, details = $7, point_in_time = $8, evaluated_at = $9`
+
+	unresolved := evaluation.Unresolved
+	if unresolved == nil {
+		unresolved = make([]domain.Code, 0)
+	}
 
 	_, err := r.conn.Exec(
 		ctx,
@@ -102,6 +129,9 @@ func (r *postgresEvaluationRepository) CreateOrUpdate(ctx context.Context, evalu
 		evaluation.UserID,
 		evaluation.RegionID,
 		evaluation.Passed,
+		evaluation.Status,
+		evaluation.Reason,
+		unresolved,
 		evaluation.Nodes,
 		evaluation.PointInTime,
 		evaluation.EvaluatedAt,
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type postgresPingRepository struct {
+	conn Connection
+}
+
+func NewPostgresPingRepository(conn Connection) domain.PingRepository {
+	return &postgresPingRepository{conn}
+}
+
+func (r *postgresPingRepository) fetch(ctx context.Context, query string, args ...any) ([]*domain.DevicePing, error) {
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pings := make([]*domain.DevicePing, 0)
+
+	for rows.Next() {
+		var (
+			ping     domain.DevicePing
+			lat, lng *float64
+		)
+		if err := rows.Scan(
+			&ping.ID,
+			&ping.UserID,
+			&ping.DeviceID,
+			&ping.At,
+			&lat,
+			&lng,
+			&ping.RegionID,
+			&ping.ReceivedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if lat != nil && lng != nil {
+			ping.LatLng = &[2]float64{*lat, *lng}
+		}
+
+		pings = append(pings, &ping)
+	}
+
+	return pings, nil
+}
+
+func (r *postgresPingRepository) CreateBatch(ctx context.Context, pings []*domain.DevicePing) (int, error) {
+
+	var (
+		userIDs    = make([]int64, len(pings))
+		deviceIDs  = make([]int64, len(pings))
+		ats        = make([]time.Time, len(pings))
+		lats       = make([]*float64, len(pings))
+		lngs       = make([]*float64, len(pings))
+		regionIDs  = make([]*string, len(pings))
+		receivedAt = make([]time.Time, len(pings))
+	)
+
+	for i, ping := range pings {
+		userIDs[i] = ping.UserID
+		deviceIDs[i] = ping.DeviceID
+		ats[i] = ping.At
+		receivedAt[i] = ping.ReceivedAt
+
+		if ping.LatLng != nil {
+			lats[i], lngs[i] = &ping.LatLng[0], &ping.LatLng[1]
+		}
+
+		if ping.RegionID != nil {
+			regionID := string(*ping.RegionID)
+			regionIDs[i] = &regionID
+		}
+	}
+
+	query := `
+			INSERT INTO device_pings (user_id, device_id, recorded_at, lat, lng, region_id, received_at)
+			SELECT * FROM unnest($1::bigint[], $2::bigint[], $3::timestamptz[], $4::float8[], $5::float8[], $6::text[], $7::timestamptz[])
+			ON CONFLICT (device_id, recorded_at) DO NOTHING`
+
+	tag, err := r.conn.Exec(ctx, query, userIDs, deviceIDs, ats, lats, lngs, regionIDs, receivedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *postgresPingRepository) ListByPeriod(ctx context.Context, userID, deviceID int64, start, end time.Time) ([]*domain.DevicePing, error) {
+
+	query := `
+			SELECT id, user_id, device_id, recorded_at, lat, lng, region_id, received_at
+			FROM device_pings
+			WHERE user_id = $1 AND device_id = $2 AND recorded_at BETWEEN $3 AND $4
+			ORDER BY recorded_at`
+
+	return r.fetch(ctx, query, userID, deviceID, start, end)
+}
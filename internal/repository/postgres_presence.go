@@ -34,6 +34,13 @@ func (p *postgresPresenceRepository) fetch(ctx context.Context, query string, ar
 			&presence.RegionID,
 			&presence.Date,
 			&presence.DeviceID,
+			&presence.Arrival,
+			&presence.Departure,
+			&presence.Transit,
+			&presence.Provenance,
+			&presence.Evidence,
+			&presence.Confirmed,
+			&presence.Ambiguous,
 			&presence.CreatedAt,
 			&presence.UpdatedAt,
 		); err != nil {
@@ -48,7 +55,7 @@ func (p *postgresPresenceRepository) fetch(ctx context.Context, query string, ar
 func (r *postgresPresenceRepository) GetByID(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) (*domain.Presence, error) {
 
 	query := `
-			SELECT user_id, region_id, date, device_id, created_at, updated_at
+			SELECT user_id, region_id, date, device_id, arrival, departure, transit, provenance, evidence, confirmed, ambiguous, created_at, updated_at
 			FROM presences
 			WHERE user_id = $1 AND region_id = $2 AND date = $3`
 
@@ -77,6 +84,13 @@ func (r *postgresPresenceRepository) List(ctx context.Context, userID int64, fil
 			region_id,
 			date,
 			device_id,
+			arrival,
+			departure,
+			transit,
+			provenance,
+			evidence,
+			confirmed,
+			ambiguous,
 			created_at,
 			updated_at
 		FROM presences
@@ -98,6 +112,12 @@ func (r *postgresPresenceRepository) List(ctx context.Context, userID int64, fil
 		query.WriteString(")")
 	}
 
+	if filter.Confirmed != nil {
+		query.WriteString(fmt.Sprintf(" AND confirmed = $%d", argIndex))
+		args = append(args, *filter.Confirmed)
+		argIndex++
+	}
+
 	if filter.Start != nil && filter.End != nil {
 		query.WriteString(fmt.Sprintf(" AND date BETWEEN $%d AND $%d", argIndex, argIndex+1))
 		args = append(args, *filter.Start, *filter.End)
@@ -114,15 +134,42 @@ func (r *postgresPresenceRepository) List(ctx context.Context, userID int64, fil
 	return r.fetch(ctx, query.String(), args...)
 }
 
-func (r *postgresPresenceRepository) ListByRegionPeriod(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) ([]*domain.Presence, error) {
+func (r *postgresPresenceRepository) ListByRegionPeriod(ctx context.Context, userID int64, regionIDs []domain.RegionID, start, end time.Time) ([]*domain.Presence, error) {
 
 	query := `
-		SELECT user_id, region_id, date, device_id, created_at, updated_at
+		SELECT user_id, region_id, date, device_id, arrival, departure, transit, provenance, evidence, confirmed, ambiguous, created_at, updated_at
 		FROM presences
-		WHERE user_id = $1 AND region_id = $2 AND date BETWEEN $3 AND $4
+		WHERE user_id = $1 AND region_id = ANY($2) AND date BETWEEN $3 AND $4
 		ORDER BY date`
 
-	return r.fetch(ctx, query, userID, regionID, start, end)
+	return r.fetch(ctx, query, userID, regionIDs, start, end)
+}
+
+func (r *postgresPresenceRepository) ListRegionIDs(ctx context.Context, userID int64) ([]domain.RegionID, error) {
+
+	query := `
+		SELECT DISTINCT region_id
+		FROM presences
+		WHERE user_id = $1
+		ORDER BY region_id`
+
+	rows, err := r.conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regionIDs := make([]domain.RegionID, 0)
+
+	for rows.Next() {
+		var regionID domain.RegionID
+		if err := rows.Scan(&regionID); err != nil {
+			return nil, err
+		}
+		regionIDs = append(regionIDs, regionID)
+	}
+
+	return regionIDs, rows.Err()
 }
 
 func (r *postgresPresenceRepository) Create(ctx context.Context, presence *domain.Presence) error {
@@ -132,8 +179,8 @@ func (r *postgresPresenceRepository) Create(ctx context.Context, presence *domai
 	}
 
 	query := `
-			INSERT INTO presences (user_id, region_id, date, device_id, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6)`
+			INSERT INTO presences (user_id, region_id, date, device_id, arrival, departure, transit, provenance, evidence, confirmed, ambiguous, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
 
 	_, err := r.conn.Exec(
 		ctx,
@@ -142,23 +189,68 @@ func (r *postgresPresenceRepository) Create(ctx context.Context, presence *domai
 		presence.RegionID,
 		presence.Date,
 		presence.DeviceID,
+		presence.Arrival,
+		presence.Departure,
+		presence.Transit,
+		presence.Provenance,
+		presence.Evidence,
+		presence.Confirmed,
+		presence.Ambiguous,
 		presence.CreatedAt,
 		presence.UpdatedAt,
 	)
 	return err
 }
 
-func (r *postgresPresenceRepository) CreateRange(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
+func (r *postgresPresenceRepository) CreateRange(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
+
+	if opts == nil {
+		opts = &domain.PresenceOpts{}
+	}
+
+	provenance := opts.Provenance
+	if provenance == "" {
+		provenance = domain.PresenceProvenanceManual
+	}
+
+	var evidence *string
+	if opts.Evidence != "" {
+		evidence = &opts.Evidence
+	}
 
+	// Travel markers are merged into existing days so that re-logging a range never clears them, and
+	// existing days keep the provenance, evidence and device they were first recorded with. A day
+	// entered by the user confirms a derived day, but a derived day never unconfirms one.
 	query := `
-			INSERT INTO presences (user_id, region_id, date, device_id, created_at, updated_at)
-			SELECT $1, $2, d::date, $3, $6, $7
+			INSERT INTO presences (user_id, region_id, date, device_id, arrival, departure, transit, provenance, evidence, confirmed, ambiguous, created_at, updated_at)
+			SELECT $1, $2, d::date, $3, $6 AND d::date = $4::date, $7 AND d::date = $5::date, $8, $9, $10, $11, $12, $13, $14
 			FROM generate_series($4::date, $5::date, '1 day') AS d
-            ON CONFLICT (user_id, region_id, date) DO NOTHING`
+            ON CONFLICT (user_id, region_id, date) DO UPDATE SET
+				arrival = presences.arrival OR EXCLUDED.arrival,
+				departure = presences.departure OR EXCLUDED.departure,
+				transit = presences.transit OR EXCLUDED.transit,
+				ambiguous = presences.ambiguous OR EXCLUDED.ambiguous,
+				confirmed = presences.confirmed OR EXCLUDED.confirmed,
+				evidence = COALESCE(presences.evidence, EXCLUDED.evidence),
+				device_id = COALESCE(presences.device_id, EXCLUDED.device_id),
+				updated_at = $14`
 
 	now := time.Now().UTC()
 
-	_, err := r.conn.Exec(ctx, query, userID, regionID, deviceID, start, end, now, now)
+	_, err := r.conn.Exec(ctx, query, userID, regionID, deviceID, start, end, opts.Arrival, opts.Departure, opts.Transit, provenance, evidence, provenance.Confirmed(), opts.Ambiguous, now, now)
+	return err
+}
+
+func (r *postgresPresenceRepository) ConfirmRange(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+
+	query := `
+			UPDATE presences
+			SET confirmed = $5, updated_at = $6
+			WHERE user_id = $1
+			AND region_id = $2
+			AND date BETWEEN $3 AND $4`
+
+	_, err := r.conn.Exec(ctx, query, userID, regionID, start, end, confirmed, time.Now().UTC())
 	return err
 }
 
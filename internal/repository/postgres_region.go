@@ -36,8 +36,12 @@ func (p *postgresRegionRepository) fetch(ctx context.Context, query string, args
 			&region.Continent,
 			&region.YearStartMonth,
 			&region.YearStartDay,
+			&region.TimeZone,
 			&region.LatLng,
 			&region.Sources,
+			&region.MemberRegionIDs,
+			&region.FiscalCalendar,
+			&region.Boundary,
 		); err != nil {
 			return nil, err
 		}
@@ -58,8 +62,12 @@ func (r *postgresRegionRepository) GetByID(ctx context.Context, regionID domain.
 			continent, 
 			year_start_month,
 			year_start_day,
+			time_zone,
 			lat_lng,
-			sources
+			sources,
+			member_region_ids,
+			fiscal_calendar,
+			boundary
 		FROM regions
 		WHERE id = $1`
 
@@ -96,12 +104,18 @@ func (r *postgresRegionRepository) List(ctx context.Context, filter *domain.Regi
 			continent, 
 			year_start_month,
 			year_start_day,
+			time_zone,
 			lat_lng,
-			sources
+			sources,
+			member_region_ids,
+			fiscal_calendar,
+			boundary
 		FROM regions`)
 
+	query.WriteString(" WHERE TRUE")
+
 	if len(filter.RegionIDs) > 0 {
-		query.WriteString(" WHERE id IN (")
+		query.WriteString(" AND id IN (")
 		for i, id := range filter.RegionIDs {
 			if i > 0 {
 				query.WriteString(", ")
@@ -113,11 +127,74 @@ func (r *postgresRegionRepository) List(ctx context.Context, filter *domain.Regi
 		query.WriteString(")")
 	}
 
+	if len(filter.MemberRegionIDs) > 0 {
+		query.WriteString(fmt.Sprintf(" AND member_region_ids && $%d", argIndex))
+		args = append(args, filter.MemberRegionIDs)
+	}
+
 	query.WriteString(" ORDER BY id")
 
 	return r.fetch(ctx, query.String(), args...)
 }
 
+func (r *postgresRegionRepository) ListDescendants(ctx context.Context, regionID domain.RegionID) ([]*domain.Region, error) {
+
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id FROM regions WHERE parent_region_id = $1
+			UNION
+			SELECT r.id FROM regions r JOIN descendants d ON r.parent_region_id = d.id
+		)
+		SELECT 
+			id, 
+			parent_region_id, 
+			region_type, 
+			name, 
+			continent, 
+			year_start_month,
+			year_start_day,
+			time_zone,
+			lat_lng,
+			sources,
+			member_region_ids,
+			fiscal_calendar,
+			boundary
+		FROM regions
+		WHERE id IN (SELECT id FROM descendants)
+		ORDER BY id`
+
+	return r.fetch(ctx, query, regionID)
+}
+
+func (r *postgresRegionRepository) ListAncestors(ctx context.Context, regionID domain.RegionID) ([]*domain.Region, error) {
+
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_region_id AS id FROM regions WHERE id = $1 AND parent_region_id IS NOT NULL
+			UNION
+			SELECT r.parent_region_id FROM regions r JOIN ancestors a ON r.id = a.id WHERE r.parent_region_id IS NOT NULL
+		)
+		SELECT 
+			id, 
+			parent_region_id, 
+			region_type, 
+			name, 
+			continent, 
+			year_start_month,
+			year_start_day,
+			time_zone,
+			lat_lng,
+			sources,
+			member_region_ids,
+			fiscal_calendar,
+			boundary
+		FROM regions
+		WHERE id IN (SELECT id FROM ancestors)
+		ORDER BY id`
+
+	return r.fetch(ctx, query, regionID)
+}
+
 func (r *postgresRegionRepository) CreateOrUpdate(ctx context.Context, region *domain.Region) error {
 
 	query := `
@@ -129,9 +206,13 @@ func (r *postgresRegionRepository) CreateOrUpdate(ctx context.Context, region *d
 			continent, 
 			year_start_month,
 			year_start_day,
+			time_zone,
 			lat_lng,
-			sources
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			sources,
+			member_region_ids,
+			fiscal_calendar,
+			boundary
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (id) DO UPDATE SET
 			parent_region_id = $2,
 			region_type = $3,
@@ -139,8 +220,12 @@ func (r *postgresRegionRepository) CreateOrUpdate(ctx context.Context, region *d
 			continent = $5,
 			year_start_month = $6,
 			year_start_day = $7,
-			lat_lng = $8,
-			sources = $9`
+			time_zone = $8,
+			lat_lng = $9,
+			sources = $10,
+			member_region_ids = $11,
+			fiscal_calendar = $12,
+			boundary = $13`
 
 	_, err := r.conn.Exec(ctx, query,
 		region.ID,
@@ -150,8 +235,12 @@ func (r *postgresRegionRepository) CreateOrUpdate(ctx context.Context, region *d
 		region.Continent,
 		region.YearStartMonth,
 		region.YearStartDay,
+		region.TimeZone,
 		region.LatLng,
 		region.Sources,
+		region.MemberRegionIDs,
+		region.FiscalCalendar,
+		region.Boundary,
 	)
 	return err
 }
@@ -34,6 +34,7 @@ func (p *postgresRuleRepository) fetch(ctx context.Context, query string, args .
 			&rule.Name,
 			&rule.Description,
 			&rule.Node,
+			&rule.IncludeSubregions,
 		); err != nil {
 			return nil, err
 		}
@@ -51,7 +52,8 @@ func (r *postgresRuleRepository) GetByID(ctx context.Context, ruleID domain.Code
 				region_id,
 				name,
 				description,
-				node
+				node,
+				include_subregions
 			FROM rules WHERE id = $1`
 
 	rules, err := r.fetch(ctx, query, ruleID)
@@ -83,7 +85,8 @@ func (r *postgresRuleRepository) List(ctx context.Context, filter *domain.RuleFi
 			region_id,
 			name,
 			description,
-			node
+			node,
+			include_subregions
 		FROM rules
 		GROUP BY id, region_id
 		ORDER BY id`)
@@ -113,7 +116,8 @@ func (r *postgresRuleRepository) ListByRegionID(ctx context.Context, regionID do
 				region_id,
 				name,
 				description,
-				node
+				node,
+				include_subregions
 		FROM rules
 		WHERE region_id = $1
 		GROUP BY id, region_id
@@ -122,22 +126,72 @@ func (r *postgresRuleRepository) ListByRegionID(ctx context.Context, regionID do
 	return r.fetch(ctx, query, regionID)
 }
 
+func (r *postgresRuleRepository) ListDependentRegionIDs(ctx context.Context, regionID domain.RegionID) ([]domain.RegionID, error) {
+
+	query := `
+		WITH RECURSIVE dependents AS (
+			SELECT r.id, r.region_id
+			FROM rules r
+			JOIN rules ref ON ref.id = ANY(r.rule_references)
+			WHERE ref.region_id = $1
+			UNION
+			SELECT r.id, r.region_id
+			FROM rules r
+			JOIN dependents d ON d.id = ANY(r.rule_references)
+		)
+		SELECT DISTINCT region_id FROM dependents
+		WHERE region_id <> $1
+		ORDER BY region_id`
+
+	rows, err := r.conn.Query(ctx, query, regionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regionIDs := make([]domain.RegionID, 0)
+
+	for rows.Next() {
+		var id domain.RegionID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		regionIDs = append(regionIDs, id)
+	}
+
+	return regionIDs, rows.Err()
+}
+
 func (r *postgresRuleRepository) CreateOrUpdate(ctx context.Context, rule *domain.Rule) error {
 
+	references, err := rule.Node.References()
+	if err != nil {
+		return err
+	}
+
+	if references == nil {
+		references = make([]domain.Code, 0)
+	}
+
 	query := `
 			INSERT INTO rules (
 				id,
 				region_id,
 				name,
 				description,
-				node
-			) VALUES ($1, $2, $3, $4, $5)
+				node,
+				include_subregions,
+				rule_references
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
 			ON CONFLICT (id) DO UPDATE SET
 				name = $3,
 				description = $4,
-				node = $5`
+				node = $5,
+				include_subregions = $6,
+				rule_references = $7`
 
-	_, err := r.conn.Exec(
+	_, err = r.conn.Exec(
 		ctx,
 		query,
 		rule.ID,
@@ -145,6 +199,8 @@ func (r *postgresRuleRepository) CreateOrUpdate(ctx context.Context, rule *domai
 		rule.Name,
 		rule.Description,
 		rule.Node,
+		rule.IncludeSubregions,
+		references,
 	)
 	return err
 }
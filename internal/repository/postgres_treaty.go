@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type postgresTreatyRepository struct {
+	conn Connection
+}
+
+func NewPostgresTreatyRepository(conn Connection) domain.TreatyRepository {
+	return &postgresTreatyRepository{conn}
+}
+
+func (r *postgresTreatyRepository) fetch(ctx context.Context, query string, args ...any) ([]*domain.Treaty, error) {
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	treaties := make([]*domain.Treaty, 0)
+
+	for rows.Next() {
+		var treaty domain.Treaty
+		if err := rows.Scan(
+			&treaty.ID,
+			&treaty.Name,
+			&treaty.RegionIDs[0],
+			&treaty.RegionIDs[1],
+			&treaty.Tests,
+			&treaty.Sources,
+		); err != nil {
+			return nil, err
+		}
+		treaties = append(treaties, &treaty)
+	}
+
+	return treaties, nil
+}
+
+func (r *postgresTreatyRepository) GetByID(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+
+	query := `
+		SELECT
+			id,
+			name,
+			region_a,
+			region_b,
+			tests,
+			sources
+		FROM treaties
+		WHERE id = $1`
+
+	treaties, err := r.fetch(ctx, query, treatyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(treaties) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	return treaties[0], nil
+}
+
+func (r *postgresTreatyRepository) List(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error) {
+
+	if filter == nil {
+		filter = new(domain.TreatyFilter)
+	}
+
+	query := `
+		SELECT
+			id,
+			name,
+			region_a,
+			region_b,
+			tests,
+			sources
+		FROM treaties
+		WHERE cardinality($1::TEXT[]) = 0 OR region_a = ANY($1) OR region_b = ANY($1)
+		ORDER BY id`
+
+	regionIDs := filter.RegionIDs
+	if regionIDs == nil {
+		regionIDs = make([]domain.RegionID, 0)
+	}
+
+	return r.fetch(ctx, query, regionIDs)
+}
+
+func (r *postgresTreatyRepository) CreateOrUpdate(ctx context.Context, treaty *domain.Treaty) error {
+
+	query := `
+		INSERT INTO treaties (id, name, region_a, region_b, tests, sources)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE
+		SET name = $2, region_a = $3, region_b = $4, tests = $5, sources = $6`
+
+	sources := treaty.Sources
+	if sources == nil {
+		sources = make([]domain.Source, 0)
+	}
+
+	_, err := r.conn.Exec(
+		ctx,
+		query,
+		treaty.ID,
+		treaty.Name,
+		treaty.RegionIDs[0],
+		treaty.RegionIDs[1],
+		treaty.Tests,
+		sources,
+	)
+	return err
+}
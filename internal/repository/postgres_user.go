@@ -108,3 +108,41 @@ func (r *postgresUserRepository) Delete(ctx context.Context, userID int64) error
 	_, err := r.conn.Exec(ctx, query, userID)
 	return err
 }
+
+func (r *postgresUserRepository) SetCalendarTokenHash(ctx context.Context, userID int64, hash string) error {
+
+	query := `
+			UPDATE users
+			SET calendar_token_hash = $2, updated_at = NOW()
+			WHERE id = $1`
+
+	tag, err := r.conn.Exec(ctx, query, userID, hash)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepository) GetByCalendarTokenHash(ctx context.Context, hash string) (*domain.User, error) {
+
+	query := `
+			SELECT id, favorite_regions, want_residency, created_at, updated_at
+			FROM users
+			WHERE calendar_token_hash = $1`
+
+	users, err := r.fetch(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	return &users[0], nil
+}
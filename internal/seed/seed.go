@@ -22,6 +22,8 @@ type Region struct {
 	domain.Region
 	Rules      []domain.Rule
 	Conditions []domain.Condition
+	// Treaties are declared on the first of the two regions they link.
+	Treaties []domain.Treaty
 }
 
 type SeedData []Region
@@ -51,6 +53,8 @@ func (s *Seeder) Seed(ctx context.Context, fileName string) error {
 	regionSvc := service.NewRegionService(s.logger, tx)
 	ruleSvc := service.NewRuleService(s.logger, tx)
 	conditionSvc := service.NewConditionService(s.logger, tx)
+	// Treaties are only upserted, so no evaluation service is needed
+	treatySvc := service.NewTreatyService(s.logger, tx, nil)
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -69,6 +73,11 @@ func (s *Seeder) Seed(ctx context.Context, fileName string) error {
 		return fmt.Errorf("cannot unmarshal seed data: %w", err)
 	}
 
+	var (
+		rules    []*domain.Rule
+		treaties []*domain.Treaty
+	)
+
 	for _, region := range regions {
 		if err := regionSvc.CreateOrUpdate(ctx, &region.Region); err != nil {
 			return fmt.Errorf("cannot upsert region: %w", err)
@@ -82,10 +91,7 @@ func (s *Seeder) Seed(ctx context.Context, fileName string) error {
 				return fmt.Errorf("cannot validate rule ID: %w", err)
 			}
 
-			if err := ruleSvc.CreateOrUpdate(ctx, &rule); err != nil {
-				s.logger.Error("cannot upsert rule", "region", region.ID, "id", rule.ID)
-				return fmt.Errorf("cannot upsert rule: %w", err)
-			}
+			rules = append(rules, &rule)
 		}
 
 		for _, condition := range region.Conditions {
@@ -101,6 +107,43 @@ func (s *Seeder) Seed(ctx context.Context, fileName string) error {
 				return fmt.Errorf("cannot upsert condition: %w", err)
 			}
 		}
+
+		for _, treaty := range region.Treaties {
+			treaty.ID = domain.Code(strings.ToUpper(string(treaty.ID)))
+
+			if err := validID(region.ID, treaty.ID); err != nil {
+				return fmt.Errorf("cannot validate treaty ID: %w", err)
+			}
+
+			for _, test := range treaty.Tests {
+				for regionID, conditionID := range test.Conditions {
+					test.Conditions[regionID] = domain.Code(strings.ToUpper(string(conditionID)))
+				}
+			}
+
+			treaties = append(treaties, &treaty)
+		}
+	}
+
+	// Rules are upserted after the rules they reference, which also catches reference cycles
+	rules, err = domain.SortRules(rules)
+	if err != nil {
+		return fmt.Errorf("cannot sort rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if err := ruleSvc.CreateOrUpdate(ctx, rule); err != nil {
+			s.logger.Error("cannot upsert rule", "region", rule.RegionID, "id", rule.ID)
+			return fmt.Errorf("cannot upsert rule: %w", err)
+		}
+	}
+
+	// Treaties are upserted last, as their conditions may belong to regions seeded later
+	for _, treaty := range treaties {
+		if err := treatySvc.CreateOrUpdate(ctx, treaty); err != nil {
+			s.logger.Error("cannot upsert treaty", "id", treaty.ID)
+			return fmt.Errorf("cannot upsert treaty: %w", err)
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
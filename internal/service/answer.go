@@ -16,6 +16,7 @@ type AnswerService struct {
 
 	conditionRepo  domain.ConditionRepository
 	answerRepo     domain.AnswerRepository
+	ruleRepo       domain.RuleRepository
 	evaluationRepo domain.EvaluationRepository
 }
 
@@ -25,6 +26,7 @@ func NewAnswerService(logger *slog.Logger, conn repository.Connection) domain.An
 
 		conditionRepo:  repository.NewPostgresConditionRepository(conn),
 		answerRepo:     repository.NewPostgresAnswerRepository(conn),
+		ruleRepo:       repository.NewPostgresRuleRepository(conn),
 		evaluationRepo: repository.NewPostgresEvaluationRepository(conn),
 	}
 }
@@ -62,12 +64,19 @@ func (s *AnswerService) CreateOrUpdate(ctx context.Context, userID int64, condit
 		return err
 	}
 
+	// Date answers bound split-year periods, so they are checked up front rather than at evaluation
+	if condition.Type == domain.ConditionTypeDate {
+		date, ok := value.(string)
+		if _, err := time.Parse(time.DateOnly, date); !ok || err != nil {
+			return domain.ValidationError("answer to %s must be a date formatted as YYYY-MM-DD", conditionID)
+		}
+	}
+
 	if err := s.answerRepo.CreateOrUpdate(ctx, answer); err != nil {
 		return fmt.Errorf("create or update answer: %w", err)
 	}
 
-	// Delete existing evaluations for the user region
-	if err := s.evaluationRepo.DeleteByUserAndRegionID(ctx, userID, condition.RegionID); err != nil {
+	if err := s.clearEvaluations(ctx, userID, condition.RegionID); err != nil {
 		return err
 	}
 
@@ -94,8 +103,7 @@ func (s *AnswerService) Delete(ctx context.Context, userID int64, conditionID do
 		return fmt.Errorf("delete answer: %w", err)
 	}
 
-	// Delete existing evaluations for the user region
-	if err := s.evaluationRepo.DeleteByUserAndRegionID(ctx, userID, answer.RegionID); err != nil {
+	if err := s.clearEvaluations(ctx, userID, answer.RegionID); err != nil {
 		return err
 	}
 
@@ -103,3 +111,20 @@ func (s *AnswerService) Delete(ctx context.Context, userID int64, conditionID do
 
 	return nil
 }
+
+// clearEvaluations deletes the user's evaluations for the region and for every region with rules
+// that reference the region's rules.
+func (s *AnswerService) clearEvaluations(ctx context.Context, userID int64, regionID domain.RegionID) error {
+	dependents, err := s.ruleRepo.ListDependentRegionIDs(ctx, regionID)
+	if err != nil {
+		return fmt.Errorf("list dependent regions: %w", err)
+	}
+
+	for _, id := range append([]domain.RegionID{regionID}, dependents...) {
+		if err := s.evaluationRepo.DeleteByUserAndRegionID(ctx, userID, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/repository"
+)
+
+// calendarTokenBytes is the number of random bytes in a calendar feed token.
+const calendarTokenBytes = 32
+
+type CalendarService struct {
+	logger *slog.Logger
+
+	presenceSvc   domain.PresenceService
+	evaluationSvc domain.EvaluationService
+
+	userRepo   domain.UserRepository
+	regionRepo domain.RegionRepository
+}
+
+// NewCalendarService builds on the presence and evaluation services, whose presences and remaining
+// days make up the feed.
+func NewCalendarService(logger *slog.Logger, conn repository.Connection, presenceSvc domain.PresenceService, evaluationSvc domain.EvaluationService) domain.CalendarService {
+	return &CalendarService{
+		logger: logger,
+
+		presenceSvc:   presenceSvc,
+		evaluationSvc: evaluationSvc,
+
+		userRepo:   repository.NewPostgresUserRepository(conn),
+		regionRepo: repository.NewPostgresRegionRepository(conn),
+	}
+}
+
+// CreateFeedToken stores only the hash of the token, so a leaked database does not expose feeds.
+func (s *CalendarService) CreateFeedToken(ctx context.Context, userID int64) (string, error) {
+	if userID < 0 {
+		return "", fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	b := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate calendar token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.userRepo.SetCalendarTokenHash(ctx, userID, hashCalendarToken(token)); err != nil {
+		return "", fmt.Errorf("set calendar token: %w", err)
+	}
+
+	s.logger.Debug("created calendar feed token", "userId", userID)
+
+	return token, nil
+}
+
+func (s *CalendarService) Feed(ctx context.Context, token string) (*domain.CalendarFeed, error) {
+	if token == "" {
+		return nil, domain.ErrNotFound
+	}
+
+	user, err := s.userRepo.GetByCalendarTokenHash(ctx, hashCalendarToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	presences, err := s.presenceSvc.List(ctx, user.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list presences: %w", err)
+	}
+
+	evaluations, err := s.evaluationSvc.EvaluateRegions(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate regions: %w", err)
+	}
+
+	regions, err := s.regionRepo.List(ctx, &domain.RegionFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list regions: %w", err)
+	}
+
+	byID := make(map[domain.RegionID]*domain.Region, len(regions))
+	for _, region := range regions {
+		byID[region.ID] = region
+	}
+
+	return domain.NewCalendarFeed(byID, presences, evaluations), nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
@@ -23,9 +24,11 @@ type EvaluationService struct {
 
 	regionRepo     domain.RegionRepository
 	ruleRepo       domain.RuleRepository
+	conditionRepo  domain.ConditionRepository
 	answerRepo     domain.AnswerRepository
 	evaluationRepo domain.EvaluationRepository
 	presenceRepo   domain.PresenceRepository
+	userRepo       domain.UserRepository
 }
 
 func NewEvaluationService(logger *slog.Logger, conn repository.Connection, ch *amqp091.Channel) domain.EvaluationService {
@@ -36,9 +39,11 @@ func NewEvaluationService(logger *slog.Logger, conn repository.Connection, ch *a
 
 		regionRepo:     repository.NewPostgresRegionRepository(conn),
 		ruleRepo:       repository.NewPostgresRuleRepository(conn),
+		conditionRepo:  repository.NewPostgresConditionRepository(conn),
 		answerRepo:     repository.NewPostgresAnswerRepository(conn),
 		evaluationRepo: repository.NewPostgresEvaluationRepository(conn),
 		presenceRepo:   repository.NewPostgresPresenceRepository(conn),
+		userRepo:       repository.NewPostgresUserRepository(conn),
 	}
 }
 
@@ -48,7 +53,10 @@ func NewEvaluationService(logger *slog.Logger, conn repository.Connection, ch *a
 // A point-in-time (PIT) date can be provided to evaluate the region as of that specific time.
 func (s *EvaluationService) EvaluateRegion(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error) {
 
-	if !opts.Recompute {
+	// Cached evaluations count every day, so evaluations of confirmed days only are never cached
+	cacheable := !opts.ExcludeUnconfirmed
+
+	if cacheable && !opts.Recompute && !opts.Explain {
 		evaluation, err := s.evaluationRepo.GetByID(ctx, userID, regionID)
 		if err != nil && !errors.Is(err, domain.ErrNotFound) {
 			return nil, fmt.Errorf("get evaluation by ID: %w", err)
@@ -64,29 +72,38 @@ func (s *EvaluationService) EvaluateRegion(ctx context.Context, userID int64, re
 	timestamp := time.Now().UTC()
 
 	if opts.PointInTime.IsZero() {
-		opts.PointInTime = timestamp
+		pit, err := s.regionNow(ctx, regionID, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		opts.PointInTime = pit
 	}
 
-	evalCtx, err := s.buildEvaluationContext(ctx, userID, regionID, opts.PointInTime)
+	evalCtx, err := s.buildEvaluationContext(ctx, userID, regionID, opts.PointInTime, opts.PointInTime)
 	if err != nil {
 		return nil, fmt.Errorf("load aggregate: %w", err)
 	}
 
-	details, passed, err := s.engine.EvaluateRegion(evalCtx)
+	if opts.ExcludeUnconfirmed {
+		dropUnconfirmed(evalCtx)
+	}
+
+	evaluation, err := s.engine.EvaluateRegion(evalCtx)
 	if err != nil {
 		return nil, fmt.Errorf("evaluation service: evaluate region: %w", err)
 	}
 
-	evaluation := &domain.RegionEvaluation{
-		RegionID:    regionID,
-		UserID:      userID,
-		Passed:      passed,
-		Nodes:       details,
-		PointInTime: opts.PointInTime,
-		EvaluatedAt: timestamp,
+	evaluation.UserID = userID
+	evaluation.EvaluatedAt = timestamp
+
+	if opts.Explain {
+		evaluation.Explanations, err = s.engine.Explain(evalCtx, evaluation)
+		if err != nil {
+			return nil, fmt.Errorf("evaluation service: explain region: %w", err)
+		}
 	}
 
-	if opts.Cache {
+	if cacheable && opts.Cache {
 		if err := s.evaluationRepo.CreateOrUpdate(ctx, evaluation); err != nil {
 			return nil, fmt.Errorf("create or update evaluation: %w", err)
 		}
@@ -116,13 +133,219 @@ func (s *EvaluationService) EvaluateRegion(ctx context.Context, userID int64, re
 	return evaluation, nil
 }
 
-func (s *EvaluationService) buildEvaluationContext(ctx context.Context, userID int64, regionID domain.RegionID, pit time.Time) (*domain.EvaluationContext, error) {
+// EvaluateRegions evaluates every region relevant to the user: regions with presences, and the
+// user's favorite and wanted residency regions. Cached evaluations are used while they are fresh,
+// and regions that fail to evaluate are reported with an error status rather than failing the rest.
+func (s *EvaluationService) EvaluateRegions(ctx context.Context, userID int64) ([]*domain.RegionEvaluation, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	presenceRegionIDs, err := s.presenceRepo.ListRegionIDs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list presence region IDs: %w", err)
+	}
+
+	cached, err := s.evaluationRepo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list evaluations: %w", err)
+	}
+
+	timestamp := time.Now().UTC()
+
+	// Cached evaluations are fresh on the calendar day of their region, so load their time zones
+	cachedRegionIDs := make([]domain.RegionID, len(cached))
+	for i, evaluation := range cached {
+		cachedRegionIDs[i] = evaluation.RegionID
+	}
+
+	regions := make(map[domain.RegionID]*domain.Region, len(cached))
+	if len(cachedRegionIDs) > 0 {
+		list, err := s.regionRepo.List(ctx, &domain.RegionFilter{RegionIDs: cachedRegionIDs})
+		if err != nil {
+			return nil, fmt.Errorf("list regions: %w", err)
+		}
+
+		for _, region := range list {
+			regions[region.ID] = region
+		}
+	}
+
+	fresh := make(map[domain.RegionID]*domain.RegionEvaluation, len(cached))
+	for _, evaluation := range cached {
+		region, ok := regions[evaluation.RegionID]
+		if ok && evaluation.IsFresh(region, timestamp) {
+			fresh[evaluation.RegionID] = evaluation
+		}
+	}
+
+	var (
+		regionIDs []domain.RegionID
+		seen      = make(map[domain.RegionID]struct{})
+	)
+
+	for _, ids := range [][]domain.RegionID{presenceRegionIDs, user.WantResidency, user.FavoriteRegions} {
+		for _, regionID := range ids {
+			if _, ok := seen[regionID]; !ok {
+				seen[regionID] = struct{}{}
+				regionIDs = append(regionIDs, regionID)
+			}
+		}
+	}
+
+	evaluations := make([]*domain.RegionEvaluation, len(regionIDs))
+
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(domain.MaxConcurrentEvaluations)
+
+	for i, regionID := range regionIDs {
+		if evaluation, ok := fresh[regionID]; ok {
+			evaluations[i] = evaluation
+			continue
+		}
+
+		g.Go(func() error {
+			// Each region is evaluated as of today in its own time zone
+			opts := &domain.EvaluateOpts{
+				Recompute: true,
+				Cache:     true,
+			}
+
+			evaluation, err := s.EvaluateRegion(groupCtx, userID, regionID, opts)
+			if err != nil {
+				if groupCtx.Err() != nil {
+					return groupCtx.Err()
+				}
+
+				s.logger.Error("failed to evaluate region", "userId", userID, "regionId", regionID, "error", err)
+
+				reason := "failed to evaluate region"
+				if errors.Is(err, domain.ErrNotFound) {
+					reason = "region not found"
+				}
+
+				evaluation = &domain.RegionEvaluation{
+					RegionID:    regionID,
+					UserID:      userID,
+					Status:      domain.EvaluationStatusError,
+					Reason:      reason,
+					Nodes:       make([]domain.EvaluationComponent, 0),
+					PointInTime: timestamp,
+					EvaluatedAt: timestamp,
+				}
+			}
+
+			evaluations[i] = evaluation
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("evaluate regions: %w", err)
+	}
+
+	return evaluations, nil
+}
+
+// regionNow returns the wall clock time in the region at the timestamp, so evaluations made as of
+// now count the region's current calendar day.
+func (s *EvaluationService) regionNow(ctx context.Context, regionID domain.RegionID, timestamp time.Time) (time.Time, error) {
+	region, err := s.regionRepo.GetByID(ctx, regionID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get region: %w", err)
+	}
+
+	return region.LocalTime(timestamp), nil
+}
+
+// buildEvaluationContext loads everything needed to evaluate the region at the point in time. Presences
+// are loaded to cover every point in time up to until, so the context can be evaluated across a range.
+func (s *EvaluationService) buildEvaluationContext(ctx context.Context, userID int64, regionID domain.RegionID, pit, until time.Time) (*domain.EvaluationContext, error) {
+	ec, err := s.loadRegionContext(ctx, userID, regionID, pit, until)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadDependencies(ctx, userID, ec, pit, until); err != nil {
+		return nil, fmt.Errorf("load rule dependencies: %w", err)
+	}
+
+	return ec, nil
+}
+
+// loadDependencies loads the contexts of other regions whose rules are referenced, directly or
+// transitively, by the rules of the evaluated region. References that do not resolve are left to
+// the engine to report.
+func (s *EvaluationService) loadDependencies(ctx context.Context, userID int64, ec *domain.EvaluationContext, pit, until time.Time) error {
+	loaded := make(map[domain.Code]struct{})
+
+	var pending []domain.Code
+	enqueue := func(rules []*domain.Rule) error {
+		for _, rule := range rules {
+			loaded[rule.ID] = struct{}{}
+		}
+
+		for _, rule := range rules {
+			references, err := rule.Node.References()
+			if err != nil {
+				return fmt.Errorf("rule %s references: %w", rule.ID, err)
+			}
+			pending = append(pending, references...)
+		}
+
+		return nil
+	}
+
+	if err := enqueue(ec.Rules); err != nil {
+		return err
+	}
+
+	for len(pending) > 0 {
+		ruleID := pending[0]
+		pending = pending[1:]
+
+		if _, ok := loaded[ruleID]; ok {
+			continue
+		}
+
+		rule, err := s.ruleRepo.GetByID(ctx, ruleID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get rule %s: %w", ruleID, err)
+		}
+
+		dep, err := s.loadRegionContext(ctx, userID, rule.RegionID, pit, until)
+		if err != nil {
+			return fmt.Errorf("region %s: %w", rule.RegionID, err)
+		}
+
+		if ec.Dependencies == nil {
+			ec.Dependencies = make(map[domain.RegionID]*domain.EvaluationContext)
+		}
+		ec.Dependencies[rule.RegionID] = dep
+
+		if err := enqueue(dep.Rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadRegionContext loads the region, its rules, conditions, the user's answers and the presences
+// needed to evaluate the region's rules.
+func (s *EvaluationService) loadRegionContext(ctx context.Context, userID int64, regionID domain.RegionID, pit, until time.Time) (*domain.EvaluationContext, error) {
 	g, groupCtx := errgroup.WithContext(ctx)
 
 	var (
-		region  *domain.Region
-		rules   []*domain.Rule
-		answers []*domain.Answer
+		region     *domain.Region
+		subregions []*domain.Region
+		rules      []*domain.Rule
+		conditions []*domain.Condition
+		answers    []*domain.Answer
 	)
 
 	g.Go(func() error {
@@ -134,6 +357,15 @@ func (s *EvaluationService) buildEvaluationContext(ctx context.Context, userID i
 		return nil
 	})
 
+	g.Go(func() error {
+		r, err := s.regionRepo.ListDescendants(groupCtx, regionID)
+		if err != nil {
+			return fmt.Errorf("list region descendants: %w", err)
+		}
+		subregions = r
+		return nil
+	})
+
 	g.Go(func() error {
 		r, err := s.ruleRepo.ListByRegionID(groupCtx, regionID)
 		if err != nil {
@@ -143,6 +375,15 @@ func (s *EvaluationService) buildEvaluationContext(ctx context.Context, userID i
 		return nil
 	})
 
+	g.Go(func() error {
+		c, err := s.conditionRepo.ListByRegionID(groupCtx, regionID)
+		if err != nil {
+			return fmt.Errorf("list conditions by region ID: %w", err)
+		}
+		conditions = c
+		return nil
+	})
+
 	g.Go(func() error {
 		a, err := s.answerRepo.ListByRegionID(groupCtx, userID, regionID)
 		if err != nil {
@@ -156,28 +397,214 @@ func (s *EvaluationService) buildEvaluationContext(ctx context.Context, userID i
 		return nil, fmt.Errorf("load evaluation context data: %w", err)
 	}
 
-	start, end, err := engine.ComputeMaxPeriod(pit, region, rules)
+	start, end, err := s.engine.ComputeMaxPeriod(pit, region, rules)
 	if err != nil {
 		return nil, fmt.Errorf("compute max period: %w", err)
 	}
 
-	presences, err := s.presenceRepo.ListByRegionPeriod(ctx, userID, region.ID, start, end)
+	if until.After(pit) {
+		_, untilEnd, err := s.engine.ComputeMaxPeriod(until, region, rules)
+		if err != nil {
+			return nil, fmt.Errorf("compute max period: %w", err)
+		}
+
+		end = untilEnd
+	}
+
+	subregionIDs := make([]domain.RegionID, len(subregions))
+	for i, r := range subregions {
+		subregionIDs[i] = r.ID
+	}
+
+	// Subregion presences are only loaded when at least one rule rolls them up
+	regionIDs := region.RegionIDs()
+	for _, rule := range rules {
+		if rule.IncludeSubregions {
+			regionIDs = append(regionIDs, subregionIDs...)
+			break
+		}
+	}
+
+	presences, err := s.presenceRepo.ListByRegionPeriod(ctx, userID, regionIDs, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("list presences by region period: %w", err)
 	}
 
+	conditionMap := make(map[domain.Code]*domain.Condition, len(conditions))
+	for _, c := range conditions {
+		conditionMap[c.ID] = c
+	}
+
 	answerMap := make(map[domain.Code]*domain.Answer, len(answers))
 	for _, a := range answers {
 		answerMap[a.ConditionID] = a
 	}
 
 	ec := &domain.EvaluationContext{
-		At:        pit,
-		Region:    region,
-		Presences: presences,
-		Rules:     rules,
-		Answers:   answerMap,
+		At:           pit,
+		Region:       region,
+		SubregionIDs: subregionIDs,
+		Presences:    presences,
+		Rules:        rules,
+		Conditions:   conditionMap,
+		Answers:      answerMap,
 	}
 
 	return ec, nil
 }
+
+// Forecast projects the region evaluation across future days, assuming the user travels as planned
+// or stays in the region continuously, and reports the first day the region and each rule change outcome.
+func (s *EvaluationService) Forecast(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+	if opts.From.IsZero() {
+		from, err := s.regionNow(ctx, regionID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		opts.From = from
+	}
+
+	opts.From = opts.From.UTC().Truncate(24 * time.Hour)
+	opts.Until = opts.Until.UTC().Truncate(24 * time.Hour)
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	evalCtx, err := s.buildEvaluationContext(ctx, userID, regionID, opts.From, opts.Until)
+	if err != nil {
+		return nil, fmt.Errorf("load aggregate: %w", err)
+	}
+
+	planned := opts.Planned
+	if opts.Stay {
+		planned = append(planned, domain.PlannedPresence{RegionID: regionID, Start: opts.From, End: opts.Until})
+	}
+
+	addPresences(evalCtx, userID, planned)
+
+	forecast, err := s.engine.Forecast(evalCtx, opts.From, opts.Until)
+	if err != nil {
+		return nil, fmt.Errorf("evaluation service: forecast: %w", err)
+	}
+
+	return forecast, nil
+}
+
+// Timeline evaluates the region on every day, or every period boundary, across the range and returns
+// the intervals where the region passed or not, with the rules that triggered each transition.
+func (s *EvaluationService) Timeline(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+	if opts.Step == "" {
+		opts.Step = domain.TimelineStepDay
+	}
+
+	opts.From = opts.From.UTC().Truncate(24 * time.Hour)
+	opts.Until = opts.Until.UTC().Truncate(24 * time.Hour)
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	evalCtx, err := s.buildEvaluationContext(ctx, userID, regionID, opts.From, opts.Until)
+	if err != nil {
+		return nil, fmt.Errorf("load aggregate: %w", err)
+	}
+
+	timeline, err := s.engine.Timeline(evalCtx, opts.From, opts.Until, opts.Step)
+	if err != nil {
+		return nil, fmt.Errorf("evaluation service: timeline: %w", err)
+	}
+
+	return timeline, nil
+}
+
+// WhatIf evaluates every region affected by the hypothetical trips with and without them, and reports
+// which regions and rules would change outcome. Nothing is persisted.
+func (s *EvaluationService) WhatIf(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.PointInTime.IsZero() {
+		for _, trip := range opts.Trips {
+			if trip.End.After(opts.PointInTime) {
+				opts.PointInTime = trip.End
+			}
+		}
+	}
+
+	var (
+		regionIDs []domain.RegionID
+		seen      = make(map[domain.RegionID]struct{})
+	)
+
+	for _, trip := range opts.Trips {
+		affected, err := affectedRegionIDs(ctx, s.regionRepo, s.ruleRepo, trip.RegionID)
+		if err != nil {
+			return nil, fmt.Errorf("list affected regions: %w", err)
+		}
+
+		for _, id := range affected {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				regionIDs = append(regionIDs, id)
+			}
+		}
+	}
+
+	whatIf := &domain.WhatIf{
+		PointInTime: opts.PointInTime,
+		Regions:     make([]*domain.RegionDiff, 0, len(regionIDs)),
+	}
+
+	for _, regionID := range regionIDs {
+		evalCtx, err := s.buildEvaluationContext(ctx, userID, regionID, opts.PointInTime, opts.PointInTime)
+		if err != nil {
+			return nil, fmt.Errorf("load aggregate for region %s: %w", regionID, err)
+		}
+
+		before, err := s.engine.EvaluateRegion(evalCtx)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate region %s: %w", regionID, err)
+		}
+
+		addPresences(evalCtx, userID, opts.Trips)
+
+		after, err := s.engine.EvaluateRegion(evalCtx)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate region %s with trips: %w", regionID, err)
+		}
+
+		diff := domain.NewRegionDiff(before, after, evalCtx.Rules)
+		whatIf.Regions = append(whatIf.Regions, diff)
+	}
+
+	return whatIf, nil
+}
+
+// addPresences overlays planned presences onto the context and its dependencies in memory. The
+// engine only counts presences within the scope of each rule, so every context gets all of them.
+func addPresences(ec *domain.EvaluationContext, userID int64, planned []domain.PlannedPresence) {
+	var presences []*domain.Presence
+	for _, p := range planned {
+		presences = append(presences, p.Presences(userID)...)
+	}
+
+	ec.Presences = append(ec.Presences, presences...)
+	for _, dep := range ec.Dependencies {
+		dep.Presences = append(dep.Presences, presences...)
+	}
+}
+
+// dropUnconfirmed removes the days the user has not confirmed from the context and its dependencies
+// in memory.
+func dropUnconfirmed(ec *domain.EvaluationContext) {
+	unconfirmed := func(p *domain.Presence) bool {
+		return !p.Confirmed
+	}
+
+	ec.Presences = slices.DeleteFunc(ec.Presences, unconfirmed)
+	for _, dep := range ec.Dependencies {
+		dep.Presences = slices.DeleteFunc(dep.Presences, unconfirmed)
+	}
+}
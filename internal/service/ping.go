@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/geo"
+	"github.com/pumpkinlog/backend/internal/repository"
+)
+
+// pingFoldMargin widens the period a fold lists pings over, so every ping on the region-local days
+// of the folded pings is seen whatever the time zones of the regions.
+const pingFoldMargin = 48 * time.Hour
+
+// PingMessage queues the period of a batch of pings to be folded into presences.
+type PingMessage struct {
+	UserID   int64     `json:"userId"`
+	DeviceID int64     `json:"deviceId"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+type PingService struct {
+	logger *slog.Logger
+	ch     *amqp091.Channel
+
+	presenceSvc domain.PresenceService
+
+	pingRepo   domain.PingRepository
+	deviceRepo domain.DeviceRepository
+	regionRepo domain.RegionRepository
+}
+
+// NewPingService builds on the presence service, which records the folded days and clears the
+// evaluations they affect.
+func NewPingService(logger *slog.Logger, conn repository.Connection, ch *amqp091.Channel, presenceSvc domain.PresenceService) domain.PingService {
+	return &PingService{
+		logger: logger,
+		ch:     ch,
+
+		presenceSvc: presenceSvc,
+
+		pingRepo:   repository.NewPostgresPingRepository(conn),
+		deviceRepo: repository.NewPostgresDeviceRepository(conn),
+		regionRepo: repository.NewPostgresRegionRepository(conn),
+	}
+}
+
+func (s *PingService) Record(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error) {
+	if userID < 0 {
+		return nil, fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	if deviceID < 0 {
+		return nil, fmt.Errorf("%w: device ID cannot be negative", domain.ErrValidation)
+	}
+
+	if len(pings) == 0 {
+		return nil, fmt.Errorf("%w: batch has no pings", domain.ErrValidation)
+	}
+
+	if len(pings) > domain.MaxPingBatch {
+		return nil, fmt.Errorf("%w: batch cannot have more than %d pings", domain.ErrValidation, domain.MaxPingBatch)
+	}
+
+	if _, err := s.deviceRepo.GetByID(ctx, userID, deviceID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	for i, ping := range pings {
+		ping.UserID = userID
+		ping.DeviceID = deviceID
+		ping.ReceivedAt = now
+
+		if err := ping.Validate(); err != nil {
+			return nil, fmt.Errorf("ping %d: %w", i, err)
+		}
+	}
+
+	// Only the first ping the batch holds for a time is stored, as with pings sent in earlier batches
+	batch := slices.Clone(pings)
+	slices.SortStableFunc(batch, func(a, b *domain.DevicePing) int {
+		return a.At.Compare(b.At)
+	})
+	batch = slices.CompactFunc(batch, func(a, b *domain.DevicePing) bool {
+		return a.At.Equal(b.At)
+	})
+
+	stored, err := s.pingRepo.CreateBatch(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("create pings: %w", err)
+	}
+
+	receipt := &domain.PingReceipt{
+		Received:   len(pings),
+		Stored:     stored,
+		Duplicates: len(pings) - stored,
+	}
+
+	// Duplicates are folded too, so a batch resent after a failed publish is still folded
+	msg := PingMessage{
+		UserID:   userID,
+		DeviceID: deviceID,
+		Start:    batch[0].At,
+		End:      batch[len(batch)-1].At,
+	}
+
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal ping message: %w", err)
+	}
+
+	publishing := amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Body:         encoded,
+	}
+
+	if err := s.ch.PublishWithContext(ctx, "device.events", "ping.received", false, false, publishing); err != nil {
+		return nil, fmt.Errorf("publish pings: %w", err)
+	}
+
+	s.logger.Debug("recorded pings", "userId", userID, "deviceId", deviceID, "received", receipt.Received, "stored", receipt.Stored)
+
+	return receipt, nil
+}
+
+func (s *PingService) Fold(ctx context.Context, userID, deviceID int64, start, end time.Time) error {
+	if start.After(end) {
+		return fmt.Errorf("%w: start cannot be after end", domain.ErrValidation)
+	}
+
+	pings, err := s.pingRepo.ListByPeriod(ctx, userID, deviceID, start.Add(-pingFoldMargin), end.Add(pingFoldMargin))
+	if err != nil {
+		return fmt.Errorf("list pings: %w", err)
+	}
+
+	if len(pings) == 0 {
+		return nil
+	}
+
+	regions, err := s.regionRepo.List(ctx, &domain.RegionFilter{})
+	if err != nil {
+		return fmt.Errorf("list regions: %w", err)
+	}
+
+	rows, skipped := geo.NewIndex(regions).PingRows(pings)
+
+	// Presences are upserted, so a fold that fails is completed when the message is redelivered
+	if len(rows) > 0 {
+		if err := s.presenceSvc.CreateRows(ctx, userID, &deviceID, rows); err != nil {
+			return fmt.Errorf("create presences: %w", err)
+		}
+	}
+
+	s.logger.Debug("folded pings", "userId", userID, "deviceId", deviceID, "pings", len(pings), "skipped", skipped, "rows", len(rows))
+
+	return nil
+}
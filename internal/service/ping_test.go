@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/test/mocks"
+)
+
+func TestPingServiceFold(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.June, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	date := func(day int) time.Time {
+		return time.Date(2025, time.June, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	regionID := func(id domain.RegionID) *domain.RegionID {
+		return &id
+	}
+
+	pings := []*domain.DevicePing{
+		{ID: 1, At: at(1, 9), RegionID: regionID("JE")},
+		{ID: 2, At: at(2, 9), RegionID: regionID("JE")},
+		{ID: 3, At: at(3, 9), RegionID: regionID("GG")},
+	}
+
+	var (
+		calls    int
+		deviceID *int64
+		rows     []*domain.ImportRow
+	)
+
+	s := &PingService{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+
+		presenceSvc: mocks.PresenceService{
+			CreateRowsFunc: func(ctx context.Context, userID int64, id *int64, r []*domain.ImportRow) error {
+				calls++
+				deviceID, rows = id, r
+				return nil
+			},
+		},
+
+		pingRepo: mocks.PingRepo{
+			ListByPeriodFunc: func(ctx context.Context, userID, deviceID int64, start, end time.Time) ([]*domain.DevicePing, error) {
+				return pings, nil
+			},
+		},
+		regionRepo: mocks.RegionRepo{
+			ListFunc: func(ctx context.Context, filter *domain.RegionFilter) ([]*domain.Region, error) {
+				return []*domain.Region{
+					{ID: "JE", Type: domain.RegionTypeCountry},
+					{ID: "GG", Type: domain.RegionTypeCountry},
+				}, nil
+			},
+		},
+	}
+
+	err := s.Fold(context.Background(), 1, 7, at(1, 9), at(3, 9))
+	require.NoError(t, err)
+
+	// Every folded row is written in one call, so evaluations are only cleared once
+	require.Equal(t, 1, calls)
+	require.Equal(t, int64(7), *deviceID)
+	require.Equal(t, []*domain.ImportRow{
+		{RegionID: "JE", Start: date(1), End: date(2), Provenance: domain.PresenceProvenanceGPS, Evidence: "ping:1"},
+		{RegionID: "GG", Start: date(3), End: date(3), Provenance: domain.PresenceProvenanceGPS, Evidence: "ping:3"},
+	}, rows)
+}
@@ -1,22 +1,29 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 
 	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/geo"
 	"github.com/pumpkinlog/backend/internal/repository"
 )
 
 type PresenceService struct {
 	logger *slog.Logger
+	conn   repository.Connection
 	ch     *amqp091.Channel
 
+	regionRepo     domain.RegionRepository
+	ruleRepo       domain.RuleRepository
 	evaluationRepo domain.EvaluationRepository
 	presenceRepo   domain.PresenceRepository
 }
@@ -24,8 +31,11 @@ type PresenceService struct {
 func NewPresenceService(logger *slog.Logger, conn repository.Connection, ch *amqp091.Channel) domain.PresenceService {
 	return &PresenceService{
 		logger: logger,
+		conn:   conn,
 		ch:     ch,
 
+		regionRepo:     repository.NewPostgresRegionRepository(conn),
+		ruleRepo:       repository.NewPostgresRuleRepository(conn),
 		evaluationRepo: repository.NewPostgresEvaluationRepository(conn),
 		presenceRepo:   repository.NewPostgresPresenceRepository(conn),
 	}
@@ -59,7 +69,7 @@ func (s *PresenceService) List(ctx context.Context, userID int64, filter *domain
 	return s.presenceRepo.List(ctx, userID, filter)
 }
 
-func (s *PresenceService) Create(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
+func (s *PresenceService) Create(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
 	if userID < 0 {
 		return fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
 	}
@@ -84,39 +94,391 @@ func (s *PresenceService) Create(ctx context.Context, userID int64, regionID dom
 		return fmt.Errorf("end cannot be before start: %w", domain.ErrValidation)
 	}
 
-	if err := s.presenceRepo.CreateRange(ctx, userID, regionID, deviceID, start, end); err != nil {
-		return fmt.Errorf("create presence range: %w", err)
+	if opts == nil {
+		opts = &domain.PresenceOpts{}
 	}
 
-	// Delete existing evaluations for the region
-	if err := s.evaluationRepo.DeleteByRegionID(ctx, regionID); err != nil {
+	if err := opts.Validate(); err != nil {
 		return err
 	}
 
-	s.logger.Debug("cleared stale evaluations", "regionId", regionID, "userId", userID)
+	if err := s.presenceRepo.CreateRange(ctx, userID, regionID, deviceID, start, end, opts); err != nil {
+		return fmt.Errorf("create presence range: %w", err)
+	}
+
+	return s.invalidate(ctx, userID, regionID)
+}
+
+func (s *PresenceService) CreateFromTimestamps(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, timeZone string, opts *domain.PresenceOpts) error {
+	if start.IsZero() {
+		return fmt.Errorf("%w: start time cannot be empty", domain.ErrValidation)
+	}
+
+	if end.IsZero() {
+		return fmt.Errorf("%w: end time cannot be empty", domain.ErrValidation)
+	}
+
+	var loc *time.Location
+
+	if timeZone != "" {
+		l, err := time.LoadLocation(timeZone)
+		if err != nil || l == time.Local {
+			return fmt.Errorf("%w: unknown time zone %s", domain.ErrValidation, timeZone)
+		}
+		loc = l
+	} else {
+		region, err := s.regionRepo.GetByID(ctx, regionID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: region %s not found", domain.ErrValidation, regionID)
+		}
+		if err != nil {
+			return fmt.Errorf("get region: %w", err)
+		}
+		loc = region.Location()
+	}
+
+	return s.Create(ctx, userID, regionID, deviceID, domain.LocalDate(start, loc), domain.LocalDate(end, loc), opts)
+}
+
+func (s *PresenceService) Import(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+	if userID < 0 {
+		return nil, fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: import has no rows", domain.ErrValidation)
+	}
 
-	body := map[string]any{
-		"userId":   userID,
-		"regionId": regionID,
+	if len(rows) > domain.MaxImportRows {
+		return nil, fmt.Errorf("%w: import cannot have more than %d rows", domain.ErrValidation, domain.MaxImportRows)
 	}
 
-	encoded, err := json.Marshal(body)
+	return s.importRows(ctx, userID, rows, dryRun)
+}
+
+func (s *PresenceService) ImportTrack(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error) {
+	if userID < 0 {
+		return nil, fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w: track has no points", domain.ErrValidation)
+	}
+
+	if len(points) > domain.MaxTrackPoints {
+		return nil, fmt.Errorf("%w: track cannot have more than %d points", domain.ErrValidation, domain.MaxTrackPoints)
+	}
+
+	regions, err := s.regionRepo.List(ctx, &domain.RegionFilter{})
 	if err != nil {
-		return fmt.Errorf("marshal presence: %w", err)
+		return nil, fmt.Errorf("list regions: %w", err)
 	}
 
-	msg := amqp091.Publishing{
-		ContentType: "application/json",
-		Body:        encoded,
+	rows, skipped := geo.NewIndex(regions).TrackRows(points)
+
+	result, err := s.importRows(ctx, userID, rows, dryRun)
+	if err != nil {
+		return nil, err
 	}
+	result.SkippedPoints = skipped
 
-	if err := s.ch.PublishWithContext(ctx, "presence.events", "presence.create", false, false, msg); err != nil {
-		return fmt.Errorf("publish presence: %w", err)
+	return result, nil
+}
+
+func (s *PresenceService) ImportCalendar(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+	if userID < 0 {
+		return nil, fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: calendar has no events", domain.ErrValidation)
+	}
+
+	if len(events) > domain.MaxCalendarEvents {
+		return nil, fmt.Errorf("%w: calendar cannot have more than %d events", domain.ErrValidation, domain.MaxCalendarEvents)
+	}
+
+	regions, err := s.regionRepo.List(ctx, &domain.RegionFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list regions: %w", err)
+	}
+
+	return s.importRows(ctx, userID, calendarRows(regions, events), dryRun)
+}
+
+// calendarRows resolves the region of each event from its region code, then its location, taken as
+// a region code or name such as "St Helier, Jersey", then its position. An event positioned in
+// several regions is imported into each as ambiguous. Events whose region cannot be resolved are
+// returned as invalid rows.
+func calendarRows(regions []*domain.Region, events []*domain.CalendarEvent) []*domain.ImportRow {
+	byID := make(map[domain.RegionID]*domain.Region, len(regions))
+	byName := make(map[string]*domain.Region, len(regions))
+
+	for _, region := range regions {
+		byID[region.ID] = region
+		if region.Type != domain.RegionTypeZone {
+			byName[strings.ToLower(region.Name)] = region
+		}
+	}
+
+	var idx *geo.Index
+
+	resolve := func(event *domain.CalendarEvent) []*domain.Region {
+		if event.RegionID != "" {
+			if region, ok := byID[event.RegionID]; ok {
+				return []*domain.Region{region}
+			}
+			// Unknown regions are reported when the rows are diffed
+			return []*domain.Region{{ID: event.RegionID}}
+		}
+
+		if location := strings.TrimSpace(event.Location); location != "" {
+			if region, ok := byID[domain.RegionID(strings.ToUpper(location))]; ok {
+				return []*domain.Region{region}
+			}
+
+			if region, ok := byName[strings.ToLower(location)]; ok {
+				return []*domain.Region{region}
+			}
+
+			if i := strings.LastIndex(location, ","); i >= 0 {
+				if region, ok := byName[strings.ToLower(strings.TrimSpace(location[i+1:]))]; ok {
+					return []*domain.Region{region}
+				}
+			}
+		}
+
+		if event.Geo == nil {
+			return nil
+		}
+
+		if idx == nil {
+			idx = geo.NewIndex(regions)
+		}
+
+		var located []*domain.Region
+		for _, regionID := range idx.Locate(*event.Geo) {
+			located = append(located, byID[regionID])
+		}
+
+		return located
+	}
+
+	rows := make([]*domain.ImportRow, 0, len(events))
+
+	for _, event := range events {
+		if event.Err != nil {
+			rows = append(rows, &domain.ImportRow{Line: event.Line, Provenance: domain.PresenceProvenanceCalendar, Err: event.Err})
+			continue
+		}
+
+		located := resolve(event)
+		if len(located) == 0 {
+			rows = append(rows, &domain.ImportRow{
+				Line:       event.Line,
+				Provenance: domain.PresenceProvenanceCalendar,
+				Err:        domain.ValidationError("cannot resolve the region of event %q", cmp.Or(event.Summary, event.Location, event.UID)),
+			})
+			continue
+		}
+
+		var evidence string
+		if event.UID != "" {
+			evidence = "ical:" + event.UID
+		}
+
+		for _, region := range located {
+			start, end := event.Days(region.Location())
+
+			rows = append(rows, &domain.ImportRow{
+				Line:       event.Line,
+				RegionID:   region.ID,
+				Start:      start,
+				End:        end,
+				Provenance: domain.PresenceProvenanceCalendar,
+				Evidence:   evidence,
+				Ambiguous:  len(located) > 1,
+			})
+		}
+	}
+
+	return rows
+}
+
+// importRows diffs the rows and, unless a dry run or any row is invalid, creates every row in a
+// single transaction before invalidating the evaluations of the imported regions.
+func (s *PresenceService) importRows(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+	result, err := s.diffImport(ctx, userID, rows)
+	if err != nil {
+		return nil, err
+	}
+	result.DryRun = dryRun
+
+	if dryRun || result.Invalid > 0 {
+		return result, nil
+	}
+
+	if err := s.createRows(ctx, userID, nil, rows); err != nil {
+		return nil, fmt.Errorf("import presences: %w", err)
+	}
+	result.Committed = true
+
+	return result, nil
+}
+
+func (s *PresenceService) CreateRows(ctx context.Context, userID int64, deviceID *int64, rows []*domain.ImportRow) error {
+	if userID < 0 {
+		return fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	for i, row := range rows {
+		if err := row.Validate(); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+
+	if err := s.createRows(ctx, userID, deviceID, rows); err != nil {
+		return fmt.Errorf("create presences: %w", err)
 	}
 
 	return nil
 }
 
+// createRows creates every row in a single transaction, then invalidates the evaluations of the
+// regions of every row once.
+func (s *PresenceService) createRows(ctx context.Context, userID int64, deviceID *int64, rows []*domain.ImportRow) error {
+	var regionIDs []domain.RegionID
+
+	err := repository.WithTx(ctx, s.conn, func(tx repository.Connection) error {
+		presenceRepo := repository.NewPostgresPresenceRepository(tx)
+
+		seen := make(map[domain.RegionID]struct{})
+		for _, row := range rows {
+			opts := &domain.PresenceOpts{
+				Arrival:    row.Arrival,
+				Departure:  row.Departure,
+				Transit:    row.Transit,
+				Provenance: row.Provenance,
+				Evidence:   row.Evidence,
+				Ambiguous:  row.Ambiguous,
+			}
+
+			if err := presenceRepo.CreateRange(ctx, userID, row.RegionID, deviceID, row.Start, row.End, opts); err != nil {
+				return fmt.Errorf("create presence range in %s from %s: %w", row.RegionID, row.Start.Format(time.DateOnly), err)
+			}
+
+			if _, ok := seen[row.RegionID]; !ok {
+				seen[row.RegionID] = struct{}{}
+				regionIDs = append(regionIDs, row.RegionID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.invalidate(ctx, userID, regionIDs...)
+}
+
+// diffImport validates each row and counts the days it adds, treating days already recorded in
+// the region, or imported by an earlier row, as overlapping.
+func (s *PresenceService) diffImport(ctx context.Context, userID int64, rows []*domain.ImportRow) (*domain.PresenceImport, error) {
+	type span struct{ start, end time.Time }
+
+	type regionDate struct {
+		regionID domain.RegionID
+		date     time.Time
+	}
+
+	result := &domain.PresenceImport{
+		Rows: make([]*domain.ImportRowResult, 0, len(rows)),
+	}
+
+	// The days imported into each region, so existing presences are only listed where they can overlap
+	spans := make(map[domain.RegionID]*span)
+
+	for _, row := range rows {
+		rowResult := &domain.ImportRowResult{ImportRow: *row}
+		result.Rows = append(result.Rows, rowResult)
+
+		if err := row.Validate(); err != nil {
+			rowResult.Status = domain.ImportStatusInvalid
+			rowResult.Error = err.Error()
+			continue
+		}
+
+		sp, ok := spans[row.RegionID]
+		if !ok {
+			spans[row.RegionID] = &span{row.Start, row.End}
+			continue
+		}
+
+		if row.Start.Before(sp.start) {
+			sp.start = row.Start
+		}
+		if row.End.After(sp.end) {
+			sp.end = row.End
+		}
+	}
+
+	recorded := make(map[regionDate]struct{})
+
+	for regionID, sp := range spans {
+		_, err := s.regionRepo.GetByID(ctx, regionID)
+		if errors.Is(err, domain.ErrNotFound) {
+			delete(spans, regionID)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get region %s: %w", regionID, err)
+		}
+
+		presences, err := s.presenceRepo.ListByRegionPeriod(ctx, userID, []domain.RegionID{regionID}, sp.start, sp.end)
+		if err != nil {
+			return nil, fmt.Errorf("list presences: %w", err)
+		}
+
+		for _, p := range presences {
+			recorded[regionDate{p.RegionID, p.Date}] = struct{}{}
+		}
+	}
+
+	for _, rowResult := range result.Rows {
+		if rowResult.Status == domain.ImportStatusInvalid {
+			result.Invalid++
+			continue
+		}
+
+		if _, ok := spans[rowResult.RegionID]; !ok {
+			rowResult.Status = domain.ImportStatusInvalid
+			rowResult.Error = domain.ValidationError("region %s not found", rowResult.RegionID).Error()
+			result.Invalid++
+			continue
+		}
+
+		for d := rowResult.Start; !d.After(rowResult.End); d = d.AddDate(0, 0, 1) {
+			key := regionDate{rowResult.RegionID, d}
+			if _, ok := recorded[key]; ok {
+				rowResult.OverlapDays++
+				continue
+			}
+			recorded[key] = struct{}{}
+			rowResult.NewDays++
+		}
+
+		rowResult.Status = domain.ImportStatusCreate
+		if rowResult.OverlapDays > 0 {
+			rowResult.Status = domain.ImportStatusOverlap
+		}
+
+		result.NewDays += rowResult.NewDays
+	}
+
+	return result, nil
+}
+
 func (s *PresenceService) Delete(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) error {
 	if userID < 0 {
 		return fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
@@ -146,31 +508,133 @@ func (s *PresenceService) Delete(ctx context.Context, userID int64, regionID dom
 		return fmt.Errorf("delete presence range: %w", err)
 	}
 
-	// Delete existing evaluations for the region
-	if err := s.evaluationRepo.DeleteByRegionID(ctx, regionID); err != nil {
-		return err
+	return s.invalidate(ctx, userID, regionID)
+}
+
+func (s *PresenceService) Confirm(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+	if userID < 0 {
+		return fmt.Errorf("%w: user ID cannot be negative", domain.ErrValidation)
+	}
+
+	if regionID == "" {
+		return fmt.Errorf("%w: region ID cannot be empty", domain.ErrValidation)
+	}
+
+	if start.IsZero() {
+		return fmt.Errorf("%w: start date cannot be empty", domain.ErrValidation)
 	}
 
-	s.logger.Debug("cleared stale evaluations", "regionId", regionID)
+	if end.IsZero() {
+		return fmt.Errorf("%w: end date cannot be empty", domain.ErrValidation)
+	}
 
-	body := map[string]any{
-		"userId":   userID,
-		"regionId": regionID,
+	if start.After(end) {
+		return fmt.Errorf("%w: start cannot be after end", domain.ErrValidation)
 	}
 
-	encoded, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("marshal presence: %w", err)
+	if err := s.presenceRepo.ConfirmRange(ctx, userID, regionID, start, end, confirmed); err != nil {
+		return fmt.Errorf("confirm presence range: %w", err)
 	}
 
-	msg := amqp091.Publishing{
-		ContentType: "application/json",
-		Body:        encoded,
+	// Cached evaluations count unconfirmed days too, so confirming days leaves them current
+	s.logger.Debug("confirmed presences", "userId", userID, "regionId", regionID, "start", start, "end", end, "confirmed", confirmed)
+
+	return nil
+}
+
+// invalidate clears stale evaluations for the regions and every region whose evaluation depends on
+// their presences, then publishes a presence event for each so they are re-evaluated. A region
+// affected by several of the regions is only cleared once.
+func (s *PresenceService) invalidate(ctx context.Context, userID int64, regionIDs ...domain.RegionID) error {
+	var affected []domain.RegionID
+
+	seen := make(map[domain.RegionID]struct{})
+	for _, regionID := range regionIDs {
+		ids, err := s.affectedRegionIDs(ctx, regionID)
+		if err != nil {
+			return fmt.Errorf("list affected regions: %w", err)
+		}
+
+		for _, id := range ids {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				affected = append(affected, id)
+			}
+		}
 	}
 
-	if err := s.ch.PublishWithContext(ctx, "presence.events", "presence.create", false, false, msg); err != nil {
-		return fmt.Errorf("publish presence: %w", err)
+	for _, id := range affected {
+		// Delete existing evaluations for the region
+		if err := s.evaluationRepo.DeleteByRegionID(ctx, id); err != nil {
+			return err
+		}
+
+		s.logger.Debug("cleared stale evaluations", "regionId", id, "userId", userID)
+
+		body := map[string]any{
+			"userId":   userID,
+			"regionId": id,
+		}
+
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal presence: %w", err)
+		}
+
+		msg := amqp091.Publishing{
+			ContentType: "application/json",
+			Body:        encoded,
+		}
+
+		if err := s.ch.PublishWithContext(ctx, "presence.events", "presence.create", false, false, msg); err != nil {
+			return fmt.Errorf("publish presence: %w", err)
+		}
 	}
 
 	return nil
 }
+
+// affectedRegionIDs returns the regions whose evaluations depend on presences in the region.
+func (s *PresenceService) affectedRegionIDs(ctx context.Context, regionID domain.RegionID) ([]domain.RegionID, error) {
+	return affectedRegionIDs(ctx, s.regionRepo, s.ruleRepo, regionID)
+}
+
+// affectedRegionIDs returns the region followed by every zone it is a member of, every ancestor
+// region that may roll its presences up and every region with rules that reference theirs.
+func affectedRegionIDs(ctx context.Context, regionRepo domain.RegionRepository, ruleRepo domain.RuleRepository, regionID domain.RegionID) ([]domain.RegionID, error) {
+	zones, err := regionRepo.List(ctx, &domain.RegionFilter{MemberRegionIDs: []domain.RegionID{regionID}})
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := regionRepo.ListAncestors(ctx, regionID)
+	if err != nil {
+		return nil, err
+	}
+
+	regionIDs := []domain.RegionID{regionID}
+	for _, r := range append(zones, ancestors...) {
+		regionIDs = append(regionIDs, r.ID)
+	}
+
+	seen := make(map[domain.RegionID]struct{}, len(regionIDs))
+	for _, id := range regionIDs {
+		seen[id] = struct{}{}
+	}
+
+	for _, id := range regionIDs {
+		dependents, err := ruleRepo.ListDependentRegionIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dep := range dependents {
+			if _, ok := seen[dep]; !ok {
+				seen[dep] = struct{}{}
+				regionIDs = append(regionIDs, dep)
+			}
+		}
+	}
+
+	return regionIDs, nil
+}
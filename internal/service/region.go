@@ -50,10 +50,26 @@ func (s *RegionService) CreateOrUpdate(ctx context.Context, region *domain.Regio
 		region.YearStartMonth = 1
 	}
 
+	if region.TimeZone == "" {
+		region.TimeZone = "UTC"
+	}
+
 	if region.Sources == nil {
 		region.Sources = make([]domain.Source, 0)
 	}
 
+	if region.MemberRegionIDs == nil {
+		region.MemberRegionIDs = make([]domain.RegionID, 0)
+	}
+
+	if region.FiscalCalendar == nil {
+		region.FiscalCalendar = make([]domain.FiscalYearStart, 0)
+	}
+
+	if region.Boundary == nil {
+		region.Boundary = make([]domain.Polygon, 0)
+	}
+
 	if err := region.Validate(); err != nil {
 		return err
 	}
@@ -2,14 +2,18 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 
 	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/engine"
 	"github.com/pumpkinlog/backend/internal/repository"
 )
 
 type RuleService struct {
 	logger *slog.Logger
+	engine *engine.Engine
 
 	ruleRepo       domain.RuleRepository
 	evaluationRepo domain.EvaluationRepository
@@ -18,6 +22,7 @@ type RuleService struct {
 func NewRuleService(logger *slog.Logger, conn repository.Connection) domain.RuleService {
 	return &RuleService{
 		logger: logger,
+		engine: engine.NewEngine(),
 
 		ruleRepo:       repository.NewPostgresRuleRepository(conn),
 		evaluationRepo: repository.NewPostgresEvaluationRepository(conn),
@@ -45,16 +50,75 @@ func (s *RuleService) CreateOrUpdate(ctx context.Context, rule *domain.Rule) err
 		return err
 	}
 
-	if err := s.ruleRepo.CreateOrUpdate(ctx, rule); err != nil {
+	if err := s.engine.ValidateRule(rule); err != nil {
+		return err
+	}
+
+	if err := s.validateReferences(ctx, rule); err != nil {
 		return err
 	}
 
-	// Delete existing evaluations for the region
-	if err := s.evaluationRepo.DeleteByRegionID(ctx, rule.RegionID); err != nil {
+	if err := s.ruleRepo.CreateOrUpdate(ctx, rule); err != nil {
 		return err
 	}
 
+	dependents, err := s.ruleRepo.ListDependentRegionIDs(ctx, rule.RegionID)
+	if err != nil {
+		return fmt.Errorf("list dependent regions: %w", err)
+	}
+
+	// Delete existing evaluations for the region and every region referencing its rules
+	for _, id := range append([]domain.RegionID{rule.RegionID}, dependents...) {
+		if err := s.evaluationRepo.DeleteByRegionID(ctx, id); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Debug("cleared stale evaluations", "regionId", rule.RegionID, "ruleId", rule.ID)
 
 	return nil
 }
+
+// validateReferences checks that the rules referenced by the rule exist and that saving it would not
+// create a reference cycle.
+func (s *RuleService) validateReferences(ctx context.Context, rule *domain.Rule) error {
+	rules := map[domain.Code]*domain.Rule{rule.ID: rule}
+
+	pending, err := rule.Node.References()
+	if err != nil {
+		return domain.ValidationError("invalid rule node: %s", err)
+	}
+
+	for len(pending) > 0 {
+		ruleID := pending[0]
+		pending = pending[1:]
+
+		if _, ok := rules[ruleID]; ok {
+			continue
+		}
+
+		ref, err := s.ruleRepo.GetByID(ctx, ruleID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ValidationError("referenced rule %s not found", ruleID)
+		}
+		if err != nil {
+			return fmt.Errorf("get referenced rule %s: %w", ruleID, err)
+		}
+
+		rules[ref.ID] = ref
+
+		references, err := ref.Node.References()
+		if err != nil {
+			return fmt.Errorf("rule %s references: %w", ref.ID, err)
+		}
+		pending = append(pending, references...)
+	}
+
+	list := make([]*domain.Rule, 0, len(rules))
+	for _, r := range rules {
+		list = append(list, r)
+	}
+
+	_, err = domain.SortRules(list)
+	return err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/engine"
+	"github.com/pumpkinlog/backend/internal/test/mocks"
+)
+
+func newTestRuleService() *RuleService {
+	return &RuleService{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		engine: engine.NewEngine(),
+
+		ruleRepo: mocks.RuleRepo{
+			CreateOrUpdateFunc: func(ctx context.Context, rule *domain.Rule) error {
+				return nil
+			},
+			ListDependentRegionIDsFunc: func(ctx context.Context, regionID domain.RegionID) ([]domain.RegionID, error) {
+				return nil, nil
+			},
+		},
+		evaluationRepo: mocks.EvaluationRepo{
+			DeleteByRegionIDFunc: func(ctx context.Context, regionID domain.RegionID) error {
+				return nil
+			},
+		},
+	}
+}
+
+func TestRuleServiceCreateOrUpdate(t *testing.T) {
+	strategy := `{"type":"strategy","props":{"type":"aggregate","period":{"type":"year","years":1},"props":{"threshold":183}}}`
+	sliding := `{"type":"strategy","props":{"type":"sliding","period":{"type":"rolling","rollingDays":180},"props":{"threshold":90,"windowDays":0}}}`
+	condition := `{"type":"condition","props":{"conditionId":"JE_ABODE","equals":true,"comparator":"eq"}}`
+
+	tests := []struct {
+		name    string
+		node    string
+		wantErr string
+	}{
+		{
+			name: "valid rule",
+			node: `{"type":"atLeast","props":{"k":2,"nodes":[` + strategy + `,` + condition + `]}}`,
+		},
+		{
+			name:    "atLeast needs more passing children than it has",
+			node:    `{"type":"atLeast","props":{"k":5,"nodes":[` + strategy + `,` + condition + `]}}`,
+			wantErr: "threshold node k must be between 1 and 2",
+		},
+		{
+			name:    "not without a child",
+			node:    `{"type":"not","props":{}}`,
+			wantErr: "type is required",
+		},
+		{
+			name:    "unknown comparator",
+			node:    `{"type":"condition","props":{"conditionId":"JE_ABODE","equals":true,"comparator":"bogus"}}`,
+			wantErr: "unsupported comparator: bogus",
+		},
+		{
+			name:    "unknown day count mode",
+			node:    `{"type":"strategy","props":{"type":"aggregate","period":{"type":"year","years":1},"dayCount":"nope","props":{"threshold":183}}}`,
+			wantErr: "unknown day count mode: nope",
+		},
+		{
+			name:    "split year without a split",
+			node:    `{"type":"strategy","props":{"type":"aggregate","period":{"type":"split_year"},"props":{"threshold":183}}}`,
+			wantErr: "split-year period must split the start or end of the year",
+		},
+		{
+			name:    "sliding window without days",
+			node:    sliding,
+			wantErr: "invalid strategy sliding: invalid sliding strategy config: window days must be greater than 0",
+		},
+		{
+			name:    "nested invalid node",
+			node:    `{"type":"and","props":[` + strategy + `,{"type":"condition","props":{"conditionId":"JE_ABODE","comparator":"bogus"}}]}`,
+			wantErr: "unsupported comparator: bogus",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var node domain.RuleNode
+			require.NoError(t, json.Unmarshal([]byte(tc.node), &node))
+
+			rule := &domain.Rule{
+				ID:          "JE_TEST",
+				RegionID:    "JE",
+				Name:        "Test",
+				Description: "A test rule",
+				Node:        node,
+			}
+
+			err := newTestRuleService().CreateOrUpdate(context.Background(), rule)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, domain.ErrValidation)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
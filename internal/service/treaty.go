@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/engine"
+	"github.com/pumpkinlog/backend/internal/repository"
+)
+
+type TreatyService struct {
+	logger *slog.Logger
+	engine *engine.Engine
+
+	evaluationSvc domain.EvaluationService
+
+	treatyRepo    domain.TreatyRepository
+	regionRepo    domain.RegionRepository
+	conditionRepo domain.ConditionRepository
+	answerRepo    domain.AnswerRepository
+	presenceRepo  domain.PresenceRepository
+}
+
+// NewTreatyService builds on the evaluation service, which evaluates each treaty region before the
+// treaty tie-breakers are applied.
+func NewTreatyService(logger *slog.Logger, conn repository.Connection, evaluationSvc domain.EvaluationService) domain.TreatyService {
+	return &TreatyService{
+		logger: logger,
+		engine: engine.NewEngine(),
+
+		evaluationSvc: evaluationSvc,
+
+		treatyRepo:    repository.NewPostgresTreatyRepository(conn),
+		regionRepo:    repository.NewPostgresRegionRepository(conn),
+		conditionRepo: repository.NewPostgresConditionRepository(conn),
+		answerRepo:    repository.NewPostgresAnswerRepository(conn),
+		presenceRepo:  repository.NewPostgresPresenceRepository(conn),
+	}
+}
+
+func (s *TreatyService) GetByID(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+	if err := treatyID.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.treatyRepo.GetByID(ctx, treatyID)
+}
+
+func (s *TreatyService) List(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error) {
+	if filter == nil {
+		filter = &domain.TreatyFilter{}
+	}
+
+	return s.treatyRepo.List(ctx, filter)
+}
+
+func (s *TreatyService) CreateOrUpdate(ctx context.Context, treaty *domain.Treaty) error {
+	if err := treaty.Validate(); err != nil {
+		return err
+	}
+
+	// Condition tie-breakers ask a yes or no question of each region
+	for _, test := range treaty.Tests {
+		for regionID, conditionID := range test.Conditions {
+			condition, err := s.conditionRepo.GetByID(ctx, conditionID)
+			if errors.Is(err, domain.ErrNotFound) {
+				return domain.ValidationError("tie-breaker %s condition %s not found", test.Name, conditionID)
+			}
+			if err != nil {
+				return fmt.Errorf("get condition %s: %w", conditionID, err)
+			}
+
+			if condition.RegionID != regionID || condition.Type != domain.ConditionTypeBoolean {
+				return domain.ValidationError("tie-breaker %s condition %s must be a boolean condition of region %s", test.Name, conditionID, regionID)
+			}
+		}
+	}
+
+	return s.treatyRepo.CreateOrUpdate(ctx, treaty)
+}
+
+// Evaluate evaluates both treaty regions for the user and resolves dual residency to a single
+// treaty residence. Region evaluations follow the same options as a single region evaluation.
+func (s *TreatyService) Evaluate(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error) {
+	if err := treatyID.Validate(); err != nil {
+		return nil, err
+	}
+
+	treaty, err := s.treatyRepo.GetByID(ctx, treatyID)
+	if err != nil {
+		return nil, fmt.Errorf("get treaty: %w", err)
+	}
+
+	treatyCtx := &domain.TreatyContext{
+		At:          opts.PointInTime,
+		Treaty:      treaty,
+		Regions:     make(map[domain.RegionID]*domain.Region),
+		Evaluations: make(map[domain.RegionID]*domain.RegionEvaluation),
+		Answers:     make(map[domain.Code]*domain.Answer),
+	}
+
+	var regionIDs []domain.RegionID
+
+	for _, regionID := range treaty.RegionIDs {
+		region, err := s.regionRepo.GetByID(ctx, regionID)
+		if err != nil {
+			return nil, fmt.Errorf("get region %s: %w", regionID, err)
+		}
+		treatyCtx.Regions[regionID] = region
+		regionIDs = append(regionIDs, region.RegionIDs()...)
+	}
+
+	// Both regions and the presence tie-breakers are evaluated at the same point in time, today in
+	// the first treaty region unless given
+	if treatyCtx.At.IsZero() {
+		treatyCtx.At = treatyCtx.Regions[treaty.RegionIDs[0]].LocalTime(time.Now())
+	}
+
+	// Cached evaluations are made as of each region's own today, so they are always recomputed
+	evaluateOpts := *opts
+	evaluateOpts.PointInTime = treatyCtx.At
+	evaluateOpts.Recompute = true
+
+	for _, regionID := range treaty.RegionIDs {
+		opts := evaluateOpts
+
+		evaluation, err := s.evaluationSvc.EvaluateRegion(ctx, userID, regionID, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate region %s: %w", regionID, err)
+		}
+		treatyCtx.Evaluations[regionID] = evaluation
+	}
+
+	for _, conditionID := range treaty.ConditionIDs() {
+		answer, err := s.answerRepo.GetByID(ctx, userID, conditionID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get answer %s: %w", conditionID, err)
+		}
+		treatyCtx.Answers[conditionID] = answer
+	}
+
+	if days := treaty.MaxDays(); days > 0 {
+		start := treatyCtx.At.AddDate(0, 0, -days)
+
+		treatyCtx.Presences, err = s.presenceRepo.ListByRegionPeriod(ctx, userID, regionIDs, start, treatyCtx.At)
+		if err != nil {
+			return nil, fmt.Errorf("list presences: %w", err)
+		}
+	}
+
+	evaluation, err := s.engine.EvaluateTreaty(treatyCtx)
+	if err != nil {
+		return nil, fmt.Errorf("treaty service: evaluate treaty: %w", err)
+	}
+
+	return evaluation, nil
+}
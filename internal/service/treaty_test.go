@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/engine"
+	"github.com/pumpkinlog/backend/internal/test/mocks"
+)
+
+func TestTreatyServiceEvaluate(t *testing.T) {
+	treaty := &domain.Treaty{
+		ID:        "NZ_US",
+		RegionIDs: [2]domain.RegionID{"NZ", "US_HI"},
+		Tests: []domain.TieBreaker{
+			{
+				Name:       "Permanent home",
+				Type:       domain.TieBreakerTypeCondition,
+				Conditions: map[domain.RegionID]domain.Code{"NZ": "NZ_HOME", "US_HI": "US_HI_HOME"},
+			},
+		},
+	}
+
+	// Twenty-two hours apart, so the regions are almost always on different calendar days
+	regions := map[domain.RegionID]*domain.Region{
+		"NZ":    {ID: "NZ", TimeZone: "Pacific/Auckland"},
+		"US_HI": {ID: "US_HI", TimeZone: "Pacific/Honolulu"},
+	}
+
+	tests := []struct {
+		name        string
+		pointInTime time.Time
+		answers     map[domain.Code]*domain.Answer
+		evaluateErr error
+		wantStatus  domain.TreatyStatus
+		wantErr     string
+	}{
+		{
+			name:       "both regions evaluated at today in the first region",
+			answers:    map[domain.Code]*domain.Answer{"NZ_HOME": {Value: true}, "US_HI_HOME": {Value: false}},
+			wantStatus: domain.TreatyStatusResolved,
+		},
+		{
+			name:        "both regions evaluated at the point in time",
+			pointInTime: time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC),
+			answers:     map[domain.Code]*domain.Answer{"NZ_HOME": {Value: true}, "US_HI_HOME": {Value: false}},
+			wantStatus:  domain.TreatyStatusResolved,
+		},
+		{
+			name:       "tie-breaker answered with the wrong type is an error",
+			answers:    map[domain.Code]*domain.Answer{"NZ_HOME": {Value: "yes"}, "US_HI_HOME": {Value: false}},
+			wantStatus: domain.TreatyStatusError,
+		},
+		{
+			name:        "region evaluation failure is an error",
+			answers:     map[domain.Code]*domain.Answer{},
+			evaluateErr: errors.New("connection refused"),
+			wantErr:     "evaluate region NZ: connection refused",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var evaluated []*domain.EvaluateOpts
+
+			s := &TreatyService{
+				logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+				engine: engine.NewEngine(),
+
+				evaluationSvc: mocks.EvaluationService{
+					EvaluateRegionFunc: func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error) {
+						if tc.evaluateErr != nil {
+							return nil, tc.evaluateErr
+						}
+
+						evaluated = append(evaluated, opts)
+						return &domain.RegionEvaluation{RegionID: regionID, Passed: true, Status: domain.EvaluationStatusEvaluated}, nil
+					},
+				},
+
+				treatyRepo: mocks.TreatyRepo{
+					GetByIDFunc: func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+						return treaty, nil
+					},
+				},
+				regionRepo: mocks.RegionRepo{
+					GetByIDFunc: func(ctx context.Context, regionID domain.RegionID) (*domain.Region, error) {
+						return regions[regionID], nil
+					},
+				},
+				answerRepo: mocks.AnswerRepository{
+					GetByIDFunc: func(ctx context.Context, userID int64, conditionID domain.Code) (*domain.Answer, error) {
+						if answer, ok := tc.answers[conditionID]; ok {
+							return answer, nil
+						}
+						return nil, domain.ErrNotFound
+					},
+				},
+			}
+
+			before := regions["NZ"].LocalTime(time.Now())
+
+			evaluation, err := s.Evaluate(context.Background(), 1, treaty.ID, &domain.EvaluateOpts{PointInTime: tc.pointInTime})
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, evaluation.Status)
+
+			require.Len(t, evaluated, 2)
+			for _, opts := range evaluated {
+				require.True(t, opts.Recompute)
+				require.Equal(t, evaluation.PointInTime, opts.PointInTime)
+			}
+
+			if tc.pointInTime.IsZero() {
+				require.WithinDuration(t, before, evaluation.PointInTime, time.Minute)
+			} else {
+				require.Equal(t, tc.pointInTime, evaluation.PointInTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type CalendarService struct {
+	CreateFeedTokenFunc func(ctx context.Context, userID int64) (string, error)
+	FeedFunc            func(ctx context.Context, token string) (*domain.CalendarFeed, error)
+}
+
+func (m CalendarService) CreateFeedToken(ctx context.Context, userID int64) (string, error) {
+	return m.CreateFeedTokenFunc(ctx, userID)
+}
+
+func (m CalendarService) Feed(ctx context.Context, token string) (*domain.CalendarFeed, error) {
+	return m.FeedFunc(ctx, token)
+}
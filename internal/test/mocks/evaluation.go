@@ -10,6 +10,10 @@ import (
 type EvaluationService struct {
 	EvaluationContextFunc func(ctx context.Context, userID int64, regionID domain.RegionID, pointInTime time.Time) (*domain.EvaluationContext, error)
 	EvaluateRegionFunc    func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error)
+	EvaluateRegionsFunc   func(ctx context.Context, userID int64) ([]*domain.RegionEvaluation, error)
+	ForecastFunc          func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error)
+	WhatIfFunc            func(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error)
+	TimelineFunc          func(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error)
 }
 
 func (m EvaluationService) EvaluationContext(ctx context.Context, userID int64, regionID domain.RegionID, pointInTime time.Time) (*domain.EvaluationContext, error) {
@@ -19,3 +23,47 @@ func (m EvaluationService) EvaluationContext(ctx context.Context, userID int64,
 func (m EvaluationService) EvaluateRegion(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.EvaluateOpts) (*domain.RegionEvaluation, error) {
 	return m.EvaluateRegionFunc(ctx, userID, regionID, opts)
 }
+
+func (m EvaluationService) EvaluateRegions(ctx context.Context, userID int64) ([]*domain.RegionEvaluation, error) {
+	return m.EvaluateRegionsFunc(ctx, userID)
+}
+
+func (m EvaluationService) Forecast(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.ForecastOpts) (*domain.Forecast, error) {
+	return m.ForecastFunc(ctx, userID, regionID, opts)
+}
+
+func (m EvaluationService) WhatIf(ctx context.Context, userID int64, opts *domain.WhatIfOpts) (*domain.WhatIf, error) {
+	return m.WhatIfFunc(ctx, userID, opts)
+}
+
+func (m EvaluationService) Timeline(ctx context.Context, userID int64, regionID domain.RegionID, opts *domain.TimelineOpts) (*domain.Timeline, error) {
+	return m.TimelineFunc(ctx, userID, regionID, opts)
+}
+
+type EvaluationRepo struct {
+	GetByIDFunc                 func(ctx context.Context, userID int64, regionID domain.RegionID) (*domain.RegionEvaluation, error)
+	ListFunc                    func(ctx context.Context, userID int64) ([]*domain.RegionEvaluation, error)
+	CreateOrUpdateFunc          func(ctx context.Context, evaluation *domain.RegionEvaluation) error
+	DeleteByUserAndRegionIDFunc func(ctx context.Context, userID int64, regionID domain.RegionID) error
+	DeleteByRegionIDFunc        func(ctx context.Context, regionID domain.RegionID) error
+}
+
+func (m EvaluationRepo) GetByID(ctx context.Context, userID int64, regionID domain.RegionID) (*domain.RegionEvaluation, error) {
+	return m.GetByIDFunc(ctx, userID, regionID)
+}
+
+func (m EvaluationRepo) List(ctx context.Context, userID int64) ([]*domain.RegionEvaluation, error) {
+	return m.ListFunc(ctx, userID)
+}
+
+func (m EvaluationRepo) CreateOrUpdate(ctx context.Context, evaluation *domain.RegionEvaluation) error {
+	return m.CreateOrUpdateFunc(ctx, evaluation)
+}
+
+func (m EvaluationRepo) DeleteByUserAndRegionID(ctx context.Context, userID int64, regionID domain.RegionID) error {
+	return m.DeleteByUserAndRegionIDFunc(ctx, userID, regionID)
+}
+
+func (m EvaluationRepo) DeleteByRegionID(ctx context.Context, regionID domain.RegionID) error {
+	return m.DeleteByRegionIDFunc(ctx, regionID)
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type PingService struct {
+	RecordFunc func(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error)
+	FoldFunc   func(ctx context.Context, userID, deviceID int64, start, end time.Time) error
+}
+
+func (m PingService) Record(ctx context.Context, userID, deviceID int64, pings []*domain.DevicePing) (*domain.PingReceipt, error) {
+	return m.RecordFunc(ctx, userID, deviceID, pings)
+}
+
+func (m PingService) Fold(ctx context.Context, userID, deviceID int64, start, end time.Time) error {
+	return m.FoldFunc(ctx, userID, deviceID, start, end)
+}
+
+type PingRepo struct {
+	CreateBatchFunc  func(ctx context.Context, pings []*domain.DevicePing) (int, error)
+	ListByPeriodFunc func(ctx context.Context, userID, deviceID int64, start, end time.Time) ([]*domain.DevicePing, error)
+}
+
+func (m PingRepo) CreateBatch(ctx context.Context, pings []*domain.DevicePing) (int, error) {
+	return m.CreateBatchFunc(ctx, pings)
+}
+
+func (m PingRepo) ListByPeriod(ctx context.Context, userID, deviceID int64, start, end time.Time) ([]*domain.DevicePing, error) {
+	return m.ListByPeriodFunc(ctx, userID, deviceID, start, end)
+}
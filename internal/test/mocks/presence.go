@@ -10,9 +10,11 @@ import (
 type PresenceRepo struct {
 	GetByIDFunc            func(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) (*domain.Presence, error)
 	ListFunc               func(ctx context.Context, userID int64, filter *domain.PresenceFilter) ([]*domain.Presence, error)
-	ListByRegionPeriodFunc func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) ([]*domain.Presence, error)
+	ListByRegionPeriodFunc func(ctx context.Context, userID int64, regionIDs []domain.RegionID, start, end time.Time) ([]*domain.Presence, error)
+	ListRegionIDsFunc      func(ctx context.Context, userID int64) ([]domain.RegionID, error)
 	CreateFunc             func(ctx context.Context, location *domain.Presence) error
-	CreateRangeFunc        func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error
+	CreateRangeFunc        func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error
+	ConfirmRangeFunc       func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error
 	DeleteFunc             func(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) error
 	DeleteRangeFunc        func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) error
 }
@@ -25,16 +27,24 @@ func (m PresenceRepo) List(ctx context.Context, userID int64, filter *domain.Pre
 	return m.ListFunc(ctx, userID, filter)
 }
 
-func (m PresenceRepo) ListByRegionPeriod(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) ([]*domain.Presence, error) {
-	return m.ListByRegionPeriodFunc(ctx, userID, regionID, start, end)
+func (m PresenceRepo) ListByRegionPeriod(ctx context.Context, userID int64, regionIDs []domain.RegionID, start, end time.Time) ([]*domain.Presence, error) {
+	return m.ListByRegionPeriodFunc(ctx, userID, regionIDs, start, end)
+}
+
+func (m PresenceRepo) ListRegionIDs(ctx context.Context, userID int64) ([]domain.RegionID, error) {
+	return m.ListRegionIDsFunc(ctx, userID)
 }
 
 func (m PresenceRepo) Create(ctx context.Context, location *domain.Presence) error {
 	return m.CreateFunc(ctx, location)
 }
 
-func (m PresenceRepo) CreateRange(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
-	return m.CreateRangeFunc(ctx, userID, regionID, deviceID, start, end)
+func (m PresenceRepo) CreateRange(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
+	return m.CreateRangeFunc(ctx, userID, regionID, deviceID, start, end, opts)
+}
+
+func (m PresenceRepo) ConfirmRange(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+	return m.ConfirmRangeFunc(ctx, userID, regionID, start, end, confirmed)
 }
 
 func (m PresenceRepo) Delete(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) error {
@@ -46,10 +56,16 @@ func (m PresenceRepo) DeleteRange(ctx context.Context, userID int64, regionID do
 }
 
 type PresenceService struct {
-	GetByIDFunc func(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) (*domain.Presence, error)
-	ListFunc    func(ctx context.Context, userID int64, filter *domain.PresenceFilter) ([]*domain.Presence, error)
-	CreateFunc  func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error
-	DeleteFunc  func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) error
+	GetByIDFunc              func(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) (*domain.Presence, error)
+	ListFunc                 func(ctx context.Context, userID int64, filter *domain.PresenceFilter) ([]*domain.Presence, error)
+	CreateFunc               func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error
+	CreateFromTimestampsFunc func(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, timeZone string, opts *domain.PresenceOpts) error
+	CreateRowsFunc           func(ctx context.Context, userID int64, deviceID *int64, rows []*domain.ImportRow) error
+	ImportFunc               func(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error)
+	ImportTrackFunc          func(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error)
+	ImportCalendarFunc       func(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error)
+	ConfirmFunc              func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error
+	DeleteFunc               func(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) error
 }
 
 func (m PresenceService) GetByID(ctx context.Context, userID int64, regionID domain.RegionID, date time.Time) (*domain.Presence, error) {
@@ -60,8 +76,32 @@ func (m PresenceService) List(ctx context.Context, userID int64, filter *domain.
 	return m.ListFunc(ctx, userID, filter)
 }
 
-func (m PresenceService) Create(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time) error {
-	return m.CreateFunc(ctx, userID, regionID, deviceID, start, end)
+func (m PresenceService) Create(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, opts *domain.PresenceOpts) error {
+	return m.CreateFunc(ctx, userID, regionID, deviceID, start, end, opts)
+}
+
+func (m PresenceService) CreateRows(ctx context.Context, userID int64, deviceID *int64, rows []*domain.ImportRow) error {
+	return m.CreateRowsFunc(ctx, userID, deviceID, rows)
+}
+
+func (m PresenceService) CreateFromTimestamps(ctx context.Context, userID int64, regionID domain.RegionID, deviceID *int64, start, end time.Time, timeZone string, opts *domain.PresenceOpts) error {
+	return m.CreateFromTimestampsFunc(ctx, userID, regionID, deviceID, start, end, timeZone, opts)
+}
+
+func (m PresenceService) Import(ctx context.Context, userID int64, rows []*domain.ImportRow, dryRun bool) (*domain.PresenceImport, error) {
+	return m.ImportFunc(ctx, userID, rows, dryRun)
+}
+
+func (m PresenceService) ImportTrack(ctx context.Context, userID int64, points []domain.TrackPoint, dryRun bool) (*domain.PresenceImport, error) {
+	return m.ImportTrackFunc(ctx, userID, points, dryRun)
+}
+
+func (m PresenceService) ImportCalendar(ctx context.Context, userID int64, events []*domain.CalendarEvent, dryRun bool) (*domain.PresenceImport, error) {
+	return m.ImportCalendarFunc(ctx, userID, events, dryRun)
+}
+
+func (m PresenceService) Confirm(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time, confirmed bool) error {
+	return m.ConfirmFunc(ctx, userID, regionID, start, end, confirmed)
 }
 
 func (m PresenceService) Delete(ctx context.Context, userID int64, regionID domain.RegionID, start, end time.Time) error {
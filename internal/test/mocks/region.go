@@ -7,9 +7,11 @@ import (
 )
 
 type RegionRepo struct {
-	GetByIDFunc        func(ctx context.Context, regionID domain.RegionID) (*domain.Region, error)
-	ListFunc           func(ctx context.Context, filter *domain.RegionFilter) ([]*domain.Region, error)
-	CreateOrUpdateFunc func(ctx context.Context, region *domain.Region) error
+	GetByIDFunc         func(ctx context.Context, regionID domain.RegionID) (*domain.Region, error)
+	ListFunc            func(ctx context.Context, filter *domain.RegionFilter) ([]*domain.Region, error)
+	ListDescendantsFunc func(ctx context.Context, regionID domain.RegionID) ([]*domain.Region, error)
+	ListAncestorsFunc   func(ctx context.Context, regionID domain.RegionID) ([]*domain.Region, error)
+	CreateOrUpdateFunc  func(ctx context.Context, region *domain.Region) error
 }
 
 func (m RegionRepo) GetByID(ctx context.Context, id domain.RegionID) (*domain.Region, error) {
@@ -20,6 +22,14 @@ func (m RegionRepo) List(ctx context.Context, filter *domain.RegionFilter) ([]*d
 	return m.ListFunc(ctx, filter)
 }
 
+func (m RegionRepo) ListDescendants(ctx context.Context, regionID domain.RegionID) ([]*domain.Region, error) {
+	return m.ListDescendantsFunc(ctx, regionID)
+}
+
+func (m RegionRepo) ListAncestors(ctx context.Context, regionID domain.RegionID) ([]*domain.Region, error) {
+	return m.ListAncestorsFunc(ctx, regionID)
+}
+
 func (m RegionRepo) CreateOrUpdate(ctx context.Context, region *domain.Region) error {
 	return m.CreateOrUpdateFunc(ctx, region)
 }
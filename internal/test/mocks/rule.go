@@ -7,10 +7,11 @@ import (
 )
 
 type RuleRepo struct {
-	GetByIDFunc        func(ctx context.Context, ruleID domain.Code) (*domain.Rule, error)
-	ListFunc           func(ctx context.Context, filter *domain.RuleFilter) ([]*domain.Rule, error)
-	ListByRegionIDFunc func(ctx context.Context, regionID string) ([]*domain.Rule, error)
-	CreateOrUpdateFunc func(ctx context.Context, rule *domain.Rule) error
+	GetByIDFunc                func(ctx context.Context, ruleID domain.Code) (*domain.Rule, error)
+	ListFunc                   func(ctx context.Context, filter *domain.RuleFilter) ([]*domain.Rule, error)
+	ListByRegionIDFunc         func(ctx context.Context, regionID domain.RegionID) ([]*domain.Rule, error)
+	ListDependentRegionIDsFunc func(ctx context.Context, regionID domain.RegionID) ([]domain.RegionID, error)
+	CreateOrUpdateFunc         func(ctx context.Context, rule *domain.Rule) error
 }
 
 func (m RuleRepo) GetByID(ctx context.Context, ruleID domain.Code) (*domain.Rule, error) {
@@ -21,10 +22,14 @@ func (m RuleRepo) List(ctx context.Context, filter *domain.RuleFilter) ([]*domai
 	return m.ListFunc(ctx, filter)
 }
 
-func (m RuleRepo) ListByRegionID(ctx context.Context, regionID string) ([]*domain.Rule, error) {
+func (m RuleRepo) ListByRegionID(ctx context.Context, regionID domain.RegionID) ([]*domain.Rule, error) {
 	return m.ListByRegionIDFunc(ctx, regionID)
 }
 
+func (m RuleRepo) ListDependentRegionIDs(ctx context.Context, regionID domain.RegionID) ([]domain.RegionID, error) {
+	return m.ListDependentRegionIDsFunc(ctx, regionID)
+}
+
 func (m RuleRepo) CreateOrUpdate(ctx context.Context, rule *domain.Rule) error {
 	return m.CreateOrUpdateFunc(ctx, rule)
 }
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+)
+
+type TreatyRepo struct {
+	GetByIDFunc        func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error)
+	ListFunc           func(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error)
+	CreateOrUpdateFunc func(ctx context.Context, treaty *domain.Treaty) error
+}
+
+func (m TreatyRepo) GetByID(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+	return m.GetByIDFunc(ctx, treatyID)
+}
+
+func (m TreatyRepo) List(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error) {
+	return m.ListFunc(ctx, filter)
+}
+
+func (m TreatyRepo) CreateOrUpdate(ctx context.Context, treaty *domain.Treaty) error {
+	return m.CreateOrUpdateFunc(ctx, treaty)
+}
+
+type TreatyService struct {
+	GetByIDFunc        func(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error)
+	ListFunc           func(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error)
+	CreateOrUpdateFunc func(ctx context.Context, treaty *domain.Treaty) error
+	EvaluateFunc       func(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error)
+}
+
+func (m TreatyService) GetByID(ctx context.Context, treatyID domain.Code) (*domain.Treaty, error) {
+	return m.GetByIDFunc(ctx, treatyID)
+}
+
+func (m TreatyService) List(ctx context.Context, filter *domain.TreatyFilter) ([]*domain.Treaty, error) {
+	return m.ListFunc(ctx, filter)
+}
+
+func (m TreatyService) CreateOrUpdate(ctx context.Context, treaty *domain.Treaty) error {
+	return m.CreateOrUpdateFunc(ctx, treaty)
+}
+
+func (m TreatyService) Evaluate(ctx context.Context, userID int64, treatyID domain.Code, opts *domain.EvaluateOpts) (*domain.TreatyEvaluation, error) {
+	return m.EvaluateFunc(ctx, userID, treatyID, opts)
+}
@@ -11,6 +11,9 @@ type UserRepository struct {
 	CreateFunc  func(ctx context.Context, user *domain.User) error
 	UpdateFunc  func(ctx context.Context, user *domain.User) error
 	DeleteFunc  func(ctx context.Context, userID int64) error
+
+	SetCalendarTokenHashFunc   func(ctx context.Context, userID int64, hash string) error
+	GetByCalendarTokenHashFunc func(ctx context.Context, hash string) (*domain.User, error)
 }
 
 func (m UserRepository) GetByID(ctx context.Context, userID int64) (*domain.User, error) {
@@ -29,6 +32,14 @@ func (m UserRepository) Delete(ctx context.Context, userID int64) error {
 	return m.DeleteFunc(ctx, userID)
 }
 
+func (m UserRepository) SetCalendarTokenHash(ctx context.Context, userID int64, hash string) error {
+	return m.SetCalendarTokenHashFunc(ctx, userID, hash)
+}
+
+func (m UserRepository) GetByCalendarTokenHash(ctx context.Context, hash string) (*domain.User, error) {
+	return m.GetByCalendarTokenHashFunc(ctx, hash)
+}
+
 type UserService struct {
 	GetByIDFunc func(ctx context.Context, userID int64) (*domain.User, error)
 	CreateFunc  func(ctx context.Context, favoriteRegions, wantResidency []domain.RegionID) error
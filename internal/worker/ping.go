@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rabbitmq/amqp091-go"
+
+	"github.com/pumpkinlog/backend/internal/domain"
+	"github.com/pumpkinlog/backend/internal/service"
+)
+
+type pingWorker struct {
+	*consumer
+
+	pingSvc domain.PingService
+}
+
+// NewPingWorker folds device pings into daily presences, one batch at a time.
+func NewPingWorker(logger *slog.Logger, conn *pgxpool.Pool, ch *amqp091.Channel, concurrency int) Worker {
+	presenceSvc := service.NewPresenceService(logger, conn, ch)
+
+	w := &pingWorker{
+		pingSvc: service.NewPingService(logger, conn, ch, presenceSvc),
+	}
+
+	w.consumer = newConsumer(logger, ch, "ping.worker", concurrency, w.handleMessage)
+
+	return w
+}
+
+func (w *pingWorker) Start() error {
+	return w.start()
+}
+
+func (w *pingWorker) Stop() error {
+	return w.stop()
+}
+
+func (w *pingWorker) handleMessage(msg amqp091.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var params service.PingMessage
+	if err := json.Unmarshal(msg.Body, &params); err != nil {
+		return fmt.Errorf("unmarshal message: %w", err)
+	}
+
+	w.logger.Info("folding pings", "userId", params.UserID, "deviceId", params.DeviceID, "start", params.Start, "end", params.End)
+
+	if err := w.pingSvc.Fold(ctx, params.UserID, params.DeviceID, params.Start, params.End); err != nil {
+		return fmt.Errorf("fold pings: %w", err)
+	}
+
+	w.logger.Info("folded pings", "userId", params.UserID, "deviceId", params.DeviceID)
+
+	return nil
+}
@@ -1,7 +1,10 @@
 package worker
 
 import (
+	"fmt"
 	"log/slog"
+	"os"
+	"sync"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rabbitmq/amqp091-go"
@@ -13,3 +16,103 @@ type Worker interface {
 	Start() error
 	Stop() error
 }
+
+// consumer handles the messages of a queue, up to concurrency at once. A message is acked once
+// handled, and requeued when handling fails.
+type consumer struct {
+	logger      *slog.Logger
+	ch          *amqp091.Channel
+	queue       string
+	concurrency int
+	handle      func(msg amqp091.Delivery) error
+
+	msgs    <-chan amqp091.Delivery
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	stopped chan struct{}
+}
+
+func newConsumer(logger *slog.Logger, ch *amqp091.Channel, queue string, concurrency int, handle func(msg amqp091.Delivery) error) *consumer {
+	return &consumer{
+		logger:      logger,
+		ch:          ch,
+		queue:       queue,
+		concurrency: concurrency,
+		handle:      handle,
+
+		sem:     make(chan struct{}, concurrency),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (c *consumer) start() error {
+	if err := c.ch.Qos(c.concurrency, 0, false); err != nil {
+		return fmt.Errorf("set QoS: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("get hostname: %w", err)
+	}
+
+	msgs, err := c.ch.Consume(
+		c.queue,
+		hostname,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("consume queue: %w", err)
+	}
+
+	c.msgs = msgs
+
+	go c.run()
+
+	return nil
+}
+
+func (c *consumer) run() {
+	for msg := range c.msgs {
+		c.sem <- struct{}{}
+		c.wg.Add(1)
+
+		go func(m amqp091.Delivery) {
+			defer c.wg.Done()
+			defer func() { <-c.sem }()
+
+			if err := c.handle(m); err != nil {
+				c.logger.Error("consume error", "queue", c.queue, "error", err)
+				if nackErr := m.Nack(false, true); nackErr != nil {
+					c.logger.Error("failed to nack", "error", nackErr)
+				}
+				return
+			}
+
+			if ackErr := m.Ack(false); ackErr != nil {
+				c.logger.Error("failed to ack", "error", ackErr)
+			}
+		}(msg)
+	}
+
+	c.wg.Wait()
+	close(c.stopped)
+}
+
+func (c *consumer) stop() error {
+
+	if err := c.ch.Cancel("", false); err != nil {
+		c.logger.Warn("cancel consumer failed", "error", err)
+	}
+
+	<-c.stopped
+
+	if err := c.ch.Close(); err != nil {
+		return fmt.Errorf("close channel: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1 @@
+ALTER TABLE regions ADD COLUMN member_region_ids TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+ALTER TABLE rules DROP COLUMN IF EXISTS include_subregions;
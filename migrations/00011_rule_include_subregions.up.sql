@@ -0,0 +1 @@
+ALTER TABLE rules ADD COLUMN include_subregions BOOLEAN NOT NULL DEFAULT FALSE;
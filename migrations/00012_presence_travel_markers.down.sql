@@ -0,0 +1,4 @@
+ALTER TABLE presences
+    DROP COLUMN IF EXISTS arrival,
+    DROP COLUMN IF EXISTS departure,
+    DROP COLUMN IF EXISTS transit;
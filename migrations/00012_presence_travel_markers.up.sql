@@ -0,0 +1,4 @@
+ALTER TABLE presences
+    ADD COLUMN arrival BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN departure BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN transit BOOLEAN NOT NULL DEFAULT FALSE;
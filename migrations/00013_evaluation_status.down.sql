@@ -0,0 +1,4 @@
+ALTER TABLE evaluations
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS unresolved;
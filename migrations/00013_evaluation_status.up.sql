@@ -0,0 +1,4 @@
+ALTER TABLE evaluations
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'evaluated',
+    ADD COLUMN reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN unresolved TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE rules
+    ADD COLUMN rule_references TEXT[] NOT NULL DEFAULT '{}';
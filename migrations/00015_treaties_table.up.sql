@@ -0,0 +1,14 @@
+CREATE TABLE treaties (
+    id VARCHAR(128) PRIMARY KEY,
+    name TEXT NOT NULL,
+    region_a TEXT NOT NULL,
+    region_b TEXT NOT NULL,
+    tests JSONB NOT NULL,
+    sources JSONB NOT NULL DEFAULT '[]',
+    FOREIGN KEY (region_a) REFERENCES regions(id) ON DELETE CASCADE,
+    FOREIGN KEY (region_b) REFERENCES regions(id) ON DELETE CASCADE,
+    CHECK (region_a <> region_b)
+);
+
+CREATE INDEX idx_treaties_region_a ON treaties(region_a);
+CREATE INDEX idx_treaties_region_b ON treaties(region_b);
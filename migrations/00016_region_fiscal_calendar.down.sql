@@ -0,0 +1 @@
+ALTER TABLE regions DROP COLUMN IF EXISTS fiscal_calendar;
@@ -0,0 +1 @@
+ALTER TABLE regions ADD COLUMN fiscal_calendar JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1 @@
+ALTER TABLE regions ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
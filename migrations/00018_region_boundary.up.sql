@@ -0,0 +1 @@
+ALTER TABLE regions ADD COLUMN boundary JSONB NOT NULL DEFAULT '[]';
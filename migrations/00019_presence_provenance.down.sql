@@ -0,0 +1,3 @@
+ALTER TABLE presences
+    DROP COLUMN IF EXISTS provenance,
+    DROP COLUMN IF EXISTS ambiguous;
@@ -0,0 +1,3 @@
+ALTER TABLE presences
+    ADD COLUMN provenance TEXT NOT NULL DEFAULT 'manual',
+    ADD COLUMN ambiguous BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+-- Pings are append-only. Region IDs are not foreign keys, so a ping with an unknown region is
+-- skipped when folded rather than failing its batch.
+CREATE TABLE device_pings (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    device_id INTEGER NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    lat DOUBLE PRECISION,
+    lng DOUBLE PRECISION,
+    region_id TEXT,
+    received_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+    UNIQUE (device_id, recorded_at),
+    CHECK ((lat IS NULL) = (lng IS NULL) AND (lat IS NULL) <> (region_id IS NULL))
+);
@@ -0,0 +1,3 @@
+ALTER TABLE presences
+    DROP COLUMN IF EXISTS evidence,
+    DROP COLUMN IF EXISTS confirmed;
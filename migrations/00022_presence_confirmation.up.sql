@@ -0,0 +1,6 @@
+ALTER TABLE presences
+    ADD COLUMN evidence TEXT,
+    ADD COLUMN confirmed BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Days derived before confirmation existed are left for the user to review
+UPDATE presences SET confirmed = FALSE WHERE provenance <> 'manual';